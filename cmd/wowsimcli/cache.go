@@ -0,0 +1,74 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/resultcache"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "wowsimcli", "results")
+}
+
+// Adds the flags for caching results of seeded sims.
+func (cmd *command) addCacheFlags() {
+	cmd.cacheDir = cmd.flags.String("cachedir", defaultCacheDir(), "directory for caching results of sims with a fixed random seed, or empty to disable")
+	cmd.cacheSize = cmd.flags.Int("cachesize", 1000, "max number of results to keep in the result cache")
+	cmd.noCache = cmd.flags.Bool("nocache", false, "always run the sim instead of using a cached result. The new result is still cached.")
+}
+
+// Returns the cache and key to use for request, or a nil cache if its result
+// shouldn't be cached.
+func (cmd *command) openResultCache(request googleProto.Message) (*resultcache.Cache, string) {
+	if *cmd.cacheDir == "" || !resultcache.Cacheable(request.(interface{ GetSimOptions() *proto.SimOptions })) {
+		return nil, ""
+	}
+
+	version, err := resultcache.ExecutableVersion()
+	if err != nil {
+		log.Printf("result cache disabled, could not determine sim version: %s", err)
+		return nil, ""
+	}
+	cache, err := resultcache.New(*cmd.cacheDir, *cmd.cacheSize, version)
+	if err != nil {
+		log.Printf("result cache disabled: %s", err)
+		return nil, ""
+	}
+	key, err := cache.Key(request)
+	if err != nil {
+		log.Printf("result cache disabled, failed to compute key: %s", err)
+		return nil, ""
+	}
+	return cache, key
+}
+
+// Loads a cached result for the request into result, unless -nocache was given.
+func (cmd *command) loadCachedResult(cache *resultcache.Cache, key string, result googleProto.Message) bool {
+	if cache == nil || *cmd.noCache {
+		return false
+	}
+	if !cache.Get(key, result) {
+		return false
+	}
+	if *cmd.verbose {
+		log.Printf("using cached result %s", key)
+	}
+	return true
+}
+
+func storeCachedResult(cache *resultcache.Cache, key string, result googleProto.Message, errorResult string) {
+	if cache == nil || errorResult != "" {
+		return
+	}
+	if err := cache.Put(key, result); err != nil {
+		log.Printf("failed to cache result: %s", err)
+	}
+}
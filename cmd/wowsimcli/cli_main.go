@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 
@@ -10,53 +13,302 @@ import (
 	"github.com/wowsims/wotlk/sim/core"
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"google.golang.org/protobuf/encoding/protojson"
+	googleProto "google.golang.org/protobuf/proto"
 )
 
 func init() {
 	sim.RegisterAll()
 }
 
+const usage = `Usage: wowsimcli [command] [flags]
+
+Commands:
+  raidsim       Runs a RaidSimRequest (default if no command is given).
+  statweights   Runs a StatWeightsRequest.
+  computestats  Runs a ComputeStatsRequest.
+  gearlist      Lists all items, enchants, gems and encounter presets.
+  sweep         Runs a RaidSimRequest for every combination of a set of
+                parameter values, writing a JSON or CSV table of results.
+  talents       Runs a TalentComparisonRequest, ranking talent strings and
+                glyphs against the player's own.
+
+Use '-' as the input or output file to read from stdin or write to stdout.
+Results of raidsim, statweights and talents requests with a fixed random seed are
+cached on disk, use -nocache to force a fresh sim.
+Run 'wowsimcli [command] -h' for the flags of each command.
+`
+
+type command struct {
+	flags *flag.FlagSet
+
+	infile   *string
+	outfile  *string
+	verbose  *bool
+	format   *string
+	eventlog *string
+
+	cacheDir  *string
+	cacheSize *int
+	noCache   *bool
+}
+
+func newCommand(name string, defaultInput string) *command {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	return &command{
+		flags:   flags,
+		infile:  flags.String("input", defaultInput, "location of input file, or '-' for stdin"),
+		outfile: flags.String("output", "", "location of output file, or '-' for stdout. Defaults to output.json, output.csv or output.txt depending on the format"),
+		verbose: flags.Bool("verbose", false, "stream progress metrics to stderr as JSON lines"),
+		format:  flags.String("format", "json", "output format, either 'json' for the full result or 'summary' for a human-readable table"),
+	}
+}
+
 func main() {
-	infile := flag.String("input", "input.json", "location of input file")
-	outfile := flag.String("output", "output.json", "location of output file")
-	verbose := flag.Bool("verbose", false, "print information during runtime")
+	args := os.Args[1:]
+	name := "raidsim"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		name = args[0]
+		args = args[1:]
+	}
+
+	switch name {
+	case "raidsim":
+		cmd := newCommand(name, "input.json")
+		cmd.eventlog = cmd.flags.String("eventlog", "", "if set, location to write the structured combat log as JSON lines")
+		cmd.addCacheFlags()
+		cmd.flags.Parse(args)
+		runRaidSim(cmd)
+	case "statweights":
+		cmd := newCommand(name, "input.json")
+		cmd.addCacheFlags()
+		cmd.flags.Parse(args)
+		runStatWeights(cmd)
+	case "computestats":
+		cmd := newCommand(name, "input.json")
+		cmd.flags.Parse(args)
+		runComputeStats(cmd)
+	case "gearlist":
+		cmd := newCommand(name, "")
+		cmd.flags.Parse(args)
+		runGearList(cmd)
+	case "sweep":
+		runSweep(args)
+	case "talents":
+		cmd := newCommand(name, "input.json")
+		cmd.addCacheFlags()
+		cmd.flags.Parse(args)
+		runTalentComparison(cmd)
+	case "help":
+		fmt.Fprint(os.Stderr, usage)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runRaidSim(cmd *command) {
+	input := &proto.RaidSimRequest{}
+	cmd.readInput(input)
+	if *cmd.eventlog != "" {
+		if input.SimOptions == nil {
+			input.SimOptions = &proto.SimOptions{}
+		}
+		input.SimOptions.StructuredLog = true
+	}
+
+	cache, cacheKey := cmd.openResultCache(input)
+	finalResult := &proto.RaidSimResult{}
+	if !cmd.loadCachedResult(cache, cacheKey, finalResult) {
+		reporter := make(chan *proto.ProgressMetrics, 10)
+		core.RunRaidSimAsync(input, reporter)
+
+		for v := range reporter {
+			if v.FinalRaidResult != nil {
+				finalResult = v.FinalRaidResult
+				break
+			}
+			cmd.reportProgress(v)
+		}
+		storeCachedResult(cache, cacheKey, finalResult, finalResult.ErrorResult)
+	}
+	if finalResult.ErrorResult != "" {
+		log.Printf("sim failed: %s", finalResult.ErrorResult)
+	}
+
+	if *cmd.eventlog != "" {
+		err := writeEventLog(*cmd.eventlog, finalResult.CombatLog)
+		if err != nil {
+			log.Fatalf("failed to write event log file: %s", err)
+		}
+		if *cmd.verbose {
+			fmt.Fprintf(os.Stderr, "Wrote %d events to `%s` successfully.\n", len(finalResult.CombatLog), *cmd.eventlog)
+		}
+		// Already written separately, no need to duplicate it in the output file.
+		finalResult.CombatLog = nil
+	}
+
+	cmd.writeOutput(finalResult, func(w io.Writer) { writeRaidSimSummary(w, input, finalResult) })
+}
+
+func runStatWeights(cmd *command) {
+	input := &proto.StatWeightsRequest{}
+	cmd.readInput(input)
+
+	cache, cacheKey := cmd.openResultCache(input)
+	finalResult := &proto.StatWeightsResult{}
+	if !cmd.loadCachedResult(cache, cacheKey, finalResult) {
+		reporter := make(chan *proto.ProgressMetrics, 10)
+		core.StatWeightsAsync(input, reporter)
+
+		for v := range reporter {
+			if v.FinalWeightResult != nil {
+				finalResult = v.FinalWeightResult
+				break
+			}
+			cmd.reportProgress(v)
+		}
+		storeCachedResult(cache, cacheKey, finalResult, finalResult.ErrorResult)
+	}
 
-	flag.Parse()
+	cmd.writeOutput(finalResult, func(w io.Writer) { writeStatWeightsSummary(w, input, finalResult) })
+}
+
+func runTalentComparison(cmd *command) {
+	input := &proto.TalentComparisonRequest{}
+	cmd.readInput(input)
+
+	cache, cacheKey := cmd.openResultCache(input)
+	finalResult := &proto.TalentComparisonResult{}
+	if !cmd.loadCachedResult(cache, cacheKey, finalResult) {
+		reporter := make(chan *proto.ProgressMetrics, 10)
+		core.TalentComparisonAsync(input, reporter)
+
+		for v := range reporter {
+			if v.FinalTalentComparisonResult != nil {
+				finalResult = v.FinalTalentComparisonResult
+				break
+			}
+			cmd.reportProgress(v)
+		}
+		storeCachedResult(cache, cacheKey, finalResult, finalResult.ErrorResult)
+	}
+	if finalResult.ErrorResult != "" {
+		log.Printf("talent comparison failed: %s", finalResult.ErrorResult)
+	}
+
+	cmd.writeOutput(finalResult, func(w io.Writer) { writeTalentComparisonSummary(w, finalResult) })
+}
 
-	data, err := os.ReadFile(*infile)
+func runComputeStats(cmd *command) {
+	input := &proto.ComputeStatsRequest{}
+	cmd.readInput(input)
+
+	result := core.ComputeStats(input)
+	cmd.writeOutput(result, func(w io.Writer) { writeComputeStatsSummary(w, input, result) })
+}
+
+func runGearList(cmd *command) {
+	input := &proto.GearListRequest{}
+	if *cmd.infile != "" {
+		cmd.readInput(input)
+	}
+
+	result := core.GetGearList(input)
+	cmd.writeOutput(result, func(w io.Writer) { writeGearListSummary(w, result) })
+}
+
+func (cmd *command) readInput(input googleProto.Message) {
+	var data []byte
+	var err error
+	if *cmd.infile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*cmd.infile)
+	}
 	if err != nil {
 		log.Fatalf("failed to load input json file: %s", err)
 	}
-	input := &proto.RaidSimRequest{}
 	err = protojson.Unmarshal(data, input)
 	if err != nil {
 		log.Fatalf("failed to load input json file: %s", err)
 	}
+}
 
-	reporter := make(chan *proto.ProgressMetrics, 10)
-	core.RunRaidSimAsync(input, reporter)
+func (cmd *command) reportProgress(progress *proto.ProgressMetrics) {
+	if !*cmd.verbose {
+		return
+	}
+	line, err := protojson.Marshal(progress)
+	if err != nil {
+		log.Fatalf("failed to marshal progress: %s", err)
+	}
+	os.Stderr.Write(append(line, '\n'))
+}
 
-	var finalResult *proto.RaidSimResult
-	for v := range reporter {
-		if v.FinalRaidResult != nil {
-			finalResult = v.FinalRaidResult
-			break
-		}
-		if *verbose {
-			fmt.Printf("Sim Progress: %d / %d\n", v.CompletedIterations, v.TotalIterations)
+// Writes the result as protojson, or calls writeSummary if the summary format was requested.
+func (cmd *command) writeOutput(result googleProto.Message, writeSummary func(io.Writer)) {
+	var output []byte
+	switch *cmd.format {
+	case "json":
+		var err error
+		output, err = protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(result)
+		if err != nil {
+			log.Fatalf("failed to marshal final results: %s", err)
 		}
+	case "summary":
+		buf := &bytes.Buffer{}
+		writeSummary(buf)
+		output = buf.Bytes()
+	default:
+		log.Fatalf("unknown format: %s", *cmd.format)
+	}
+
+	outfile := cmd.outputFile()
+	if outfile == "-" {
+		os.Stdout.Write(output)
+		return
 	}
 
-	output, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(finalResult)
+	err := os.WriteFile(outfile, output, 0666)
 	if err != nil {
-		log.Fatalf("faield to marshal final results: %s", err)
+		log.Fatalf("failed to write output file: %s", err)
 	}
+	if *cmd.verbose {
+		fmt.Fprintf(os.Stderr, "Wrote output file: `%s` successfully.\n", outfile)
+	}
+}
+
+// Returns the output file, which defaults to one named after the output format.
+func (cmd *command) outputFile() string {
+	if *cmd.outfile != "" {
+		return *cmd.outfile
+	}
+	switch *cmd.format {
+	case "csv":
+		return "output.csv"
+	case "summary":
+		return "output.txt"
+	default:
+		return "output.json"
+	}
+}
 
-	err = os.WriteFile(*outfile, output, 0666)
+// Writes each event as a single line of JSON.
+func writeEventLog(filename string, events []*proto.CombatLogEvent) error {
+	file, err := os.Create(filename)
 	if err != nil {
-		log.Fatalf("failed to write output file:: %s", err)
+		return err
 	}
-	if *verbose {
-		fmt.Printf("Wrote output file: `%s` successfully.\n", *outfile)
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, event := range events {
+		line, err := protojson.Marshal(event)
+		if err != nil {
+			return err
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
 	}
+	return writer.Flush()
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// Human-readable summaries for the --format=summary option.
+
+func writeRaidSimSummary(w io.Writer, request *proto.RaidSimRequest, result *proto.RaidSimResult) {
+	if result.ErrorResult != "" {
+		fmt.Fprintf(w, "Error: %s\n", result.ErrorResult)
+		return
+	}
+
+	fmt.Fprintf(w, "Iterations: %d, Avg Duration: %0.1fs\n", request.GetSimOptions().GetIterations(), result.AvgIterationDuration)
+	fmt.Fprintf(w, "Raid DPS: %0.2f (stdev %0.2f)\n\n", result.RaidMetrics.Dps.Avg, result.RaidMetrics.Dps.Stdev)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Player\tDPS\tStdev\tHPS\tStdev\tTPS\tStdev\tDTPS\tStdev\t")
+	for _, party := range result.RaidMetrics.Parties {
+		for _, player := range party.Players {
+			if player.Dps == nil {
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%0.2f\t%0.2f\t%0.2f\t%0.2f\t%0.2f\t%0.2f\t%0.2f\t%0.2f\t\n",
+				player.Name,
+				player.Dps.Avg, player.Dps.Stdev,
+				player.Hps.Avg, player.Hps.Stdev,
+				player.Threat.Avg, player.Threat.Stdev,
+				player.Dtps.Avg, player.Dtps.Stdev)
+		}
+	}
+	tw.Flush()
+}
+
+func writeStatWeightsSummary(w io.Writer, request *proto.StatWeightsRequest, result *proto.StatWeightsResult) {
+	if result.Dps == nil || len(result.Dps.Weights) == 0 {
+		fmt.Fprintln(w, "Error: stat weights sim failed")
+		return
+	}
+
+	fmt.Fprintf(w, "EP Reference Stat: %s\n\n", stats.Stat(request.EpReferenceStat).StatName())
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Stat\tDPS Weight\tStdev\tDPS EP\tHPS Weight\tTPS Weight\tDTPS Weight\t")
+	for _, stat := range request.StatsToWeigh {
+		fmt.Fprintf(tw, "%s\t%0.3f\t%0.3f\t%0.3f\t%0.3f\t%0.3f\t%0.3f\t\n",
+			stats.Stat(stat).StatName(),
+			result.Dps.Weights[stat], result.Dps.WeightsStdev[stat], result.Dps.EpValues[stat],
+			result.Hps.Weights[stat], result.Tps.Weights[stat], result.Dtps.Weights[stat])
+	}
+	tw.Flush()
+}
+
+func writeTalentComparisonSummary(w io.Writer, result *proto.TalentComparisonResult) {
+	if result.ErrorResult != "" {
+		fmt.Fprintf(w, "Error: %s\n", result.ErrorResult)
+		return
+	}
+
+	fmt.Fprintf(w, "Baseline DPS: %0.2f (stdev %0.2f)\n\n", result.Baseline.Dps, result.Baseline.DpsStdev)
+
+	writeVariant := func(tw *tabwriter.Writer, name string, variant *proto.TalentComparisonVariant) {
+		significant := ""
+		if variant.Significant {
+			significant = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%0.2f\t%+0.2f\t%0.2f\t%s\t\n", name, variant.Dps, variant.DpsDelta, variant.DpsDeltaError, significant)
+	}
+
+	if len(result.Variants) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Talents\tGlyphs\tDPS\tDelta\tError\tSignificant\t")
+		for _, variant := range result.Variants {
+			name := variant.TalentsString
+			if name == "" {
+				name = "(baseline talents)"
+			}
+			glyphs := variant.Glyphs
+			if glyphs == nil {
+				glyphs = &proto.Glyphs{}
+			}
+			writeVariant(tw, fmt.Sprintf("%s\t%d/%d/%d %d/%d/%d", name,
+				glyphs.Major1, glyphs.Major2, glyphs.Major3, glyphs.Minor1, glyphs.Minor2, glyphs.Minor3), variant)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	if len(result.TalentPoints) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Removed Point\tDPS\tDelta\tError\tSignificant\t")
+		for _, variant := range result.TalentPoints {
+			writeVariant(tw, variant.RemovedTalent, variant)
+		}
+		tw.Flush()
+	}
+}
+
+func writeComputeStatsSummary(w io.Writer, request *proto.ComputeStatsRequest, result *proto.ComputeStatsResult) {
+	if result.ErrorResult != "" {
+		fmt.Fprintf(w, "Error: %s\n", result.ErrorResult)
+		return
+	}
+
+	for i, party := range result.RaidStats.Parties {
+		for j, player := range party.Players {
+			if len(player.GetFinalStats()) == 0 {
+				continue
+			}
+			name := fmt.Sprintf("Party %d Player %d", i+1, j+1)
+			if i < len(request.Raid.Parties) && j < len(request.Raid.Parties[i].Players) && request.Raid.Parties[i].Players[j].Name != "" {
+				name = request.Raid.Parties[i].Players[j].Name
+			}
+			fmt.Fprintf(w, "%s\n", name)
+
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "  Stat\tGear\tFinal\t")
+			for stat, value := range player.FinalStats {
+				if value == 0 {
+					continue
+				}
+				fmt.Fprintf(tw, "  %s\t%0.2f\t%0.2f\t\n", stats.Stat(stat).StatName(), player.GearStats[stat], value)
+			}
+			tw.Flush()
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func writeGearListSummary(w io.Writer, result *proto.GearListResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Items\t%d\t\n", len(result.Items))
+	fmt.Fprintf(tw, "Enchants\t%d\t\n", len(result.Enchants))
+	fmt.Fprintf(tw, "Gems\t%d\t\n", len(result.Gems))
+	fmt.Fprintf(tw, "Encounters\t%d\t\n", len(result.Encounters))
+	tw.Flush()
+}
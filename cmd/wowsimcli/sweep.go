@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// A single knob to vary, e.g. {"path": "encounter.duration", "values": [120, 180, 300]}.
+//
+// Paths use protojson field names (either lowerCamelCase or snake_case) separated
+// by '.', with [i] to index into repeated fields. Values are the JSON values to
+// set at that path.
+type sweepParameter struct {
+	Path   string            `json:"path"`
+	Values []json.RawMessage `json:"values"`
+}
+
+type sweepSpec struct {
+	Parameters []sweepParameter `json:"parameters"`
+}
+
+// One row of sweep output, for either the whole raid or a single player.
+//
+// The raid's TPS and DTPS are the sums over its players. The sim only tracks
+// their distributions per player, so the raid row has no stdev for them.
+type sweepRow struct {
+	Combination int               `json:"combination"`
+	Values      []json.RawMessage `json:"values"`
+	Unit        string            `json:"unit"`
+
+	Dps       float64 `json:"dps"`
+	DpsStdev  float64 `json:"dpsStdev"`
+	Hps       float64 `json:"hps"`
+	HpsStdev  float64 `json:"hpsStdev"`
+	Tps       float64 `json:"tps"`
+	TpsStdev  float64 `json:"tpsStdev"`
+	Dtps      float64 `json:"dtps"`
+	DtpsStdev float64 `json:"dtpsStdev"`
+
+	Error string `json:"error,omitempty"`
+}
+
+type paramFlags []string
+
+func (pf *paramFlags) String() string {
+	return strings.Join(*pf, " ")
+}
+
+func (pf *paramFlags) Set(value string) error {
+	*pf = append(*pf, value)
+	return nil
+}
+
+func runSweep(args []string) {
+	cmd := newCommand("sweep", "input.json")
+	specfile := cmd.flags.String("spec", "", "location of a sweep spec json file, like {\"parameters\": [{\"path\": \"encounter.duration\", \"values\": [120, 300]}]}")
+	concurrency := cmd.flags.Int("concurrency", runtime.NumCPU(), "number of sims to run at once")
+	var params paramFlags
+	cmd.flags.Var(&params, "param", "a sweep parameter as path=[json values], e.g. 'encounter.duration=[120,300]'. May be repeated.")
+	cmd.flags.Parse(args)
+
+	spec := &sweepSpec{}
+	if *specfile != "" {
+		data, err := os.ReadFile(*specfile)
+		if err != nil {
+			log.Fatalf("failed to load sweep spec file: %s", err)
+		}
+		if err := json.Unmarshal(data, spec); err != nil {
+			log.Fatalf("failed to parse sweep spec file: %s", err)
+		}
+	}
+	for _, param := range params {
+		eq := strings.Index(param, "=")
+		if eq == -1 {
+			log.Fatalf("invalid sweep parameter, expected path=[values]: %s", param)
+		}
+		parameter := sweepParameter{Path: param[:eq]}
+		if err := json.Unmarshal([]byte(param[eq+1:]), &parameter.Values); err != nil {
+			log.Fatalf("invalid values for sweep parameter %s: %s", parameter.Path, err)
+		}
+		spec.Parameters = append(spec.Parameters, parameter)
+	}
+	if len(spec.Parameters) == 0 {
+		log.Fatalf("no sweep parameters given, use -spec or -param")
+	}
+
+	baseRequest := &proto.RaidSimRequest{}
+	cmd.readInput(baseRequest)
+	baseData, err := protojson.Marshal(baseRequest)
+	if err != nil {
+		log.Fatalf("failed to marshal base request: %s", err)
+	}
+
+	combinations := expandSweep(spec.Parameters)
+	requests := make([]*proto.RaidSimRequest, len(combinations))
+	for i, combination := range combinations {
+		var root interface{}
+		if err := json.Unmarshal(baseData, &root); err != nil {
+			log.Fatalf("failed to decode base request: %s", err)
+		}
+		for j, parameter := range spec.Parameters {
+			var value interface{}
+			if err := json.Unmarshal(combination[j], &value); err != nil {
+				log.Fatalf("invalid value for %s: %s", parameter.Path, err)
+			}
+			root, err = setJSONPath(root, parameter.Path, value)
+			if err != nil {
+				log.Fatalf("failed to set %s: %s", parameter.Path, err)
+			}
+		}
+		data, err := json.Marshal(root)
+		if err != nil {
+			log.Fatalf("failed to encode request: %s", err)
+		}
+		requests[i] = &proto.RaidSimRequest{}
+		if err := protojson.Unmarshal(data, requests[i]); err != nil {
+			log.Fatalf("invalid request for combination %s: %s", combinationString(combination), err)
+		}
+	}
+
+	results := make([]*proto.RaidSimResult, len(requests))
+	var waitGroup sync.WaitGroup
+	var mutex sync.Mutex
+	completed := 0
+	sem := make(chan struct{}, core.MaxInt(1, *concurrency))
+	for i := range requests {
+		waitGroup.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer waitGroup.Done()
+			defer func() { <-sem }()
+			results[i] = core.RunSim(*requests[i], nil)
+
+			mutex.Lock()
+			completed++
+			cmd.reportProgress(&proto.ProgressMetrics{CompletedSims: int32(completed), TotalSims: int32(len(requests))})
+			mutex.Unlock()
+		}(i)
+	}
+	waitGroup.Wait()
+
+	var rows []sweepRow
+	for i, result := range results {
+		rows = append(rows, sweepRows(i, combinations[i], result)...)
+	}
+
+	out := io.Writer(os.Stdout)
+	if outfile := cmd.outputFile(); outfile != "-" {
+		file, err := os.Create(outfile)
+		if err != nil {
+			log.Fatalf("failed to create output file: %s", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch *cmd.format {
+	case "csv":
+		err = writeSweepCSV(out, spec.Parameters, rows)
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(struct {
+			Parameters []sweepParameter `json:"parameters"`
+			Rows       []sweepRow       `json:"rows"`
+		}{spec.Parameters, rows})
+	default:
+		log.Fatalf("unknown format for sweep: %s, expected 'json' or 'csv'", *cmd.format)
+	}
+	if err != nil {
+		log.Fatalf("failed to write output: %s", err)
+	}
+}
+
+// Returns every combination of parameter values, varying the last parameter fastest.
+func expandSweep(parameters []sweepParameter) [][]json.RawMessage {
+	combinations := [][]json.RawMessage{{}}
+	for _, parameter := range parameters {
+		var expanded [][]json.RawMessage
+		for _, combination := range combinations {
+			for _, value := range parameter.Values {
+				newCombination := append(append([]json.RawMessage{}, combination...), value)
+				expanded = append(expanded, newCombination)
+			}
+		}
+		combinations = expanded
+	}
+	return combinations
+}
+
+func combinationString(combination []json.RawMessage) string {
+	values := make([]string, len(combination))
+	for i, value := range combination {
+		values[i] = string(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+func sweepRows(combination int, values []json.RawMessage, result *proto.RaidSimResult) []sweepRow {
+	if result.ErrorResult != "" {
+		return []sweepRow{{Combination: combination, Values: values, Unit: "Raid", Error: result.ErrorResult}}
+	}
+
+	rows := []sweepRow{{
+		Combination: combination,
+		Values:      values,
+		Unit:        "Raid",
+		Dps:         result.RaidMetrics.Dps.Avg,
+		DpsStdev:    result.RaidMetrics.Dps.Stdev,
+		Hps:         result.RaidMetrics.Hps.Avg,
+		HpsStdev:    result.RaidMetrics.Hps.Stdev,
+	}}
+	raidRow := &rows[0]
+	for _, party := range result.RaidMetrics.Parties {
+		for _, player := range party.Players {
+			if player.Dps == nil {
+				continue
+			}
+			raidRow.Tps += player.Threat.Avg
+			raidRow.Dtps += player.Dtps.Avg
+			rows = append(rows, sweepRow{
+				Combination: combination,
+				Values:      values,
+				Unit:        player.Name,
+				Dps:         player.Dps.Avg,
+				DpsStdev:    player.Dps.Stdev,
+				Hps:         player.Hps.Avg,
+				HpsStdev:    player.Hps.Stdev,
+				Tps:         player.Threat.Avg,
+				TpsStdev:    player.Threat.Stdev,
+				Dtps:        player.Dtps.Avg,
+				DtpsStdev:   player.Dtps.Stdev,
+			})
+		}
+	}
+	return rows
+}
+
+func writeSweepCSV(w io.Writer, parameters []sweepParameter, rows []sweepRow) error {
+	writer := csv.NewWriter(w)
+	header := []string{"combination"}
+	for _, parameter := range parameters {
+		header = append(header, parameter.Path)
+	}
+	header = append(header, "unit", "dps", "dps_stdev", "hps", "hps_stdev", "tps", "tps_stdev", "dtps", "dtps_stdev", "error")
+	writer.Write(header)
+
+	formatFloat := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	}
+	for _, row := range rows {
+		record := []string{strconv.Itoa(row.Combination)}
+		for _, value := range row.Values {
+			// Write plain strings without their JSON quotes.
+			var str string
+			if json.Unmarshal(value, &str) != nil {
+				str = string(value)
+			}
+			record = append(record, str)
+		}
+		record = append(record, row.Unit,
+			formatFloat(row.Dps), formatFloat(row.DpsStdev),
+			formatFloat(row.Hps), formatFloat(row.HpsStdev),
+			formatFloat(row.Tps), formatFloat(row.TpsStdev),
+			formatFloat(row.Dtps), formatFloat(row.DtpsStdev),
+			row.Error)
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Sets the value at path within a decoded JSON document, creating intermediate
+// objects and extending arrays as needed. Returns the (possibly new) root.
+func setJSONPath(root interface{}, path string, value interface{}) (interface{}, error) {
+	if path == "" {
+		return value, nil
+	}
+
+	segment, rest := path, ""
+	if dot := strings.Index(path, "."); dot != -1 {
+		segment, rest = path[:dot], path[dot+1:]
+	}
+
+	// Split 'items[3][1]' into the key and its indices.
+	key := segment
+	var indices []int
+	if bracket := strings.Index(segment, "["); bracket != -1 {
+		key = segment[:bracket]
+		for _, part := range strings.Split(segment[bracket+1:], "[") {
+			index, err := strconv.Atoi(strings.TrimSuffix(part, "]"))
+			if err != nil || !strings.HasSuffix(part, "]") || index < 0 {
+				return nil, fmt.Errorf("invalid index in path segment %s", segment)
+			}
+			indices = append(indices, index)
+		}
+	}
+
+	obj, ok := root.(map[string]interface{})
+	if root == nil {
+		obj = make(map[string]interface{})
+	} else if !ok {
+		return nil, fmt.Errorf("%s is not an object", key)
+	}
+	jsonKey := key
+	if _, present := obj[jsonKey]; !present {
+		jsonKey = lowerCamelCase(key)
+	}
+
+	child, err := setIndexedPath(obj[jsonKey], indices, rest, value)
+	if err != nil {
+		return nil, err
+	}
+	obj[jsonKey] = child
+	return obj, nil
+}
+
+func setIndexedPath(node interface{}, indices []int, rest string, value interface{}) (interface{}, error) {
+	if len(indices) == 0 {
+		return setJSONPath(node, rest, value)
+	}
+
+	arr, ok := node.([]interface{})
+	if node != nil && !ok {
+		return nil, fmt.Errorf("cannot index into a non-list field")
+	}
+	for len(arr) <= indices[0] {
+		arr = append(arr, nil)
+	}
+	child, err := setIndexedPath(arr[indices[0]], indices[1:], rest, value)
+	if err != nil {
+		return nil, err
+	}
+	arr[indices[0]] = child
+	return arr, nil
+}
+
+// Converts a proto field name like 'sim_options' to its JSON name 'simOptions'.
+func lowerCamelCase(name string) string {
+	var sb strings.Builder
+	upperNext := false
+	for _, c := range name {
+		if c == '_' {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			sb.WriteString(strings.ToUpper(string(c)))
+			upperNext = false
+		} else {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
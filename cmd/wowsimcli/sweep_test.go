@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExpandSweep(t *testing.T) {
+	testCases := []struct {
+		name       string
+		parameters string
+		expected   []string
+	}{
+		{
+			name:       "NoParameters",
+			parameters: `[]`,
+			expected:   []string{""},
+		},
+		{
+			name:       "SingleParameter",
+			parameters: `[{"path": "encounter.duration", "values": [120, 180]}]`,
+			expected:   []string{"120", "180"},
+		},
+		{
+			name: "CartesianProduct",
+			parameters: `[
+				{"path": "encounter.duration", "values": [120, 180]},
+				{"path": "sim_options.iterations", "values": [1, 2, 3]}]`,
+			expected: []string{"120, 1", "120, 2", "120, 3", "180, 1", "180, 2", "180, 3"},
+		},
+		{
+			name: "EmptyValues",
+			parameters: `[
+				{"path": "encounter.duration", "values": [120, 180]},
+				{"path": "sim_options.iterations", "values": []}]`,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var parameters []sweepParameter
+			if err := json.Unmarshal([]byte(tc.parameters), &parameters); err != nil {
+				t.Fatalf("Invalid parameters: %s", err)
+			}
+
+			combinations := expandSweep(parameters)
+			if len(combinations) != len(tc.expected) {
+				t.Fatalf("Expected %d combinations, got %d", len(tc.expected), len(combinations))
+			}
+			for i, combination := range combinations {
+				if combinationString(combination) != tc.expected[i] {
+					t.Fatalf("Expected combination %d to be %q, got %q", i, tc.expected[i], combinationString(combination))
+				}
+			}
+		})
+	}
+}
+
+func TestSetJSONPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		root     string
+		path     string
+		value    interface{}
+		expected string
+		err      string
+	}{
+		{
+			name:     "NewField",
+			root:     `{}`,
+			path:     "encounter.duration",
+			value:    120.0,
+			expected: `{"encounter":{"duration":120}}`,
+		},
+		{
+			name:     "SnakeCaseField",
+			root:     `{"simOptions":{"iterations":1000}}`,
+			path:     "sim_options.iterations",
+			value:    10.0,
+			expected: `{"simOptions":{"iterations":10}}`,
+		},
+		{
+			name:     "ExistingSnakeCaseField",
+			root:     `{"sim_options":{"iterations":1000}}`,
+			path:     "sim_options.iterations",
+			value:    10.0,
+			expected: `{"sim_options":{"iterations":10}}`,
+		},
+		{
+			name:     "ListIndex",
+			root:     `{"targets":[{"level":83},{"level":83}]}`,
+			path:     "targets[1].level",
+			value:    80.0,
+			expected: `{"targets":[{"level":83},{"level":80}]}`,
+		},
+		{
+			name:     "NestedListIndices",
+			root:     `{}`,
+			path:     "parties[0].players[1].name",
+			value:    "Tester",
+			expected: `{"parties":[{"players":[null,{"name":"Tester"}]}]}`,
+		},
+		{
+			name:  "NonNumericIndex",
+			root:  `{}`,
+			path:  "targets[x].level",
+			value: 80.0,
+			err:   "invalid index in path segment targets[x]",
+		},
+		{
+			name:  "NegativeIndex",
+			root:  `{}`,
+			path:  "targets[-1].level",
+			value: 80.0,
+			err:   "invalid index in path segment targets[-1]",
+		},
+		{
+			name:  "UnclosedIndex",
+			root:  `{}`,
+			path:  "targets[1.level",
+			value: 80.0,
+			err:   "invalid index in path segment targets[1",
+		},
+		{
+			name:  "IndexIntoObject",
+			root:  `{"encounter":{"duration":180}}`,
+			path:  "encounter[0].duration",
+			value: 120.0,
+			err:   "cannot index into a non-list field",
+		},
+		{
+			name:  "FieldOfScalar",
+			root:  `{"encounter":{"duration":180}}`,
+			path:  "encounter.duration.seconds",
+			value: 120.0,
+			err:   "seconds is not an object",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var root interface{}
+			if err := json.Unmarshal([]byte(tc.root), &root); err != nil {
+				t.Fatalf("Invalid root: %s", err)
+			}
+
+			result, err := setJSONPath(root, tc.path, tc.value)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("Expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			data, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("Failed to encode result: %s", err)
+			}
+			if string(data) != tc.expected {
+				t.Fatalf("Expected %s, got %s", tc.expected, string(data))
+			}
+		})
+	}
+}
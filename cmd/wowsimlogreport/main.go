@@ -0,0 +1,94 @@
+// Compares a WoWCombatLog.txt from a real fight against a sim of the same
+// setup, reporting per-ability and per-aura deltas for one player.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/wowsims/wotlk/sim"
+	"github.com/wowsims/wotlk/sim/combatlog"
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func init() {
+	sim.RegisterAll()
+}
+
+func main() {
+	logfile := flag.String("log", "WoWCombatLog.txt", "location of the combat log file")
+	infile := flag.String("input", "input.json", "location of the RaidSimRequest json for the same gear and encounter")
+	player := flag.String("player", "", "name of the player to compare, as it appears in both the log and the sim request")
+	target := flag.String("target", "", "if set, only use the log events between the first event involving this unit and its death")
+	format := flag.String("format", "text", "output format, either 'text' or 'json'")
+	outfile := flag.String("output", "", "location of output file, defaults to stdout")
+
+	flag.Parse()
+
+	if *player == "" {
+		log.Fatalf("-player is required")
+	}
+
+	logReader, err := os.Open(*logfile)
+	if err != nil {
+		log.Fatalf("failed to open combat log: %s", err)
+	}
+	playerLog, err := combatlog.Parse(logReader, combatlog.ParseOptions{
+		Player: *player,
+		Target: *target,
+	})
+	logReader.Close()
+	if err != nil {
+		log.Fatalf("failed to parse combat log: %s", err)
+	}
+
+	data, err := os.ReadFile(*infile)
+	if err != nil {
+		log.Fatalf("failed to load input json file: %s", err)
+	}
+	input := &proto.RaidSimRequest{}
+	err = protojson.Unmarshal(data, input)
+	if err != nil {
+		log.Fatalf("failed to load input json file: %s", err)
+	}
+
+	result := core.RunRaidSim(input)
+	if result.ErrorResult != "" {
+		log.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+	unitMetrics := combatlog.FindPlayerMetrics(result, *player)
+	if unitMetrics == nil {
+		log.Fatalf("no player named %s in the sim request", *player)
+	}
+
+	report := combatlog.Compare(playerLog, result, unitMetrics, input.GetSimOptions().GetIterations())
+
+	var out io.Writer = os.Stdout
+	if *outfile != "" {
+		file, err := os.Create(*outfile)
+		if err != nil {
+			log.Fatalf("failed to create output file: %s", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch *format {
+	case "text":
+		err = report.WriteText(out)
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+	if err != nil {
+		log.Fatalf("failed to write report: %s", err)
+	}
+}
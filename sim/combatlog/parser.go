@@ -0,0 +1,383 @@
+// Package combatlog reads WoWCombatLog.txt files from real raids, so they can be
+// compared against sim results for the same setup.
+package combatlog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Spell ID used by the game for hunter Auto Shot, which the sim models as the
+// Shoot action.
+const autoShotSpellID = 75
+
+type ParseOptions struct {
+	// Name of the player whose events should be collected.
+	Player string
+
+	// If set, only events between the first event involving this unit and its
+	// death are used. Otherwise the whole log is used.
+	Target string
+}
+
+// Everything done by a single ability over the course of a log.
+type ActionLog struct {
+	ActionID core.ActionID
+	Name     string
+
+	// Like the sim metrics, hits don't include crits and misses include all
+	// avoided attacks.
+	Casts   int32
+	Hits    int32
+	Crits   int32
+	Misses  int32
+	Damage  float64
+	Healing float64
+}
+
+// Uptime and procs of a single aura on the player.
+type AuraLog struct {
+	ActionID core.ActionID
+	Name     string
+
+	Procs  int32
+	Uptime time.Duration
+
+	activeSince time.Duration
+	active      bool
+}
+
+// A single cast made by the player, in order.
+type CastLog struct {
+	Timestamp time.Duration
+	ActionID  core.ActionID
+	Target    string
+}
+
+// Everything collected for one player from a combat log.
+type PlayerLog struct {
+	Player   string
+	Duration time.Duration
+
+	Casts   []CastLog
+	Actions map[core.ActionID]*ActionLog
+	Auras   map[core.ActionID]*AuraLog
+}
+
+func (playerLog *PlayerLog) getAction(actionID core.ActionID, name string) *ActionLog {
+	action, ok := playerLog.Actions[actionID]
+	if !ok {
+		action = &ActionLog{ActionID: actionID, Name: name}
+		playerLog.Actions[actionID] = action
+	}
+	return action
+}
+
+func (playerLog *PlayerLog) getAura(actionID core.ActionID, name string) *AuraLog {
+	aura, ok := playerLog.Auras[actionID]
+	if !ok {
+		aura = &AuraLog{ActionID: actionID, Name: name}
+		playerLog.Auras[actionID] = aura
+	}
+	return aura
+}
+
+// A parsed line of the combat log.
+type event struct {
+	timestamp  time.Duration
+	eventType  string
+	sourceName string
+	destName   string
+	fields     []string
+}
+
+// Parses a WoWCombatLog.txt file, collecting casts, damage, healing and aura
+// uptimes for a single player.
+func Parse(r io.Reader, options ParseOptions) (*PlayerLog, error) {
+	if options.Player == "" {
+		return nil, fmt.Errorf("player name is required")
+	}
+
+	var events []event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var yearOffset, lastTimestamp time.Duration
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		ev, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+
+		// Logs don't include the year, so detect it changing by time going backwards.
+		ev.timestamp += yearOffset
+		if ev.timestamp < lastTimestamp-time.Hour*12 {
+			yearOffset += timestampYear
+			ev.timestamp += timestampYear
+		}
+		lastTimestamp = ev.timestamp
+		events = append(events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	start, end, err := fightWindow(events, options.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	playerLog := &PlayerLog{
+		Player:   options.Player,
+		Duration: end - start,
+		Actions:  make(map[core.ActionID]*ActionLog),
+		Auras:    make(map[core.ActionID]*AuraLog),
+	}
+	for _, ev := range events {
+		if ev.timestamp < start || ev.timestamp > end {
+			continue
+		}
+		ev.timestamp -= start
+		if err := playerLog.addEvent(ev); err != nil {
+			return nil, err
+		}
+	}
+
+	// Close out any auras still active at the end of the fight.
+	for _, aura := range playerLog.Auras {
+		if aura.active {
+			aura.Uptime += playerLog.Duration - aura.activeSince
+			aura.active = false
+		}
+	}
+
+	return playerLog, nil
+}
+
+// Returns the start and end timestamps of the events to use.
+func fightWindow(events []event, target string) (time.Duration, time.Duration, error) {
+	if len(events) == 0 {
+		return 0, 0, fmt.Errorf("combat log is empty")
+	}
+	if target == "" {
+		return events[0].timestamp, events[len(events)-1].timestamp, nil
+	}
+
+	found := false
+	var start, end time.Duration
+	for _, ev := range events {
+		if ev.sourceName != target && ev.destName != target {
+			continue
+		}
+		if !found {
+			found = true
+			start = ev.timestamp
+		}
+		end = ev.timestamp
+		if ev.eventType == "UNIT_DIED" && ev.destName == target {
+			break
+		}
+	}
+	if !found {
+		return 0, 0, fmt.Errorf("no events found for target %s", target)
+	}
+	return start, end, nil
+}
+
+func (playerLog *PlayerLog) addEvent(ev event) error {
+	fromPlayer := ev.sourceName == playerLog.Player
+	toPlayer := ev.destName == playerLog.Player
+	if !fromPlayer && !toPlayer {
+		return nil
+	}
+
+	switch ev.eventType {
+	case "SWING_DAMAGE":
+		if !fromPlayer {
+			return nil
+		}
+		action := playerLog.getAction(core.ActionID{OtherID: proto.OtherAction_OtherActionAttack}, "Melee")
+		action.Casts++
+		return action.addDamage(ev.fields, 7)
+	case "SWING_MISSED":
+		if !fromPlayer {
+			return nil
+		}
+		action := playerLog.getAction(core.ActionID{OtherID: proto.OtherAction_OtherActionAttack}, "Melee")
+		action.Casts++
+		action.Misses++
+		return nil
+	}
+
+	if len(ev.fields) < 10 {
+		return nil
+	}
+	spellID, err := strconv.Atoi(ev.fields[7])
+	if err != nil {
+		return fmt.Errorf("invalid spell ID %s for %s", ev.fields[7], ev.eventType)
+	}
+	actionID := core.ActionID{SpellID: int32(spellID)}
+	if spellID == autoShotSpellID {
+		actionID = core.ActionID{OtherID: proto.OtherAction_OtherActionShoot}
+	}
+	spellName := unquote(ev.fields[8])
+
+	switch ev.eventType {
+	case "SPELL_CAST_SUCCESS":
+		if fromPlayer {
+			playerLog.getAction(actionID, spellName).Casts++
+			playerLog.Casts = append(playerLog.Casts, CastLog{
+				Timestamp: ev.timestamp,
+				ActionID:  actionID,
+				Target:    ev.destName,
+			})
+		}
+	case "SPELL_DAMAGE", "SPELL_PERIODIC_DAMAGE", "RANGE_DAMAGE", "DAMAGE_SHIELD":
+		if fromPlayer {
+			return playerLog.getAction(actionID, spellName).addDamage(ev.fields, 10)
+		}
+	case "SPELL_MISSED", "SPELL_PERIODIC_MISSED", "RANGE_MISSED":
+		if fromPlayer {
+			playerLog.getAction(actionID, spellName).Misses++
+		}
+	case "SPELL_HEAL", "SPELL_PERIODIC_HEAL":
+		if fromPlayer {
+			return playerLog.getAction(actionID, spellName).addHealing(ev.fields, 10)
+		}
+	case "SPELL_AURA_APPLIED", "SPELL_AURA_REFRESH":
+		if toPlayer {
+			aura := playerLog.getAura(actionID, spellName)
+			aura.Procs++
+			if !aura.active {
+				aura.active = true
+				aura.activeSince = ev.timestamp
+			}
+		}
+	case "SPELL_AURA_REMOVED":
+		if toPlayer {
+			aura := playerLog.getAura(actionID, spellName)
+			if aura.active {
+				aura.Uptime += ev.timestamp - aura.activeSince
+				aura.active = false
+			} else if aura.Procs == 0 {
+				// Aura was already active when the fight started.
+				aura.Uptime += ev.timestamp
+			}
+		}
+	}
+	return nil
+}
+
+// Damage suffix fields: amount, overkill, school, resisted, blocked, absorbed, critical, glancing, crushing.
+func (action *ActionLog) addDamage(fields []string, offset int) error {
+	if len(fields) < offset+7 {
+		return fmt.Errorf("damage event is missing fields")
+	}
+	amount, err := strconv.ParseFloat(fields[offset], 64)
+	if err != nil {
+		return fmt.Errorf("invalid damage amount %s", fields[offset])
+	}
+	action.Damage += amount
+	if fields[offset+6] == "1" {
+		action.Crits++
+	} else {
+		action.Hits++
+	}
+	return nil
+}
+
+// Heal suffix fields: amount, overhealing, absorbed, critical.
+func (action *ActionLog) addHealing(fields []string, offset int) error {
+	if len(fields) < offset+4 {
+		return fmt.Errorf("heal event is missing fields")
+	}
+	amount, err := strconv.ParseFloat(fields[offset], 64)
+	if err != nil {
+		return fmt.Errorf("invalid heal amount %s", fields[offset])
+	}
+	overhealing, _ := strconv.ParseFloat(fields[offset+1], 64)
+	action.Healing += amount - overhealing
+	if fields[offset+3] == "1" {
+		action.Crits++
+	} else {
+		action.Hits++
+	}
+	return nil
+}
+
+// Parses a line like:
+//
+//	10/24 21:15:33.123  SPELL_DAMAGE,0x0E00000000123456,"Player",0x514,0xF130003E9C000001,"Patchwerk",0xa48,47809,"Shadow Bolt",0x20,12345,...
+func parseLine(line string) (event, error) {
+	sep := strings.Index(line, "  ")
+	if sep == -1 {
+		return event{}, fmt.Errorf("missing timestamp separator")
+	}
+
+	timestamp, err := parseTimestamp(line[:sep])
+	if err != nil {
+		return event{}, err
+	}
+
+	fields := splitFields(strings.TrimSpace(line[sep:]))
+	if len(fields) < 7 {
+		return event{}, fmt.Errorf("expected at least 7 fields, got %d", len(fields))
+	}
+
+	return event{
+		timestamp:  timestamp,
+		eventType:  fields[0],
+		sourceName: unquote(fields[2]),
+		destName:   unquote(fields[5]),
+		fields:     fields,
+	}, nil
+}
+
+// Length of the year timestamps are parsed in. Logs have no year, so this is
+// always year 0, which is a leap year.
+var timestampYear = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC).Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC))
+
+// Parses a 'M/D HH:MM:SS.mmm' timestamp into an offset from the start of the year.
+func parseTimestamp(str string) (time.Duration, error) {
+	t, err := time.Parse("1/2 15:04:05.000", str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %s", str)
+	}
+	return t.Sub(time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)), nil
+}
+
+// Splits on commas, except for those inside quoted names.
+func splitFields(str string) []string {
+	var fields []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ',':
+			if !inQuotes {
+				fields = append(fields, str[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, str[start:])
+}
+
+func unquote(str string) string {
+	return strings.Trim(str, "\"")
+}
@@ -0,0 +1,76 @@
+package combatlog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+const testLog = `10/24 21:15:30.000  SPELL_AURA_APPLIED,0x0E00000000000001,"Tester",0x511,0x0E00000000000001,"Tester",0x511,12345,"Some Buff",0x1,BUFF
+10/24 21:15:31.000  SWING_DAMAGE,0x0E00000000000001,"Tester",0x511,0xF130003E9C000001,"Patchwerk",0xa48,1000,0,1,0,0,0,nil,nil,nil
+10/24 21:15:32.000  SPELL_CAST_SUCCESS,0x0E00000000000001,"Tester",0x511,0xF130003E9C000001,"Patchwerk",0xa48,47809,"Shadow Bolt, Rank 13",0x20
+10/24 21:15:32.500  SPELL_DAMAGE,0x0E00000000000001,"Tester",0x511,0xF130003E9C000001,"Patchwerk",0xa48,47809,"Shadow Bolt, Rank 13",0x20,5000,0,32,0,0,0,1,nil,nil
+10/24 21:15:33.000  SWING_MISSED,0x0E00000000000001,"Tester",0x511,0xF130003E9C000001,"Patchwerk",0xa48,MISS
+10/24 21:15:35.000  SPELL_AURA_REMOVED,0x0E00000000000001,"Tester",0x511,0x0E00000000000001,"Tester",0x511,12345,"Some Buff",0x1,BUFF
+10/24 21:15:40.000  UNIT_DIED,0x0000000000000000,nil,0x80000000,0xF130003E9C000001,"Patchwerk",0xa48
+10/24 21:15:50.000  SWING_DAMAGE,0x0E00000000000001,"Tester",0x511,0xF130003E9C000002,"Trash",0xa48,1000,0,1,0,0,0,nil,nil,nil`
+
+func TestParse(t *testing.T) {
+	playerLog, err := Parse(strings.NewReader(testLog), ParseOptions{Player: "Tester", Target: "Patchwerk"})
+	if err != nil {
+		t.Fatalf("Failed to parse log: %s", err)
+	}
+
+	if playerLog.Duration != time.Second*9 {
+		t.Fatalf("Expected duration 9s, got %s", playerLog.Duration)
+	}
+
+	melee := playerLog.Actions[core.ActionID{OtherID: proto.OtherAction_OtherActionAttack}]
+	if melee == nil || melee.Casts != 2 || melee.Misses != 1 || melee.Damage != 1000 {
+		t.Fatalf("Unexpected melee results: %+v", melee)
+	}
+
+	shadowBolt := playerLog.Actions[core.ActionID{SpellID: 47809}]
+	if shadowBolt == nil || shadowBolt.Casts != 1 || shadowBolt.Crits != 1 || shadowBolt.Damage != 5000 {
+		t.Fatalf("Unexpected shadow bolt results: %+v", shadowBolt)
+	}
+	if shadowBolt.Name != "Shadow Bolt, Rank 13" {
+		t.Fatalf("Unexpected spell name: %s", shadowBolt.Name)
+	}
+
+	// Applied before the fight started, so it should only count uptime from the start.
+	buff := playerLog.Auras[core.ActionID{SpellID: 12345}]
+	if buff == nil || buff.Procs != 0 || buff.Uptime != time.Second*4 {
+		t.Fatalf("Unexpected aura results: %+v", buff)
+	}
+}
+
+func TestParseDateRollover(t *testing.T) {
+	testCases := []struct {
+		name  string
+		start string
+		end   string
+	}{
+		{name: "Midnight", start: "10/24 23:59:58.000", end: "10/25 00:00:02.000"},
+		{name: "NewYear", start: "12/31 23:59:58.000", end: "1/1 00:00:02.000"},
+		{name: "LeapDay", start: "2/28 23:59:58.000", end: "2/29 00:00:02.000"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			log := tc.start + `  SWING_DAMAGE,0x0E00000000000001,"Tester",0x511,0xF130003E9C000001,"Patchwerk",0xa48,1000,0,1,0,0,0,nil,nil,nil
+` + tc.end + `  UNIT_DIED,0x0000000000000000,nil,0x80000000,0xF130003E9C000001,"Patchwerk",0xa48`
+
+			playerLog, err := Parse(strings.NewReader(log), ParseOptions{Player: "Tester", Target: "Patchwerk"})
+			if err != nil {
+				t.Fatalf("Failed to parse log: %s", err)
+			}
+			if playerLog.Duration != time.Second*4 {
+				t.Fatalf("Expected duration 4s, got %s", playerLog.Duration)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package combatlog
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Per-ability comparison between a combat log and the sim.
+//
+// The DPS delta is split into two parts: the part explained by casting the
+// ability more or less often (rotation), and the part explained by each cast
+// doing more or less damage (gear, crits and other RNG).
+type ActionComparison struct {
+	ActionID core.ActionID `json:"actionId"`
+	Name     string        `json:"name"`
+
+	LogCastsPerMinute float64 `json:"logCastsPerMinute"`
+	SimCastsPerMinute float64 `json:"simCastsPerMinute"`
+
+	LogDps float64 `json:"logDps"`
+	SimDps float64 `json:"simDps"`
+	LogHps float64 `json:"logHps"`
+	SimHps float64 `json:"simHps"`
+
+	LogCritRate float64 `json:"logCritRate"`
+	SimCritRate float64 `json:"simCritRate"`
+	LogMissRate float64 `json:"logMissRate"`
+	SimMissRate float64 `json:"simMissRate"`
+
+	DpsDelta         float64 `json:"dpsDelta"`
+	RotationDpsDelta float64 `json:"rotationDpsDelta"`
+	PerCastDpsDelta  float64 `json:"perCastDpsDelta"`
+}
+
+type AuraComparison struct {
+	ActionID core.ActionID `json:"actionId"`
+	Name     string        `json:"name"`
+
+	LogUptimePercent float64 `json:"logUptimePercent"`
+	SimUptimePercent float64 `json:"simUptimePercent"`
+
+	LogProcsPerMinute float64 `json:"logProcsPerMinute"`
+	SimProcsPerMinute float64 `json:"simProcsPerMinute"`
+}
+
+type Report struct {
+	Player string `json:"player"`
+
+	LogDurationSeconds float64 `json:"logDurationSeconds"`
+	SimDurationSeconds float64 `json:"simDurationSeconds"`
+
+	LogDps float64 `json:"logDps"`
+	SimDps float64 `json:"simDps"`
+
+	Actions []*ActionComparison `json:"actions"`
+	Auras   []*AuraComparison   `json:"auras"`
+}
+
+// Finds the metrics for the player with the given name in a raid sim result.
+func FindPlayerMetrics(result *proto.RaidSimResult, name string) *proto.UnitMetrics {
+	for _, party := range result.RaidMetrics.Parties {
+		for _, player := range party.Players {
+			if player.Name == name {
+				return player
+			}
+		}
+	}
+	return nil
+}
+
+// Sim-side per-iteration averages for an ability, summed over targets and tags.
+type simAction struct {
+	casts  float64
+	hits   float64
+	crits  float64
+	misses float64
+	damage float64
+	heal   float64
+}
+
+// Builds a comparison report between a player's combat log and their metrics
+// from a raid sim with the given number of iterations.
+func Compare(playerLog *PlayerLog, result *proto.RaidSimResult, unitMetrics *proto.UnitMetrics, iterations int32) *Report {
+	logSeconds := playerLog.Duration.Seconds()
+	simSeconds := result.AvgIterationDuration
+	numIterations := float64(iterations)
+
+	report := &Report{
+		Player:             playerLog.Player,
+		LogDurationSeconds: logSeconds,
+		SimDurationSeconds: simSeconds,
+		SimDps:             unitMetrics.Dps.Avg,
+	}
+
+	// Log and sim actions don't always agree on tags (e.g. MH/OH melee), so compare ignoring them.
+	simActions := make(map[core.ActionID]*simAction)
+	for _, action := range unitMetrics.Actions {
+		actionID := core.ProtoToActionID(*action.Id).WithTag(0)
+		sa, ok := simActions[actionID]
+		if !ok {
+			sa = &simAction{}
+			simActions[actionID] = sa
+		}
+		for _, target := range action.Targets {
+			sa.casts += float64(target.Casts) / numIterations
+			sa.hits += float64(target.Hits+target.Glances+target.Blocks) / numIterations
+			sa.crits += float64(target.Crits) / numIterations
+			sa.misses += float64(target.Misses+target.Dodges+target.Parries) / numIterations
+			sa.damage += target.Damage / numIterations
+			sa.heal += target.Healing / numIterations
+		}
+	}
+
+	actionIDs := make(map[core.ActionID]struct{})
+	for actionID := range simActions {
+		actionIDs[actionID] = struct{}{}
+	}
+	for actionID, action := range playerLog.Actions {
+		actionIDs[actionID] = struct{}{}
+		report.LogDps += action.Damage / logSeconds
+	}
+
+	for actionID := range actionIDs {
+		comparison := &ActionComparison{ActionID: actionID}
+		logCasts := 0.0
+		logDamage := 0.0
+		if action, ok := playerLog.Actions[actionID]; ok {
+			comparison.Name = action.Name
+			logCasts = float64(action.Casts)
+			if logCasts == 0 {
+				// Procs often have no cast event in the log.
+				logCasts = float64(action.Hits + action.Crits + action.Misses)
+			}
+			logDamage = action.Damage
+			comparison.LogCastsPerMinute = logCasts / logSeconds * 60
+			comparison.LogDps = action.Damage / logSeconds
+			comparison.LogHps = action.Healing / logSeconds
+			comparison.LogCritRate = rate(float64(action.Crits), float64(action.Hits+action.Crits))
+			comparison.LogMissRate = rate(float64(action.Misses), float64(action.Hits+action.Crits+action.Misses))
+		}
+
+		simCasts := 0.0
+		simDamage := 0.0
+		if sa, ok := simActions[actionID]; ok {
+			simCasts = sa.casts
+			simDamage = sa.damage
+			comparison.SimCastsPerMinute = sa.casts / simSeconds * 60
+			comparison.SimDps = sa.damage / simSeconds
+			comparison.SimHps = sa.heal / simSeconds
+			comparison.SimCritRate = rate(sa.crits, sa.hits+sa.crits)
+			comparison.SimMissRate = rate(sa.misses, sa.hits+sa.crits+sa.misses)
+		}
+
+		comparison.DpsDelta = comparison.LogDps - comparison.SimDps
+		simDamagePerCast := 0.0
+		if simCasts > 0 {
+			simDamagePerCast = simDamage / simCasts
+		}
+		logDamagePerCast := 0.0
+		if logCasts > 0 {
+			logDamagePerCast = logDamage / logCasts
+		}
+		comparison.RotationDpsDelta = (comparison.LogCastsPerMinute - comparison.SimCastsPerMinute) / 60 * simDamagePerCast
+		comparison.PerCastDpsDelta = comparison.LogCastsPerMinute / 60 * (logDamagePerCast - simDamagePerCast)
+
+		report.Actions = append(report.Actions, comparison)
+	}
+	sort.Slice(report.Actions, func(i, j int) bool {
+		a, b := report.Actions[i], report.Actions[j]
+		if a.LogDps+a.SimDps != b.LogDps+b.SimDps {
+			return a.LogDps+a.SimDps > b.LogDps+b.SimDps
+		}
+		return a.ActionID.String() < b.ActionID.String()
+	})
+
+	auraIDs := make(map[core.ActionID]struct{})
+	simAuras := make(map[core.ActionID]*proto.AuraMetrics)
+	for _, aura := range unitMetrics.Auras {
+		actionID := core.ProtoToActionID(*aura.Id).WithTag(0)
+		simAuras[actionID] = aura
+		auraIDs[actionID] = struct{}{}
+	}
+	for actionID := range playerLog.Auras {
+		auraIDs[actionID] = struct{}{}
+	}
+	for actionID := range auraIDs {
+		comparison := &AuraComparison{ActionID: actionID}
+		if aura, ok := playerLog.Auras[actionID]; ok {
+			comparison.Name = aura.Name
+			comparison.LogUptimePercent = rate(aura.Uptime.Seconds(), logSeconds) * 100
+			comparison.LogProcsPerMinute = float64(aura.Procs) / logSeconds * 60
+		}
+		if aura, ok := simAuras[actionID]; ok {
+			comparison.SimUptimePercent = rate(aura.UptimeSecondsAvg, simSeconds) * 100
+			comparison.SimProcsPerMinute = aura.ProcsAvg / simSeconds * 60
+		}
+		report.Auras = append(report.Auras, comparison)
+	}
+	sort.Slice(report.Auras, func(i, j int) bool {
+		a, b := report.Auras[i], report.Auras[j]
+		if a.LogUptimePercent+a.SimUptimePercent != b.LogUptimePercent+b.SimUptimePercent {
+			return a.LogUptimePercent+a.SimUptimePercent > b.LogUptimePercent+b.SimUptimePercent
+		}
+		return a.ActionID.String() < b.ActionID.String()
+	})
+
+	return report
+}
+
+func rate(numerator float64, denominator float64) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+// Writes the report as aligned text tables.
+func (report *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Player: %s\n", report.Player)
+	fmt.Fprintf(w, "Duration: log %0.1fs, sim %0.1fs\n", report.LogDurationSeconds, report.SimDurationSeconds)
+	fmt.Fprintf(w, "DPS: log %0.1f, sim %0.1f (delta %+0.1f)\n\n", report.LogDps, report.SimDps, report.LogDps-report.SimDps)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Ability\tID\tCPM log\tCPM sim\tDPS log\tDPS sim\tDelta\tRotation\tPer Cast\tCrit log\tCrit sim\tMiss log\tMiss sim\t")
+	for _, action := range report.Actions {
+		fmt.Fprintf(tw, "%s\t%s\t%0.2f\t%0.2f\t%0.1f\t%0.1f\t%+0.1f\t%+0.1f\t%+0.1f\t%0.1f%%\t%0.1f%%\t%0.1f%%\t%0.1f%%\t\n",
+			displayName(action.Name), action.ActionID,
+			action.LogCastsPerMinute, action.SimCastsPerMinute,
+			action.LogDps, action.SimDps,
+			action.DpsDelta, action.RotationDpsDelta, action.PerCastDpsDelta,
+			action.LogCritRate*100, action.SimCritRate*100,
+			action.LogMissRate*100, action.SimMissRate*100)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Aura\tID\tUptime log\tUptime sim\tPPM log\tPPM sim\t")
+	for _, aura := range report.Auras {
+		fmt.Fprintf(tw, "%s\t%s\t%0.1f%%\t%0.1f%%\t%0.2f\t%0.2f\t\n",
+			displayName(aura.Name), aura.ActionID,
+			aura.LogUptimePercent, aura.SimUptimePercent,
+			aura.LogProcsPerMinute, aura.SimProcsPerMinute)
+	}
+	return tw.Flush()
+}
+
+func displayName(name string) string {
+	if name == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(name, "\t", " ")
+}
@@ -0,0 +1,101 @@
+package combatlog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestCompare(t *testing.T) {
+	playerLog, err := Parse(strings.NewReader(testLog), ParseOptions{Player: "Tester", Target: "Patchwerk"})
+	if err != nil {
+		t.Fatalf("Failed to parse log: %s", err)
+	}
+
+	shadowBolt := core.ActionID{SpellID: 47809}
+	melee := core.ActionID{OtherID: proto.OtherAction_OtherActionAttack}
+	buff := core.ActionID{SpellID: 12345}
+
+	// 10 iterations of 10s each.
+	unitMetrics := &proto.UnitMetrics{
+		Name: "Tester",
+		Dps:  &proto.DistributionMetrics{Avg: 1300},
+		Actions: []*proto.ActionMetrics{
+			{
+				Id:      shadowBolt.ToProto(),
+				Targets: []*proto.TargetedActionMetrics{{Casts: 20, Hits: 10, Crits: 10, Damage: 100000}},
+			},
+			// Tags are ignored, so both hands are compared against the log's melee.
+			{
+				Id:      melee.WithTag(1).ToProto(),
+				Targets: []*proto.TargetedActionMetrics{{Casts: 30, Hits: 30, Damage: 30000}},
+			},
+			{
+				Id:      melee.WithTag(2).ToProto(),
+				Targets: []*proto.TargetedActionMetrics{{Casts: 10, Misses: 10}},
+			},
+		},
+		Auras: []*proto.AuraMetrics{
+			{Id: buff.ToProto(), UptimeSecondsAvg: 5, ProcsAvg: 1},
+		},
+	}
+	result := &proto.RaidSimResult{
+		RaidMetrics: &proto.RaidMetrics{
+			Parties: []*proto.PartyMetrics{{Players: []*proto.UnitMetrics{unitMetrics}}},
+		},
+		AvgIterationDuration: 10,
+	}
+
+	if FindPlayerMetrics(result, "Tester") != unitMetrics {
+		t.Fatalf("Expected to find the player's metrics")
+	}
+	report := Compare(playerLog, result, unitMetrics, 10)
+
+	expectClose := func(name string, expected float64, actual float64) {
+		t.Helper()
+		if !core.WithinToleranceFloat64(expected, actual, 0.01) {
+			t.Fatalf("Expected %s to be %0.2f, got %0.2f", name, expected, actual)
+		}
+	}
+
+	expectClose("log dps", 6000.0/9, report.LogDps)
+	expectClose("sim dps", 1300, report.SimDps)
+
+	if len(report.Actions) != 2 || report.Actions[0].ActionID != shadowBolt || report.Actions[1].ActionID != melee {
+		t.Fatalf("Expected shadow bolt and melee comparisons, in order of dps, got %+v", report.Actions)
+	}
+
+	sb := report.Actions[0]
+	expectClose("shadow bolt log cpm", 60.0/9, sb.LogCastsPerMinute)
+	expectClose("shadow bolt sim cpm", 12, sb.SimCastsPerMinute)
+	expectClose("shadow bolt log dps", 5000.0/9, sb.LogDps)
+	expectClose("shadow bolt sim dps", 1000, sb.SimDps)
+	expectClose("shadow bolt log crit rate", 1, sb.LogCritRate)
+	expectClose("shadow bolt sim crit rate", 0.5, sb.SimCritRate)
+	// Every cast does the same damage, so the whole delta comes from casting less often.
+	expectClose("shadow bolt dps delta", 5000.0/9-1000, sb.DpsDelta)
+	expectClose("shadow bolt rotation dps delta", 5000.0/9-1000, sb.RotationDpsDelta)
+	expectClose("shadow bolt per cast dps delta", 0, sb.PerCastDpsDelta)
+
+	meleeComparison := report.Actions[1]
+	expectClose("melee sim cpm", 24, meleeComparison.SimCastsPerMinute)
+	expectClose("melee log miss rate", 0.5, meleeComparison.LogMissRate)
+	expectClose("melee sim miss rate", 0.25, meleeComparison.SimMissRate)
+
+	if len(report.Auras) != 1 || report.Auras[0].ActionID != buff {
+		t.Fatalf("Expected a single aura comparison, got %+v", report.Auras)
+	}
+	expectClose("aura log uptime", 400.0/9, report.Auras[0].LogUptimePercent)
+	expectClose("aura sim uptime", 50, report.Auras[0].SimUptimePercent)
+	expectClose("aura sim ppm", 6, report.Auras[0].SimProcsPerMinute)
+
+	var text strings.Builder
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("Failed to write report: %s", err)
+	}
+	if !strings.Contains(text.String(), "Shadow Bolt, Rank 13") {
+		t.Fatalf("Expected the report to name Shadow Bolt, got:\n%s", text.String())
+	}
+}
@@ -169,6 +169,7 @@ func init() {
 
 			ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 				curTarget := target
+				numHits := core.MinInt32(numHits, sim.GetNumActiveTargets())
 				for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 					result := spell.CalcDamage(sim, curTarget, 0, spell.OutcomeMagicHit)
 					if result.Landed() {
@@ -168,6 +168,7 @@ func NewAgent(party *Party, partyIndex int, player proto.Player) Agent {
 	}
 
 	character := NewCharacter(party, partyIndex, player)
+	character.Spec = PlayerProtoToSpec(player)
 	return factory(character, player)
 }
 
@@ -2,6 +2,8 @@
 package core
 
 import (
+	"context"
+
 	"github.com/wowsims/wotlk/sim/core/items"
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
@@ -48,21 +50,96 @@ func ComputeStats(csr *proto.ComputeStatsRequest) *proto.ComputeStatsResult {
 func StatWeights(request *proto.StatWeightsRequest) *proto.StatWeightsResult {
 	statsToWeigh := stats.ProtoArrayToStatsList(request.StatsToWeigh)
 
-	result := CalcStatWeight(*request, statsToWeigh, stats.Stat(request.EpReferenceStat), nil)
+	result := CalcStatWeight(context.Background(), *request, statsToWeigh, stats.Stat(request.EpReferenceStat), nil)
 
 	return result.ToProto()
 }
 
 func StatWeightsAsync(request *proto.StatWeightsRequest, progress chan *proto.ProgressMetrics) {
+	StatWeightsAsyncWithContext(context.Background(), request, progress)
+}
+
+// Like StatWeightsAsync, but stops early with an error result if ctx is cancelled.
+func StatWeightsAsyncWithContext(ctx context.Context, request *proto.StatWeightsRequest, progress chan *proto.ProgressMetrics) {
 	statsToWeigh := stats.ProtoArrayToStatsList(request.StatsToWeigh)
 	go func() {
-		result := CalcStatWeight(*request, statsToWeigh, stats.Stat(request.EpReferenceStat), progress)
+		result := CalcStatWeight(ctx, *request, statsToWeigh, stats.Stat(request.EpReferenceStat), progress)
 		progress <- &proto.ProgressMetrics{
 			FinalWeightResult: result.ToProto(),
 		}
 	}()
 }
 
+/**
+ * Returns DPS, TPS and DTPS at each point of one or two stat ranges.
+ */
+func StatCurve(request *proto.StatCurveRequest) *proto.StatCurveResult {
+	return CalcStatCurve(context.Background(), request, nil)
+}
+
+func StatCurveAsync(request *proto.StatCurveRequest, progress chan *proto.ProgressMetrics) {
+	StatCurveAsyncWithContext(context.Background(), request, progress)
+}
+
+// Like StatCurveAsync, but stops early with an error result if ctx is cancelled.
+func StatCurveAsyncWithContext(ctx context.Context, request *proto.StatCurveRequest, progress chan *proto.ProgressMetrics) {
+	go func() {
+		result := CalcStatCurve(ctx, request, progress)
+		progress <- &proto.ProgressMetrics{
+			FinalStatCurveResult: result,
+		}
+	}()
+}
+
+/**
+ * Searches candidate items for the best gear sets, and returns them ranked by DPS.
+ */
+func GearOptimizer(request *proto.GearOptimizerRequest) *proto.GearOptimizerResult {
+	return OptimizeGear(context.Background(), request, nil)
+}
+
+func GearOptimizerAsync(request *proto.GearOptimizerRequest, progress chan *proto.ProgressMetrics) {
+	GearOptimizerAsyncWithContext(context.Background(), request, progress)
+}
+
+// Like GearOptimizerAsync, but stops early with an error result if ctx is cancelled.
+func GearOptimizerAsyncWithContext(ctx context.Context, request *proto.GearOptimizerRequest, progress chan *proto.ProgressMetrics) {
+	go func() {
+		result := OptimizeGear(ctx, request, progress)
+		progress <- &proto.ProgressMetrics{
+			FinalGearOptimizerResult: result,
+		}
+	}()
+}
+
+/**
+ * Picks the best gems and enchants for the player's gear.
+ */
+func GemFill(request *proto.GemFillRequest) *proto.GemFillResult {
+	return FillGems(context.Background(), request)
+}
+
+/**
+ * Sims each talent and glyph variant against the player's own, and returns them ranked by DPS.
+ */
+func TalentComparison(request *proto.TalentComparisonRequest) *proto.TalentComparisonResult {
+	return CompareTalents(context.Background(), request, nil)
+}
+
+func TalentComparisonAsync(request *proto.TalentComparisonRequest, progress chan *proto.ProgressMetrics) {
+	TalentComparisonAsyncWithContext(context.Background(), request, progress)
+}
+
+// Like TalentComparisonAsync, but stops early with an error result if ctx is cancelled.
+func TalentComparisonAsyncWithContext(ctx context.Context, request *proto.TalentComparisonRequest, progress chan *proto.ProgressMetrics) {
+	go func() {
+		result := CompareTalents(ctx, request, progress)
+		progress <- &proto.ProgressMetrics{
+			FinalTalentComparisonResult: result,
+		}
+	}()
+}
+
 /**
  * Runs multiple iterations of the sim with a full raid.
  */
@@ -73,3 +150,8 @@ func RunRaidSim(request *proto.RaidSimRequest) *proto.RaidSimResult {
 func RunRaidSimAsync(request *proto.RaidSimRequest, progress chan *proto.ProgressMetrics) {
 	go RunSim(*request, progress)
 }
+
+// Like RunRaidSimAsync, but stops early with an error result if ctx is cancelled.
+func RunRaidSimAsyncWithContext(ctx context.Context, request *proto.RaidSimRequest, progress chan *proto.ProgressMetrics) {
+	go RunSimWithContext(ctx, *request, progress)
+}
@@ -98,9 +98,13 @@ func (weapon Weapon) GetSpellSchool() SpellSchool {
 }
 
 func (weapon Weapon) EnemyWeaponDamage(sim *Simulation, attackPower float64) float64 {
-	// Maximum damage range is 135% of minimum damage; AP contribution is % of minimum damage roll
+	// Maximum damage range is 135% of minimum damage unless set; AP contribution is % of minimum damage roll
 	// TODO: Scrape more logs to determine this value, it was 133% in classic but seems to be slightly higher?
-	rand := 1 + 0.35*sim.RandomFloat("Enemy Weapon Damage")
+	spread := 0.35
+	if weapon.BaseDamageMin > 0 && weapon.BaseDamageMax > weapon.BaseDamageMin {
+		spread = weapon.BaseDamageMax/weapon.BaseDamageMin - 1
+	}
+	rand := 1 + spread*sim.RandomFloat("Enemy Weapon Damage")
 	return weapon.BaseDamageMin * (rand + attackPower*EnemyAutoAttackAPCoefficient)
 }
 
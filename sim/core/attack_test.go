@@ -126,7 +126,8 @@ func (fa *FakeAgent) Reset(sim *Simulation) {
 
 }
 func (fa *FakeAgent) OnGCDReady(sim *Simulation) {
-
+	fa.Spell.Cast(sim, fa.CurrentTarget)
+	fa.WaitUntil(sim, sim.CurrentTime+GCDDefault)
 }
 func (fa *FakeAgent) OnAutoAttack(sim *Simulation, spell *Spell) {
 
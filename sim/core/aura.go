@@ -180,6 +180,9 @@ func (aura *Aura) SetStacks(sim *Simulation, newStacks int32) {
 	if sim.Log != nil {
 		aura.Unit.Log(sim, "%s stacks: %d --> %d", aura.ActionID, oldStacks, newStacks)
 	}
+	if sim.EventLog != nil {
+		aura.logStacksEvent(sim, oldStacks, newStacks)
+	}
 	aura.stacks = newStacks
 	if aura.OnStacksChange != nil {
 		aura.OnStacksChange(aura, sim, oldStacks, newStacks)
@@ -459,8 +462,21 @@ restart:
 }
 
 func (at *auraTracker) doneIteration(sim *Simulation) {
-	// Expire all the remaining auras. Need to keep looping because sometimes
-	// expiring auras can trigger other auras.
+	at.expireAll(sim)
+
+	for _, aura := range at.auras {
+		aura.doneIteration(sim)
+	}
+
+	// Add metrics for any auras that are still active.
+	for _, aura := range at.auras {
+		aura.metrics.doneIteration()
+	}
+}
+
+// Expires all active auras.
+func (at *auraTracker) expireAll(sim *Simulation) {
+	// Need to keep looping because sometimes expiring auras can trigger other auras.
 	foundUnexpired := true
 	for foundUnexpired {
 		foundUnexpired = false
@@ -471,15 +487,6 @@ func (at *auraTracker) doneIteration(sim *Simulation) {
 			}
 		}
 	}
-
-	for _, aura := range at.auras {
-		aura.doneIteration(sim)
-	}
-
-	// Add metrics for any auras that are still active.
-	for _, aura := range at.auras {
-		aura.metrics.doneIteration()
-	}
 }
 
 // Adds a new aura to the simulation. If an aura with the same ID already
@@ -490,6 +497,9 @@ func (aura *Aura) Activate(sim *Simulation) {
 		if sim.Log != nil && !aura.ActionID.IsEmptyAction() {
 			aura.Unit.Log(sim, "Aura refreshed: %s", aura.ActionID)
 		}
+		if sim.EventLog != nil && !aura.ActionID.IsEmptyAction() {
+			aura.logEvent(sim, proto.CombatLogEventType_CombatLogEventTypeAuraRefreshed)
+		}
 		aura.Refresh(sim)
 		return
 	}
@@ -581,6 +591,9 @@ func (aura *Aura) Activate(sim *Simulation) {
 	if sim.Log != nil && !aura.ActionID.IsEmptyAction() {
 		aura.Unit.Log(sim, "Aura gained: %s", aura.ActionID)
 	}
+	if sim.EventLog != nil && !aura.ActionID.IsEmptyAction() {
+		aura.logEvent(sim, proto.CombatLogEventType_CombatLogEventTypeAuraGained)
+	}
 
 	if aura.OnGain != nil {
 		aura.OnGain(aura, sim)
@@ -695,6 +708,9 @@ func (aura *Aura) Deactivate(sim *Simulation) {
 	if sim.Log != nil && !aura.ActionID.IsEmptyAction() {
 		aura.Unit.Log(sim, "Aura faded: %s", aura.ActionID)
 	}
+	if sim.EventLog != nil && !aura.ActionID.IsEmptyAction() {
+		aura.logEvent(sim, proto.CombatLogEventType_CombatLogEventTypeAuraFaded)
+	}
 
 	aura.expires = 0
 	if aura.activeIndex != Inactive {
@@ -899,6 +915,15 @@ func (at *auraTracker) GetMetricsProto(numIterations int32) []*proto.AuraMetrics
 	return metrics
 }
 
+// Combines the aura metrics of the same unit from another Simulation into this tracker.
+func (at *auraTracker) mergeMetrics(other *auraTracker) {
+	for _, otherAura := range other.auras {
+		if aura := at.GetAura(otherAura.Label); aura != nil {
+			aura.metrics.merge(&otherAura.metrics)
+		}
+	}
+}
+
 // Returns the same Aura for chaining.
 func MakePermanent(aura *Aura) *Aura {
 	aura.Duration = NeverExpires
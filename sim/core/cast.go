@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
 )
 
@@ -21,6 +22,7 @@ type Hardcast struct {
 	Expires    time.Duration
 	OnComplete func(*Simulation, *Unit)
 	Target     *Unit
+	Spell      *Spell // Only set for spell casts, not for HardcastWaitUntil.
 }
 
 func (hc *Hardcast) OnExpire(sim *Simulation) {
@@ -72,10 +74,11 @@ func (spell *Spell) makeCastFunc(config CastConfig, onCastComplete CastFunc) Cas
 	return spell.wrapCastFuncInit(config,
 		spell.wrapCastFuncResources(config,
 			spell.wrapCastFuncHaste(config,
-				spell.wrapCastFuncGCD(config,
-					spell.wrapCastFuncCooldown(config,
-						spell.wrapCastFuncSharedCooldown(config,
-							spell.makeCastFuncWait(config, onCastComplete)))))))
+				spell.wrapCastFuncMovement(config,
+					spell.wrapCastFuncGCD(config,
+						spell.wrapCastFuncCooldown(config,
+							spell.wrapCastFuncSharedCooldown(config,
+								spell.makeCastFuncWait(config, onCastComplete))))))))
 }
 
 func (spell *Spell) ApplyCostModifiers(cost float64) float64 {
@@ -201,6 +204,21 @@ func (spell *Spell) wrapCastFuncHaste(config CastConfig, onCastComplete CastFunc
 	}
 }
 
+func (spell *Spell) wrapCastFuncMovement(config CastConfig, onCastComplete CastFunc) CastFunc {
+	if config.DefaultCast.CastTime == 0 && config.DefaultCast.ChannelTime == 0 {
+		return onCastComplete
+	}
+
+	return func(sim *Simulation, target *Unit) {
+		// Hard casts and channels can't begin until the unit stops moving.
+		if (spell.CurCast.CastTime != 0 || spell.CurCast.ChannelTime != 0) && spell.Unit.IsMoving(sim) {
+			spell.CurCast.CastTime += spell.Unit.MovementEndsAt(sim) - sim.CurrentTime
+		}
+
+		onCastComplete(sim, target)
+	}
+}
+
 func (spell *Spell) wrapCastFuncGCD(config CastConfig, onCastComplete CastFunc) CastFunc {
 	if config.DefaultCast.GCD == 0 {
 		return onCastComplete
@@ -300,7 +318,8 @@ func (spell *Spell) makeCastFuncWait(config CastConfig, onCastComplete CastFunc)
 		}
 	}
 
-	if config.DefaultCast.CastTime == 0 {
+	// Channels can be delayed by movement, so need to handle cast times as well.
+	if config.DefaultCast.CastTime == 0 && config.DefaultCast.ChannelTime == 0 {
 		if spell.Flags.Matches(SpellFlagNoLogs) {
 			return onCastComplete
 		} else {
@@ -313,6 +332,10 @@ func (spell *Spell) makeCastFuncWait(config CastConfig, onCastComplete CastFunc)
 						spell.Unit.Log(sim, "Completed cast %s", spell.ActionID)
 					}
 				}
+				if sim.EventLog != nil && !spell.ActionID.IsEmptyAction() {
+					spell.logCastEvent(sim, proto.CombatLogEventType_CombatLogEventTypeCastStart, target, MaxFloat(0, spell.CurCast.Cost), spell.CurCast.CastTime)
+					spell.logCastEvent(sim, proto.CombatLogEventType_CombatLogEventTypeCastComplete, target, 0, 0)
+				}
 				onCastComplete(sim, target)
 			}
 		}
@@ -326,6 +349,9 @@ func (spell *Spell) makeCastFuncWait(config CastConfig, onCastComplete CastFunc)
 						spell.Unit.Log(sim, "Completed cast %s", spell.ActionID)
 					}
 				}
+				if sim.EventLog != nil && !spell.ActionID.IsEmptyAction() {
+					spell.logCastEvent(sim, proto.CombatLogEventType_CombatLogEventTypeCastComplete, target, 0, 0)
+				}
 				oldOnCastComplete3(sim, target)
 			}
 		}
@@ -335,6 +361,9 @@ func (spell *Spell) makeCastFuncWait(config CastConfig, onCastComplete CastFunc)
 				spell.Unit.Log(sim, "Casting %s (Cost = %0.03f, Cast Time = %s)",
 					spell.ActionID, MaxFloat(0, spell.CurCast.Cost), spell.CurCast.CastTime)
 			}
+			if sim.EventLog != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
+				spell.logCastEvent(sim, proto.CombatLogEventType_CombatLogEventTypeCastStart, target, MaxFloat(0, spell.CurCast.Cost), spell.CurCast.CastTime)
+			}
 
 			// For instant-cast spells we can skip creating an aura.
 			if spell.CurCast.CastTime == 0 {
@@ -343,6 +372,7 @@ func (spell *Spell) makeCastFuncWait(config CastConfig, onCastComplete CastFunc)
 				spell.Unit.Hardcast.Expires = sim.CurrentTime + spell.CurCast.CastTime
 				spell.Unit.Hardcast.OnComplete = onCastComplete
 				spell.Unit.Hardcast.Target = target
+				spell.Unit.Hardcast.Spell = spell
 
 				// If hardcast and GCD happen at the same time then we don't need a separate action.
 				if spell.Unit.Hardcast.Expires != spell.Unit.NextGCDAt() {
@@ -353,6 +383,10 @@ func (spell *Spell) makeCastFuncWait(config CastConfig, onCastComplete CastFunc)
 					// Delay autoattacks until the cast is complete.
 					spell.Unit.AutoAttacks.DelayMeleeUntil(sim, spell.Unit.Hardcast.Expires)
 				}
+
+				if spell.Flags.Matches(SpellFlagInterruptible) {
+					sim.onInterruptibleCast(sim, spell.Unit)
+				}
 			}
 		}
 	}
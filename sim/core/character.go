@@ -19,6 +19,7 @@ type Character struct {
 	Race         proto.Race
 	ShattFaction proto.ShattrathFaction
 	Class        proto.Class
+	Spec         proto.Spec
 
 	// Current gear.
 	Equip items.Equipment
@@ -349,6 +350,7 @@ func (character *Character) reset(sim *Simulation, agent Agent) {
 
 	if character.Type == PlayerUnit {
 		character.SetGCDTimer(sim, 0)
+		character.scheduleMovement(sim, 0)
 	}
 
 	agent.Reset(sim)
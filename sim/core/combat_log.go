@@ -0,0 +1,76 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Structured equivalents of the debug log messages. These are only invoked when
+// sim.EventLog is set, so callers should check it first to avoid building
+// events that will never be read.
+
+// Records a structured combat log event for this unit, filling in the
+// timestamp and unit index. Callers are responsible for the target index.
+func (unit *Unit) LogEvent(sim *Simulation, event *proto.CombatLogEvent) {
+	event.Timestamp = sim.CurrentTime.Seconds()
+	event.UnitIndex = unit.UnitIndex
+	sim.EventLog(event)
+}
+
+func (unit *Unit) LogResourceEvent(sim *Simulation, eventType proto.CombatLogEventType, resourceType proto.ResourceType, actionID ActionID, amount float64, before float64, after float64) {
+	unit.LogEvent(sim, &proto.CombatLogEvent{
+		Type:           eventType,
+		TargetIndex:    -1,
+		Id:             actionID.ToProto(),
+		Amount:         amount,
+		ResourceType:   resourceType,
+		ResourceBefore: before,
+		ResourceAfter:  after,
+	})
+}
+
+func (spell *Spell) logCastEvent(sim *Simulation, eventType proto.CombatLogEventType, target *Unit, cost float64, castTime time.Duration) {
+	event := &proto.CombatLogEvent{
+		Type:     eventType,
+		Id:       spell.ActionID.ToProto(),
+		Cost:     cost,
+		CastTime: castTime.Seconds(),
+	}
+	if target != nil {
+		event.TargetIndex = target.UnitIndex
+	} else {
+		event.TargetIndex = -1
+	}
+	spell.Unit.LogEvent(sim, event)
+}
+
+func (spell *Spell) logResultEvent(sim *Simulation, eventType proto.CombatLogEventType, result *SpellEffect) {
+	spell.Unit.LogEvent(sim, &proto.CombatLogEvent{
+		Type:        eventType,
+		TargetIndex: result.Target.UnitIndex,
+		Id:          spell.ActionID.ToProto(),
+		Outcome:     result.Outcome.String(),
+		Amount:      result.Damage,
+		Threat:      result.calcThreat(spell),
+		IsPeriodic:  result.IsPeriodic,
+	})
+}
+
+func (aura *Aura) logStacksEvent(sim *Simulation, oldStacks int32, newStacks int32) {
+	aura.Unit.LogEvent(sim, &proto.CombatLogEvent{
+		Type:        proto.CombatLogEventType_CombatLogEventTypeAuraStacksChanged,
+		TargetIndex: -1,
+		Id:          aura.ActionID.ToProto(),
+		OldStacks:   oldStacks,
+		NewStacks:   newStacks,
+	})
+}
+
+func (aura *Aura) logEvent(sim *Simulation, eventType proto.CombatLogEventType) {
+	aura.Unit.LogEvent(sim, &proto.CombatLogEvent{
+		Type:        eventType,
+		TargetIndex: -1,
+		Id:          aura.ActionID.ToProto(),
+	})
+}
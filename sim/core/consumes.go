@@ -1225,7 +1225,7 @@ func (character *Character) newBasicExplosiveSpellConfig(sharedTimer *Timer, act
 
 		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
 			// TODO: AOE Cap
-			for _, aoeTarget := range sim.Encounter.Targets {
+			for _, aoeTarget := range sim.Encounter.ActiveTargets {
 				spell.CalcAndDealDamageMagicHitAndCrit(sim, &aoeTarget.Unit, sim.Roll(minDamage, maxDamage))
 			}
 			// TODO: Deal self-damage
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type timelineDebuffFactory func(target *Unit, debuffs *proto.Debuffs) *Aura
+
+// Debuffs which can be scheduled with a proto.DebuffEvent timeline, keyed by
+// their field name in proto.Debuffs. Options such as talent points come from
+// the raid's Debuffs where possible.
+var timelineDebuffs = map[string]timelineDebuffFactory{
+	"judgement_of_wisdom": func(target *Unit, _ *proto.Debuffs) *Aura { return JudgementOfWisdomAura(target) },
+	"judgement_of_light":  func(target *Unit, _ *proto.Debuffs) *Aura { return JudgementOfLightAura(target) },
+	"misery":              func(target *Unit, _ *proto.Debuffs) *Aura { return MiseryAura(target) },
+	"faerie_fire": func(target *Unit, debuffs *proto.Debuffs) *Aura {
+		return FaerieFireAura(target, debuffs.FaerieFire == proto.TristateEffect_TristateEffectImproved)
+	},
+	"curse_of_elements":     func(target *Unit, _ *proto.Debuffs) *Aura { return CurseOfElementsAura(target) },
+	"earth_and_moon":        func(target *Unit, _ *proto.Debuffs) *Aura { return EarthAndMoonAura(target) },
+	"heart_of_the_crusader": func(target *Unit, _ *proto.Debuffs) *Aura { return HeartoftheCrusaderDebuff(target, 3) },
+	"master_poisoner":       func(target *Unit, _ *proto.Debuffs) *Aura { return MasterPoisonerDebuff(target, 3) },
+	"totem_of_wrath":        func(target *Unit, _ *proto.Debuffs) *Aura { return TotemOfWrathDebuff(target) },
+	"shadow_mastery":        func(target *Unit, _ *proto.Debuffs) *Aura { return ShadowMasteryAura(target) },
+	"improved_scorch":       func(target *Unit, _ *proto.Debuffs) *Aura { return ImprovedScorchAura(target) },
+	"winters_chill":         func(target *Unit, _ *proto.Debuffs) *Aura { return WintersChillAura(target, 1) },
+	"blood_frenzy":          func(target *Unit, _ *proto.Debuffs) *Aura { return BloodFrenzyAura(target, 2) },
+	"savage_combat":         func(target *Unit, _ *proto.Debuffs) *Aura { return SavageCombatAura(target, 2) },
+	"gift_of_arthas":        func(target *Unit, _ *proto.Debuffs) *Aura { return GiftOfArthasAura(target) },
+	"mangle":                func(target *Unit, _ *proto.Debuffs) *Aura { return MangleAura(target) },
+	"trauma":                func(target *Unit, _ *proto.Debuffs) *Aura { return TraumaAura(target, 2) },
+	"expose_armor":          func(target *Unit, _ *proto.Debuffs) *Aura { return ExposeArmorAura(target, false) },
+	"sunder_armor":          func(target *Unit, _ *proto.Debuffs) *Aura { return SunderArmorAura(target, 1) },
+	"acid_spit":             func(target *Unit, _ *proto.Debuffs) *Aura { return AcidSpitAura(target, 1) },
+	"curse_of_weakness":     func(target *Unit, _ *proto.Debuffs) *Aura { return CurseOfWeaknessAura(target, 2) },
+	"sting":                 func(target *Unit, _ *proto.Debuffs) *Aura { return StingAura(target) },
+	"spore_cloud":           func(target *Unit, _ *proto.Debuffs) *Aura { return SporeCloudAura(target) },
+	"demoralizing_roar": func(target *Unit, debuffs *proto.Debuffs) *Aura {
+		return DemoralizingRoarAura(target, GetTristateValueInt32(debuffs.DemoralizingRoar, 0, 5))
+	},
+	"demoralizing_shout": func(target *Unit, debuffs *proto.Debuffs) *Aura {
+		return DemoralizingShoutAura(target, 0, GetTristateValueInt32(debuffs.DemoralizingShout, 0, 5))
+	},
+	"vindication": func(target *Unit, _ *proto.Debuffs) *Aura { return VindicationAura(target) },
+	"thunder_clap": func(target *Unit, debuffs *proto.Debuffs) *Aura {
+		return ThunderClapAura(target, GetTristateValueInt32(debuffs.ThunderClap, 0, 3))
+	},
+	"frost_fever": func(target *Unit, debuffs *proto.Debuffs) *Aura {
+		return FrostFeverAura(target, GetTristateValueInt32(debuffs.FrostFever, 0, 3))
+	},
+	"infected_wounds":        func(target *Unit, _ *proto.Debuffs) *Aura { return InfectedWoundsAura(target, 3) },
+	"judgements_of_the_just": func(target *Unit, _ *proto.Debuffs) *Aura { return JudgementsOfTheJustAura(target, 2) },
+	"insect_swarm":           func(target *Unit, _ *proto.Debuffs) *Aura { return InsectSwarmAura(target) },
+	"scorpid_sting":          func(target *Unit, _ *proto.Debuffs) *Aura { return ScorpidStingAura(target) },
+	"screech":                func(target *Unit, _ *proto.Debuffs) *Aura { return ScreechAura(target) },
+}
+
+// Returns a copy of the raid's debuffs without those which follow a timeline
+// on the target instead.
+func withoutTimelineDebuffs(debuffs *proto.Debuffs, timeline []*proto.DebuffEvent) *proto.Debuffs {
+	debuffs = googleProto.Clone(debuffs).(*proto.Debuffs)
+	fields := debuffs.ProtoReflect().Descriptor().Fields()
+	for _, event := range timeline {
+		if field := fields.ByName(protoreflect.Name(event.Debuff)); field != nil {
+			debuffs.ProtoReflect().Clear(field)
+		}
+	}
+	return debuffs
+}
+
+// A debuff applied by a timeline. The aura is shared with players who apply
+// the same debuff, so its duration is only changed while an event is active.
+type timelineDebuff struct {
+	aura     *Aura
+	duration time.Duration
+
+	// Number of timeline events currently keeping the debuff up.
+	numActive int
+}
+
+// Applies debuffs to a target according to its timeline, see proto.DebuffEvent.
+func (target *Target) setupDebuffTimeline(timeline []*proto.DebuffEvent, raidDebuffs *proto.Debuffs) {
+	if len(timeline) == 0 {
+		return
+	}
+	if raidDebuffs == nil {
+		raidDebuffs = &proto.Debuffs{}
+	}
+
+	debuffs := make(map[string]*timelineDebuff)
+	for _, event := range timeline {
+		factory, ok := timelineDebuffs[event.Debuff]
+		if !ok {
+			panic(fmt.Sprintf("Debuff %s can't be used in a debuff timeline", event.Debuff))
+		}
+		if event.EndTime != 0 && event.EndTime <= event.StartTime {
+			panic(fmt.Sprintf("Debuff timeline event for %s ends before it starts", event.Debuff))
+		}
+		if debuffs[event.Debuff] == nil {
+			aura := factory(&target.Unit, raidDebuffs)
+			debuffs[event.Debuff] = &timelineDebuff{
+				aura:     aura,
+				duration: aura.Duration,
+			}
+		}
+	}
+
+	target.RegisterResetEffect(func(sim *Simulation) {
+		for _, debuff := range debuffs {
+			debuff.aura.Duration = debuff.duration
+			debuff.numActive = 0
+		}
+		for _, event := range timeline {
+			debuffs[event.Debuff].schedule(sim, event)
+		}
+	})
+}
+
+func (debuff *timelineDebuff) schedule(sim *Simulation, event *proto.DebuffEvent) {
+	aura := debuff.aura
+	stackInterval := DurationFromSeconds(event.StackInterval)
+	active := false
+
+	sim.AddPendingAction(&PendingAction{
+		NextActionAt: DurationFromSeconds(event.StartTime),
+		Priority:     ActionPriorityDOT, // Before player actions, like ScheduledAura.
+		OnAction: func(sim *Simulation) {
+			active = true
+			debuff.numActive++
+			aura.Duration = NeverExpires
+			aura.Activate(sim)
+			if aura.MaxStacks == 0 {
+				return
+			}
+
+			// Overlapping events keep the highest of their stacks, rather than adding up.
+			stacks := aura.MaxStacks
+			if stackInterval != 0 {
+				stacks = 1
+			}
+			aura.SetStacks(sim, MaxInt32(aura.GetStacks(), stacks))
+			if stacks == aura.MaxStacks {
+				return
+			}
+			StartPeriodicAction(sim, PeriodicActionOptions{
+				Period:   stackInterval,
+				NumTicks: int(aura.MaxStacks - stacks),
+				Priority: ActionPriorityDOT,
+				OnAction: func(sim *Simulation) {
+					stacks++
+					if active && aura.IsActive() {
+						aura.SetStacks(sim, MaxInt32(aura.GetStacks(), stacks))
+					}
+				},
+			})
+		},
+	})
+
+	if event.EndTime == 0 {
+		return
+	}
+	sim.AddPendingAction(&PendingAction{
+		NextActionAt: DurationFromSeconds(event.EndTime),
+		Priority:     ActionPriorityDOT,
+		OnAction: func(sim *Simulation) {
+			active = false
+			debuff.numActive--
+			if debuff.numActive == 0 {
+				// Players applying the debuff from now on get its usual duration.
+				aura.Duration = debuff.duration
+				aura.Deactivate(sim)
+			}
+		},
+	})
+}
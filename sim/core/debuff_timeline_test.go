@@ -0,0 +1,109 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Runs the pending actions of the current iteration up to and including end.
+func runPendingActionsUntil(sim *Simulation, end time.Duration) {
+	for sim.pendingActions.len() > 0 {
+		pa := sim.pendingActions.pop()
+		if pa.cancelled {
+			continue
+		}
+		if pa.NextActionAt > end {
+			sim.pendingActions.push(pa)
+			break
+		}
+		if pa.NextActionAt > sim.CurrentTime {
+			sim.advance(pa.NextActionAt - sim.CurrentTime)
+		}
+		pa.consumed = true
+		pa.OnAction(sim)
+	}
+	if end > sim.CurrentTime {
+		sim.advance(end - sim.CurrentTime)
+	}
+}
+
+func TestDebuffTimelineSharedAura(t *testing.T) {
+	request := fakeSimRequest()
+	request.Encounter.Targets[0].DebuffTimeline = []*proto.DebuffEvent{
+		{Debuff: "sunder_armor", StartTime: 0, EndTime: 10, StackInterval: 1},
+		{Debuff: "sunder_armor", StartTime: 2, EndTime: 20, StackInterval: 1},
+	}
+	sim := NewSim(*request)
+	sim.Reset()
+	// The fake player has no rotation.
+	sim.Raid.Parties[0].Players[0].GetCharacter().gcdAction.Cancel(sim)
+	sunder := SunderArmorAura(sim.GetTargetUnit(0), 1)
+
+	// The second event starts from 1 stack, which doesn't add to the first's.
+	runPendingActionsUntil(sim, time.Millisecond*3500)
+	if sunder.GetStacks() != 4 {
+		t.Fatalf("Expected 4 stacks of Sunder Armor at 3.5s, got %d", sunder.GetStacks())
+	}
+
+	runPendingActionsUntil(sim, time.Second*15)
+	if !sunder.IsActive() || sunder.GetStacks() != 5 {
+		t.Fatalf("Expected the second event to keep 5 stacks of Sunder Armor up, got %d", sunder.GetStacks())
+	}
+
+	runPendingActionsUntil(sim, time.Second*21)
+	if sunder.IsActive() {
+		t.Fatalf("Expected Sunder Armor to drop off after the last event ends")
+	}
+
+	// Players applying the debuff afterwards get its usual duration.
+	sunder.Activate(sim)
+	if sunder.ExpiresAt() != time.Second*51 {
+		t.Fatalf("Expected Sunder Armor applied at 21s to expire at 51s, got %s", sunder.ExpiresAt())
+	}
+}
+
+func TestDebuffTimeline(t *testing.T) {
+	request := fakeSimRequest()
+	request.Raid.Debuffs = &proto.Debuffs{
+		FaerieFire:      proto.TristateEffect_TristateEffectRegular,
+		CurseOfElements: true,
+		SunderArmor:     true,
+	}
+	request.Encounter.Targets[0].DebuffTimeline = []*proto.DebuffEvent{
+		{Debuff: "sunder_armor", StartTime: 3, StackInterval: 1.5},
+		{Debuff: "faerie_fire", StartTime: 0, EndTime: 30},
+		{Debuff: "faerie_fire", StartTime: 40},
+		{Debuff: "curse_of_elements", StartTime: 6},
+	}
+	sim := NewSim(*request)
+	sim.Reset()
+	// The fake player has no rotation.
+	sim.Raid.Parties[0].Players[0].GetCharacter().gcdAction.Cancel(sim)
+	target := sim.GetTargetUnit(0)
+	faerieFire := FaerieFireAura(target, false)
+	curseOfElements := CurseOfElementsAura(target)
+	sunder := SunderArmorAura(target, 1)
+
+	// Debuffs on the timeline aren't applied for the whole fight.
+	runPendingActionsUntil(sim, time.Second)
+	if !faerieFire.IsActive() || curseOfElements.IsActive() || sunder.IsActive() {
+		t.Fatalf("Expected only Faerie Fire to be up at 1s")
+	}
+
+	runPendingActionsUntil(sim, time.Second*7)
+	if !curseOfElements.IsActive() || sunder.GetStacks() != 3 {
+		t.Fatalf("Expected Curse of Elements and 3 stacks of Sunder Armor at 7s, got %d stacks", sunder.GetStacks())
+	}
+
+	runPendingActionsUntil(sim, time.Second*35)
+	if faerieFire.IsActive() {
+		t.Fatalf("Expected Faerie Fire to drop off between events")
+	}
+
+	runPendingActionsUntil(sim, time.Second*100)
+	if !faerieFire.IsActive() || !curseOfElements.IsActive() || sunder.GetStacks() != 5 {
+		t.Fatalf("Expected every debuff to stay up until the end of the fight, got %d stacks of Sunder Armor", sunder.GetStacks())
+	}
+}
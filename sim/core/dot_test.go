@@ -71,7 +71,14 @@ func NewFakeElementalShaman(char Character, options proto.Player) Agent {
 }
 
 func SetupFakeSim() *Simulation {
-	sim := NewSim(proto.RaidSimRequest{
+	sim := NewSim(*fakeSimRequest())
+	sim.Reset()
+
+	return sim
+}
+
+func fakeSimRequest() *proto.RaidSimRequest {
+	return &proto.RaidSimRequest{
 		SimOptions: &proto.SimOptions{
 			RandomSeed: 100,
 		},
@@ -98,10 +105,7 @@ func SetupFakeSim() *Simulation {
 			},
 			Duration: 180,
 		},
-	})
-	sim.Reset()
-
-	return sim
+	}
 }
 
 func expectDotTickDamage(t *testing.T, dot *Dot, expectedDamage float64) {
@@ -0,0 +1,351 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// One phase of a scripted encounter timeline.
+type EncounterPhase struct {
+	Name string
+
+	// Triggers, see proto.EncounterPhase. A zero value means the trigger is unused.
+	StartTime               time.Duration
+	TriggerTarget           *Target
+	TriggerHealthProportion float64
+
+	ActivateTargets   []*Target
+	DeactivateTargets []*Target
+	PrimaryTarget     *Target // nil for no change
+
+	DamageTakenMultiplier float64
+	DamageDealtMultiplier float64
+
+	metrics phaseMetrics
+}
+
+type phaseMetrics struct {
+	// Values for the current iteration.
+	began       bool
+	startedAt   time.Duration
+	startDamage []float64 // Value of Encounter.unitDamage when the phase began.
+
+	// Aggregate values.
+	timesReached int32
+	startSum     float64
+	durationSum  float64
+	raidDamage   float64
+	unitDamage   map[int32]float64
+}
+
+func newEncounterPhases(options []*proto.EncounterPhase, targets []*Target) []*EncounterPhase {
+	getTarget := func(phaseName string, index int32) *Target {
+		if index < 0 || int(index) >= len(targets) {
+			panic(fmt.Sprintf("Encounter phase %s refers to invalid target index %d", phaseName, index))
+		}
+		return targets[index]
+	}
+
+	var phases []*EncounterPhase
+	for i, phaseOptions := range options {
+		phase := &EncounterPhase{
+			Name:                  phaseOptions.Name,
+			StartTime:             DurationFromSeconds(phaseOptions.StartTime),
+			DamageTakenMultiplier: phaseOptions.DamageTakenMultiplier,
+			DamageDealtMultiplier: phaseOptions.DamageDealtMultiplier,
+			metrics: phaseMetrics{
+				unitDamage: make(map[int32]float64),
+			},
+		}
+		if phase.Name == "" {
+			phase.Name = fmt.Sprintf("Phase %d", i+1)
+		}
+		if phase.DamageTakenMultiplier == 0 {
+			phase.DamageTakenMultiplier = 1
+		}
+		if phase.DamageDealtMultiplier == 0 {
+			phase.DamageDealtMultiplier = 1
+		}
+
+		if phaseOptions.TriggerHealthProportion > 0 {
+			phase.TriggerTarget = getTarget(phase.Name, phaseOptions.TriggerTargetIndex)
+			phase.TriggerHealthProportion = phaseOptions.TriggerHealthProportion
+			if !phase.TriggerTarget.HasHealthBar() || phase.TriggerTarget.MaxHealth() <= 0 {
+				panic(fmt.Sprintf("Encounter phase %s is triggered by the health of %s, which has no health", phase.Name, phase.TriggerTarget.Label))
+			}
+		}
+		for _, index := range phaseOptions.ActivateTargets {
+			phase.ActivateTargets = append(phase.ActivateTargets, getTarget(phase.Name, index))
+		}
+		for _, index := range phaseOptions.DeactivateTargets {
+			phase.DeactivateTargets = append(phase.DeactivateTargets, getTarget(phase.Name, index))
+		}
+		if phaseOptions.ChangePrimaryTarget {
+			phase.PrimaryTarget = getTarget(phase.Name, phaseOptions.PrimaryTargetIndex)
+		}
+
+		phases = append(phases, phase)
+	}
+	return phases
+}
+
+// Sets up phases for the encounter, unless it already has some. This lets
+// boss AIs script their own fight, while still allowing users to override it.
+// Must be called during initialization, e.g. from TargetAI.Initialize.
+func (encounter *Encounter) SetDefaultPhases(options []*proto.EncounterPhase) {
+	if len(encounter.Phases) > 0 {
+		return
+	}
+	encounter.Phases = newEncounterPhases(options, encounter.Targets)
+}
+
+// Whether this phase has no triggers, and so begins as soon as it can.
+func (phase *EncounterPhase) isImmediate() bool {
+	return phase.StartTime == 0 && phase.TriggerTarget == nil
+}
+
+func (phase *EncounterPhase) isTriggered(sim *Simulation) bool {
+	if phase.isImmediate() {
+		return true
+	}
+	if phase.StartTime != 0 && sim.CurrentTime >= phase.StartTime {
+		return true
+	}
+	if phase.TriggerTarget != nil && phase.TriggerTarget.HealthProportion() <= phase.TriggerHealthProportion {
+		return true
+	}
+	return false
+}
+
+// Returns the phase the encounter is currently in, or nil if it has no phases
+// or the first one hasn't begun.
+func (encounter *Encounter) CurrentPhase() *EncounterPhase {
+	if encounter.nextPhase == 0 {
+		return nil
+	}
+	return encounter.Phases[encounter.nextPhase-1]
+}
+
+// Makes every target active again, so Reset handlers see the targets present
+// before any phase begins.
+func (encounter *Encounter) resetActiveTargets() {
+	if len(encounter.ActiveTargets) != len(encounter.Targets) {
+		for _, target := range encounter.Targets {
+			target.active = true
+		}
+		encounter.updateActiveTargets()
+	}
+}
+
+func (encounter *Encounter) resetPhases(sim *Simulation) {
+	encounter.primaryTarget = encounter.Targets[0]
+	encounter.nextPhase = 0
+	encounter.phaseTriggerAction = nil
+	encounter.damageTakenMultiplier = 1
+	encounter.damageDealtMultiplier = 1
+	if len(encounter.Phases) > 0 {
+		if encounter.unitDamage == nil {
+			numUnits := int32(0)
+			for _, unit := range sim.Raid.AllUnits {
+				numUnits = MaxInt32(numUnits, unit.UnitIndex+1)
+			}
+			encounter.unitDamage = make([]float64, numUnits)
+		}
+		for i := range encounter.unitDamage {
+			encounter.unitDamage[i] = 0
+		}
+	}
+	for _, phase := range encounter.Phases {
+		phase.metrics.began = false
+	}
+
+	encounter.checkPhaseTriggers(sim)
+}
+
+// Begins the next phase(s) if their triggers have been met, and schedules the
+// time trigger of the next phase that hasn't.
+func (encounter *Encounter) checkPhaseTriggers(sim *Simulation) {
+	for encounter.nextPhase < len(encounter.Phases) {
+		phase := encounter.Phases[encounter.nextPhase]
+		if !phase.isTriggered(sim) {
+			break
+		}
+		encounter.beginPhase(sim, phase)
+	}
+
+	if encounter.phaseTriggerAction != nil {
+		encounter.phaseTriggerAction.Cancel(sim)
+		encounter.phaseTriggerAction = nil
+	}
+	if encounter.nextPhase < len(encounter.Phases) {
+		if startTime := encounter.Phases[encounter.nextPhase].StartTime; startTime != 0 {
+			encounter.phaseTriggerAction = &PendingAction{
+				NextActionAt: startTime,
+				Priority:     ActionPriorityPhase,
+				OnAction: func(sim *Simulation) {
+					encounter.phaseTriggerAction = nil
+					encounter.checkPhaseTriggers(sim)
+				},
+			}
+			sim.AddPendingAction(encounter.phaseTriggerAction)
+		}
+	}
+}
+
+func (encounter *Encounter) beginPhase(sim *Simulation, phase *EncounterPhase) {
+	if prevPhase := encounter.CurrentPhase(); prevPhase != nil {
+		encounter.endPhaseMetrics(sim, prevPhase)
+	}
+	encounter.nextPhase++
+
+	if sim.Log != nil {
+		sim.Log("Encounter phase %s began.", phase.Name)
+	}
+
+	for _, target := range phase.ActivateTargets {
+		encounter.setTargetActive(sim, target, true)
+	}
+	for _, target := range phase.DeactivateTargets {
+		encounter.setTargetActive(sim, target, false)
+	}
+	encounter.updateActiveTargets()
+
+	if phase.PrimaryTarget != nil {
+		encounter.primaryTarget = phase.PrimaryTarget
+		for _, unit := range sim.Raid.AllUnits {
+			if unit.CurrentTarget != nil && unit.CurrentTarget.Type == EnemyUnit {
+				unit.CurrentTarget = &phase.PrimaryTarget.Unit
+			}
+		}
+	}
+	// Anyone attacking a target which just despawned moves on to the primary target.
+	for _, target := range phase.DeactivateTargets {
+		for _, unit := range sim.Raid.AllUnits {
+			if unit.CurrentTarget == &target.Unit {
+				unit.CurrentTarget = &encounter.PrimaryTarget().Unit
+			}
+		}
+	}
+
+	for _, target := range encounter.Targets {
+		target.PseudoStats.DamageTakenMultiplier *= phase.DamageTakenMultiplier / encounter.damageTakenMultiplier
+		target.PseudoStats.DamageDealtMultiplier *= phase.DamageDealtMultiplier / encounter.damageDealtMultiplier
+	}
+	encounter.damageTakenMultiplier = phase.DamageTakenMultiplier
+	encounter.damageDealtMultiplier = phase.DamageDealtMultiplier
+
+	metrics := &phase.metrics
+	metrics.began = true
+	metrics.startedAt = sim.CurrentTime
+	metrics.startDamage = append(metrics.startDamage[:0], encounter.unitDamage...)
+}
+
+func (encounter *Encounter) setTargetActive(sim *Simulation, target *Target, active bool) {
+	if target.active == active || (active && target.dead) {
+		return
+	}
+	target.active = active
+
+	if target.AutoAttacks.IsEnabled() {
+		if active {
+			target.AutoAttacks.EnableAutoSwing(sim)
+		} else {
+			target.AutoAttacks.CancelAutoSwing(sim)
+		}
+	}
+}
+
+func (encounter *Encounter) updateActiveTargets() {
+	// Always build a new slice, since AoE effects may be ranging over the old one.
+	activeTargets := make([]*Target, 0, len(encounter.Targets))
+	for _, target := range encounter.Targets {
+		if target.active {
+			activeTargets = append(activeTargets, target)
+		}
+	}
+	encounter.ActiveTargets = activeTargets
+	encounter.updateAOECapMultiplier()
+}
+
+// Returns the target players should attack by default. This is the first
+// target, unless changed by an encounter phase.
+func (encounter *Encounter) PrimaryTarget() *Target {
+	if !encounter.primaryTarget.active && len(encounter.ActiveTargets) > 0 {
+		return encounter.ActiveTargets[0]
+	}
+	return encounter.primaryTarget
+}
+
+// Returns true if unit is a target which currently can't be attacked.
+func (encounter *Encounter) IsInactiveTarget(unit *Unit) bool {
+	return unit.Type == EnemyUnit && !encounter.Targets[unit.Index].active
+}
+
+func (encounter *Encounter) endPhaseMetrics(sim *Simulation, phase *EncounterPhase) {
+	metrics := &phase.metrics
+	if !metrics.began {
+		return
+	}
+	metrics.began = false
+
+	metrics.timesReached++
+	metrics.startSum += metrics.startedAt.Seconds()
+	metrics.durationSum += (sim.CurrentTime - metrics.startedAt).Seconds()
+	for unitIndex, total := range encounter.unitDamage {
+		damage := total - metrics.startDamage[unitIndex]
+		if damage == 0 {
+			continue
+		}
+		metrics.unitDamage[int32(unitIndex)] += damage
+		metrics.raidDamage += damage
+	}
+}
+
+func (encounter *Encounter) doneIterationPhases(sim *Simulation) {
+	if phase := encounter.CurrentPhase(); phase != nil {
+		encounter.endPhaseMetrics(sim, phase)
+	}
+}
+
+func (encounter *Encounter) mergePhaseMetrics(other *Encounter) {
+	for i, phase := range encounter.Phases {
+		metrics := &phase.metrics
+		otherMetrics := &other.Phases[i].metrics
+		metrics.timesReached += otherMetrics.timesReached
+		metrics.startSum += otherMetrics.startSum
+		metrics.durationSum += otherMetrics.durationSum
+		metrics.raidDamage += otherMetrics.raidDamage
+		for unitIndex, damage := range otherMetrics.unitDamage {
+			metrics.unitDamage[unitIndex] += damage
+		}
+	}
+}
+
+func (phase *EncounterPhase) GetMetricsProto(numIterations int32) *proto.PhaseMetrics {
+	metrics := &phase.metrics
+	phaseProto := &proto.PhaseMetrics{
+		Name:          phase.Name,
+		ChanceReached: float64(metrics.timesReached) / float64(numIterations),
+		UnitDps:       make(map[int32]float64),
+	}
+	if metrics.timesReached == 0 {
+		return phaseProto
+	}
+
+	phaseProto.AvgStartTime = metrics.startSum / float64(metrics.timesReached)
+	phaseProto.AvgDuration = metrics.durationSum / float64(metrics.timesReached)
+	if metrics.durationSum > 0 {
+		phaseProto.RaidDps = metrics.raidDamage / metrics.durationSum
+		for unitIndex, damage := range metrics.unitDamage {
+			phaseProto.UnitDps[unitIndex] = damage / metrics.durationSum
+		}
+	}
+	return phaseProto
+}
+
+// Whether this target can currently be attacked.
+func (target *Target) IsActive() bool {
+	return target.active
+}
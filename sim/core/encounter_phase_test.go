@@ -0,0 +1,103 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestEncounterPhases(t *testing.T) {
+	request := fakeSimRequest()
+	request.Encounter.Targets = []*proto.Target{
+		{Name: "Boss", Level: 83},
+		{Name: "Add", Level: 83, Stats: stats.Stats{stats.Health: 1_000_000}.ToFloatArray()},
+		{Name: "Other Add", Level: 83},
+	}
+	request.Encounter.Phases = []*proto.EncounterPhase{
+		{Name: "Boss", DeactivateTargets: []int32{1, 2}},
+		{Name: "Adds", StartTime: 60, ActivateTargets: []int32{1, 2}, ChangePrimaryTarget: true, PrimaryTargetIndex: 1},
+		{Name: "Burn", TriggerTargetIndex: 1, TriggerHealthProportion: 0.1, DeactivateTargets: []int32{1, 2}, DamageTakenMultiplier: 1.5},
+	}
+	sim := NewSim(*request)
+	sim.Reset()
+	// The fake player has no rotation.
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	fa.gcdAction.Cancel(sim)
+	encounter := &sim.Encounter
+	boss, add := encounter.Targets[0], encounter.Targets[1]
+
+	if phase := encounter.CurrentPhase(); phase == nil || phase.Name != "Boss" {
+		t.Fatalf("Expected the first phase to begin immediately, got %v", phase)
+	}
+	if len(encounter.ActiveTargets) != 1 || !encounter.IsInactiveTarget(&add.Unit) {
+		t.Fatalf("Expected only the boss to be active, got %d active targets", len(encounter.ActiveTargets))
+	}
+
+	runPendingActionsUntil(sim, time.Second*60)
+	if phase := encounter.CurrentPhase(); phase.Name != "Adds" {
+		t.Fatalf("Expected the adds phase to begin at 60s, got %s", phase.Name)
+	}
+	if len(encounter.ActiveTargets) != 3 || encounter.PrimaryTarget() != add || fa.CurrentTarget != &add.Unit {
+		t.Fatalf("Expected the raid to switch to the add once it spawns")
+	}
+
+	runPendingActionsUntil(sim, time.Second*70)
+	encounter.onTargetDamaged(sim, &fa.Unit, &add.Unit, 950_000)
+	if phase := encounter.CurrentPhase(); phase.Name != "Burn" {
+		t.Fatalf("Expected the add's health to trigger the burn phase, got %s", phase.Name)
+	}
+	if len(encounter.ActiveTargets) != 1 || encounter.PrimaryTarget() != boss || fa.CurrentTarget != &boss.Unit {
+		t.Fatalf("Expected the raid to switch back to the boss once the adds despawn")
+	}
+	if boss.PseudoStats.DamageTakenMultiplier != 1.5 {
+		t.Fatalf("Expected the boss to take 50%% more damage in the burn phase, got %0.2f", boss.PseudoStats.DamageTakenMultiplier)
+	}
+
+	runPendingActionsUntil(sim, time.Second*100)
+	encounter.doneIterationPhases(sim)
+	adds := encounter.Phases[1].GetMetricsProto(1)
+	if adds.AvgStartTime != 60 || adds.AvgDuration != 10 || adds.RaidDps != 95_000 {
+		t.Fatalf("Expected the adds phase to last 10s at 95000 DPS, got %v", adds)
+	}
+	burn := encounter.Phases[2].GetMetricsProto(1)
+	if burn.ChanceReached != 1 || burn.AvgStartTime != 70 || burn.AvgDuration != 30 {
+		t.Fatalf("Expected the burn phase to last from 70s to 100s, got %v", burn)
+	}
+}
+
+func TestAOEDamageMaxHitsSkipsInactiveTargets(t *testing.T) {
+	request := fakeSimRequest()
+	request.Encounter.Targets = []*proto.Target{
+		{Name: "Boss", Level: 83},
+		{Name: "Add", Level: 83},
+		{Name: "Other Add", Level: 83},
+	}
+	request.Encounter.Phases = []*proto.EncounterPhase{
+		{Name: "Adds", DeactivateTargets: []int32{0}},
+	}
+	sim := NewSim(*request)
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	spell := fa.RegisterSpell(SpellConfig{
+		ActionID:         ActionID{SpellID: 53385},
+		SpellSchool:      SpellSchoolPhysical,
+		ProcMask:         ProcMaskMeleeMHSpecial,
+		DamageMultiplier: 1,
+		ApplyEffects: ApplyEffectFuncAOEDamageMaxHits(sim.Environment, SpellEffect{
+			BaseDamage:     BaseDamageConfigFlat(1000),
+			OutcomeApplier: fa.OutcomeFuncAlwaysHit(),
+		}, 2),
+	})
+	spell.finalize()
+	sim.Reset()
+	// The fake player has no rotation.
+	fa.gcdAction.Cancel(sim)
+
+	spell.Cast(sim, fa.CurrentTarget)
+	for i, expected := range []int32{0, 1, 1} {
+		if hits := spell.SpellMetrics[i].Hits; hits != expected {
+			t.Fatalf("Expected %d hits on target %d, got %d", expected, i, hits)
+		}
+	}
+}
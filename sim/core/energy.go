@@ -73,6 +73,9 @@ func (eb *energyBar) addEnergyInternal(sim *Simulation, amount float64, metrics
 	if sim.Log != nil {
 		eb.unit.Log(sim, "Gained %0.3f energy from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, eb.currentEnergy, newEnergy)
 	}
+	if sim.EventLog != nil {
+		eb.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, proto.ResourceType_ResourceTypeEnergy, metrics.ActionID, amount, eb.currentEnergy, newEnergy)
+	}
 
 	eb.currentEnergy = newEnergy
 }
@@ -92,6 +95,9 @@ func (eb *energyBar) SpendEnergy(sim *Simulation, amount float64, metrics *Resou
 	if sim.Log != nil {
 		eb.unit.Log(sim, "Spent %0.3f energy from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, eb.currentEnergy, newEnergy)
 	}
+	if sim.EventLog != nil {
+		eb.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceSpent, proto.ResourceType_ResourceTypeEnergy, metrics.ActionID, amount, eb.currentEnergy, newEnergy)
+	}
 
 	eb.currentEnergy = newEnergy
 }
@@ -118,6 +124,9 @@ func (eb *energyBar) AddComboPoints(sim *Simulation, pointsToAdd int32, metrics
 	if sim.Log != nil {
 		eb.unit.Log(sim, "Gained %d combo points from %s (%d --> %d)", pointsToAdd, metrics.ActionID, eb.comboPoints, newComboPoints)
 	}
+	if sim.EventLog != nil {
+		eb.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, proto.ResourceType_ResourceTypeComboPoints, metrics.ActionID, float64(pointsToAdd), float64(eb.comboPoints), float64(newComboPoints))
+	}
 
 	eb.comboPoints = newComboPoints
 }
@@ -126,6 +135,9 @@ func (eb *energyBar) SpendComboPoints(sim *Simulation, metrics *ResourceMetrics)
 	if sim.Log != nil {
 		eb.unit.Log(sim, "Spent %d combo points from %s (%d --> %d).", eb.comboPoints, metrics.ActionID, eb.comboPoints, 0)
 	}
+	if sim.EventLog != nil {
+		eb.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceSpent, proto.ResourceType_ResourceTypeComboPoints, metrics.ActionID, float64(eb.comboPoints), float64(eb.comboPoints), 0)
+	}
 	metrics.AddEvent(float64(-eb.comboPoints), float64(-eb.comboPoints))
 	eb.comboPoints = 0
 }
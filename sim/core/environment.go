@@ -64,23 +64,29 @@ func (env *Environment) construct(raidProto proto.Raid, encounterProto proto.Enc
 		unit.CurrentTarget = &env.Encounter.Targets[0].Unit
 	}
 
-	// Apply extra debuffs from raid.
+	env.setupMovement(env.Encounter.MovementEvents)
+
+	// Apply extra debuffs from raid. Debuffs with a timeline on the target
+	// follow that instead.
 	if raidProto.Debuffs != nil && len(env.Encounter.Targets) > 0 {
-		applyDebuffEffects(&env.Encounter.Targets[0].Unit, *raidProto.Debuffs)
+		var timeline []*proto.DebuffEvent
+		if len(encounterProto.Targets) > 0 {
+			timeline = encounterProto.Targets[0].DebuffTimeline
+		}
+		applyDebuffEffects(&env.Encounter.Targets[0].Unit, *withoutTimelineDebuffs(raidProto.Debuffs, timeline))
+	}
+	for _, target := range env.Encounter.Targets {
+		if target.Index < int32(len(encounterProto.Targets)) {
+			target.setupDebuffTimeline(encounterProto.Targets[target.Index].DebuffTimeline, raidProto.Debuffs)
+		}
 	}
 
 	// Assign target or target using Tanks field.
 	for _, target := range env.Encounter.Targets {
 		if target.Index < int32(len(encounterProto.Targets)) {
 			targetProto := encounterProto.Targets[target.Index]
-			if targetProto.TankIndex >= 0 && targetProto.TankIndex < int32(len(raidProto.Tanks)) {
-				raidTargetProto := raidProto.Tanks[targetProto.TankIndex]
-				if raidTargetProto != nil {
-					raidTarget := env.Raid.GetPlayerFromRaidTarget(*raidTargetProto)
-					if raidTarget != nil {
-						target.CurrentTarget = &raidTarget.GetCharacter().Unit
-					}
-				}
+			if tank := env.getTank(raidProto.Tanks, targetProto.TankIndex); tank != nil {
+				target.CurrentTarget = tank
 			}
 		}
 	}
@@ -88,15 +94,32 @@ func (env *Environment) construct(raidProto proto.Raid, encounterProto proto.Enc
 	env.State = Constructed
 }
 
+// Returns the player at an index in Raid.tanks, or nil if there isn't one.
+func (env *Environment) getTank(tanks []*proto.RaidTarget, tankIndex int32) *Unit {
+	if tankIndex < 0 || tankIndex >= int32(len(tanks)) || tanks[tankIndex] == nil {
+		return nil
+	}
+	tank := env.Raid.GetPlayerFromRaidTarget(*tanks[tankIndex])
+	if tank == nil {
+		return nil
+	}
+	return &tank.GetCharacter().Unit
+}
+
 // The initialization phase.
 func (env *Environment) initialize(raidProto proto.Raid, encounterProto proto.Encounter) *proto.RaidStats {
 	for _, target := range env.Encounter.Targets {
 		if target.Index < int32(len(encounterProto.Targets)) {
-			target.initialize(encounterProto.Targets[target.Index])
+			targetProto := encounterProto.Targets[target.Index]
+			target.initialize(targetProto)
+			for _, swing := range targetProto.SecondarySwings {
+				target.registerSecondarySwing(swing, env.getTank(raidProto.Tanks, swing.TankIndex))
+			}
 		} else {
 			target.initialize(nil)
 		}
 	}
+	env.setupRaidDamage(encounterProto.RaidDamage)
 
 	for _, party := range env.Raid.Parties {
 		for _, playerOrPet := range party.PlayersAndPets {
@@ -163,6 +186,10 @@ func (env *Environment) setupAttackTables() {
 			defender.DefenseTables[i] = attackTable
 		}
 	}
+
+	for _, target := range env.Encounter.Targets {
+		target.applyAttackTableOverride()
+	}
 }
 
 func (env *Environment) IsFinalized() bool {
@@ -178,6 +205,12 @@ func (env *Environment) GetNumTargets() int32 {
 	return int32(len(env.Encounter.Targets))
 }
 
+// Number of targets which can currently be attacked. Unlike GetNumTargets, this
+// can change during an iteration if the encounter has phases.
+func (env *Environment) GetNumActiveTargets() int32 {
+	return int32(len(env.Encounter.ActiveTargets))
+}
+
 func (env *Environment) GetTarget(index int32) *Target {
 	return env.Encounter.Targets[index]
 }
@@ -188,6 +188,7 @@ const (
 	SpellFlagNoOnCastComplete                               // Disables OnCastComplete callbacks.
 	SpellFlagNoMetrics                                      // Disables metrics for a spell.
 	SpellFlagNoLogs                                         // Disables logs for a spell.
+	SpellFlagInterruptible                                  // Hardcasts of this spell can be interrupted, see Unit.InterruptCast.
 
 	// Used to let agents categorize their spells.
 	SpellFlagAgentReserved1
@@ -116,6 +116,7 @@ func (unit *Unit) HardcastWaitUntil(sim *Simulation, readyTime time.Duration, on
 
 	unit.Hardcast.Expires = readyTime
 	unit.Hardcast.OnComplete = onComplete
+	unit.Hardcast.Spell = nil
 	unit.newHardcastAction(sim)
 }
 
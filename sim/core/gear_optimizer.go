@@ -0,0 +1,762 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/items"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const defaultGearOptimizerSims = 10
+const maxGearOptimizerSims = 200
+
+// Number of partial sets kept after each slot of the search.
+const gearOptimizerBeamWidth = 250
+
+// Jewelcrafting gems are unique-equipped up to this many.
+const maxJewelcraftingGems = 3
+
+// An item which can go in a slot, with its gems and enchant chosen.
+type gearOption struct {
+	item items.Item
+
+	// Gems set by the request, which are never changed.
+	fixedGems []bool
+
+	score float64
+	key   string
+}
+
+type gearSearchState struct {
+	options [items.ItemSlotRanged + 1]*gearOption
+	score   float64
+}
+
+type gearOptimizer struct {
+	class       proto.Class
+	professions []proto.Profession
+	titansGrip  bool
+	weights     stats.Stats
+
+	gems        []items.Gem // Non-meta gems which can be used any number of times.
+	limitedGems []items.Gem // Unique and profession gems.
+	metaGems    []items.Gem
+	enchants    []items.Enchant
+}
+
+// Number of sims a gear optimizer request runs: one per candidate, plus the
+// stat weights presim if no weights were given.
+func GearOptimizerSims(request *proto.GearOptimizerRequest) int {
+	numSims := int(request.NumSims)
+	if numSims == 0 {
+		numSims = defaultGearOptimizerSims
+	}
+	return numSims + statWeightsPresimSims(request.StatWeights, request.StatsToWeigh)
+}
+
+// Number of sims gearStatWeights runs to compute stat weights.
+func statWeightsPresimSims(statWeights []float64, statsToWeigh []proto.Stat) int {
+	if len(statWeights) > 0 {
+		return 0
+	}
+	return len(statsToWeigh) + 1
+}
+
+// Searches the candidate items of each slot for the best gear sets, scoring
+// them with stat weights and then simming the best ones.
+func OptimizeGear(ctx context.Context, request *proto.GearOptimizerRequest, progress chan *proto.ProgressMetrics) *proto.GearOptimizerResult {
+	numSims := int(request.NumSims)
+	if numSims == 0 {
+		numSims = defaultGearOptimizerSims
+	}
+	if numSims < 0 || numSims > maxGearOptimizerSims {
+		return &proto.GearOptimizerResult{ErrorResult: fmt.Sprintf("Number of sims must be between 1 and %d", maxGearOptimizerSims)}
+	}
+
+	player := googleProto.Clone(request.Player).(*proto.Player)
+	if player.BonusStats == nil {
+		player.BonusStats = make([]float64, stats.Len)
+	}
+
+	weights, errorResult := gearStatWeights(ctx, request.StatWeights, proto.StatWeightsRequest{
+		Player:       player,
+		RaidBuffs:    request.RaidBuffs,
+		PartyBuffs:   request.PartyBuffs,
+		Debuffs:      request.Debuffs,
+		Encounter:    request.Encounter,
+		SimOptions:   request.SimOptions,
+		Tanks:        request.Tanks,
+		StatsToWeigh: request.StatsToWeigh,
+	}, progress)
+	if errorResult != "" {
+		return &proto.GearOptimizerResult{ErrorResult: errorResult}
+	}
+
+	optimizer, err := newGearOptimizer(player, weights, request.Gems, request.Enchants)
+	if err != nil {
+		return &proto.GearOptimizerResult{ErrorResult: err.Error()}
+	}
+	options, err := optimizer.slotOptions(request.Slots, items.ProtoToEquipment(*player.Equipment))
+	if err != nil {
+		return &proto.GearOptimizerResult{ErrorResult: err.Error()}
+	}
+
+	candidates := optimizer.search(options, numSims)
+	if len(candidates) == 0 {
+		return &proto.GearOptimizerResult{ErrorResult: "No gear set satisfies the constraints"}
+	}
+
+	baseSimRequest := &proto.RaidSimRequest{
+		Raid:       SinglePlayerRaidProto(player, request.PartyBuffs, request.RaidBuffs, request.Debuffs),
+		Encounter:  request.Encounter,
+		SimOptions: request.SimOptions,
+	}
+	baseSimRequest.Raid.Tanks = request.Tanks
+	baseSimRequest = googleProto.Clone(baseSimRequest).(*proto.RaidSimRequest)
+	simOptions := baseSimRequest.SimOptions
+	if simOptions.RandomSeed == 0 {
+		simOptions.RandomSeed = time.Now().UnixNano()
+	}
+
+	var mutex sync.Mutex
+	simsCompleted := 0
+
+	runCandidate := func(candidate *proto.GearOptimizerCandidate) {
+		simRequest := googleProto.Clone(baseSimRequest).(*proto.RaidSimRequest)
+		simRequest.Raid.Parties[0].Players[0].Equipment = candidate.Equipment
+
+		result := RunSimWithContext(ctx, *simRequest, nil)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if result.ErrorResult != "" {
+			if errorResult == "" {
+				errorResult = result.ErrorResult
+			}
+			return
+		}
+		dps := result.RaidMetrics.Parties[0].Players[0].Dps
+		candidate.Dps = dps.Avg
+		candidate.DpsStdev = dps.Stdev
+		candidate.DpsError = dps.Stdev / math.Sqrt(float64(simOptions.Iterations))
+
+		simsCompleted++
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				TotalIterations:     int32(len(candidates)) * simOptions.Iterations,
+				CompletedIterations: int32(simsCompleted) * simOptions.Iterations,
+				CompletedSims:       int32(simsCompleted),
+				TotalSims:           int32(len(candidates)),
+			}
+		}
+	}
+
+	candidatesToRun := make(chan *proto.GearOptimizerCandidate, len(candidates))
+	for _, candidate := range candidates {
+		candidatesToRun <- candidate
+	}
+	close(candidatesToRun)
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < MinInt(runtime.NumCPU(), len(candidates)); i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for candidate := range candidatesToRun {
+				if ctx.Err() != nil {
+					return
+				}
+				runCandidate(candidate)
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	if ctx.Err() != nil {
+		return &proto.GearOptimizerResult{ErrorResult: "Gear optimizer cancelled: " + ctx.Err().Error()}
+	}
+	if errorResult != "" {
+		return &proto.GearOptimizerResult{ErrorResult: errorResult}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Dps > candidates[j].Dps
+	})
+	return &proto.GearOptimizerResult{
+		Candidates:  candidates,
+		StatWeights: weights[:],
+	}
+}
+
+// Returns the given stat weights, or computes them for the request's
+// stats_to_weigh with a stat weights sim.
+func gearStatWeights(ctx context.Context, statWeights []float64, swr proto.StatWeightsRequest, progress chan *proto.ProgressMetrics) (stats.Stats, string) {
+	weights := stats.Stats{}
+	if len(statWeights) > 0 {
+		copy(weights[:], statWeights)
+		return weights, ""
+	}
+	if len(swr.StatsToWeigh) == 0 {
+		return weights, "Stat weights or stats to weigh are required"
+	}
+
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{PresimRunning: true}
+	}
+	swr.Player = googleProto.Clone(swr.Player).(*proto.Player)
+	result := CalcStatWeight(ctx, swr, stats.ProtoArrayToStatsList(swr.StatsToWeigh), stats.Stat(swr.StatsToWeigh[0]), nil)
+	if result.ErrorResult != "" {
+		return weights, result.ErrorResult
+	}
+	return result.Dps.Weights, ""
+}
+
+func newGearOptimizer(player *proto.Player, weights stats.Stats, gemIDs []int32, enchantIDs []int32) (*gearOptimizer, error) {
+	optimizer := &gearOptimizer{
+		class:       player.Class,
+		professions: []proto.Profession{player.Profession1, player.Profession2},
+		titansGrip:  player.GetWarrior().GetTalents().GetTitansGrip(),
+		weights:     weights,
+	}
+
+	for _, gemID := range gemIDs {
+		gem, ok := items.GemsByID[gemID]
+		if !ok {
+			return nil, fmt.Errorf("No gem with id: %d", gemID)
+		}
+		if !optimizer.hasProfession(gem.RequiredProfession) {
+			continue
+		}
+		if gem.Color == proto.GemColor_GemColorMeta {
+			optimizer.metaGems = append(optimizer.metaGems, gem)
+		} else if gem.Unique || gem.RequiredProfession != proto.Profession_ProfessionUnknown {
+			optimizer.limitedGems = append(optimizer.limitedGems, gem)
+		} else {
+			optimizer.gems = append(optimizer.gems, gem)
+		}
+	}
+
+	allowedEnchants := make(map[int32]bool, len(enchantIDs))
+	for _, enchantID := range enchantIDs {
+		allowedEnchants[enchantID] = true
+	}
+	foundEnchants := make(map[int32]bool, len(enchantIDs))
+	for _, enchant := range items.Enchants {
+		if allowedEnchants[enchant.ID] {
+			optimizer.enchants = append(optimizer.enchants, enchant)
+			foundEnchants[enchant.ID] = true
+		}
+	}
+	for _, enchantID := range enchantIDs {
+		if !foundEnchants[enchantID] {
+			return nil, fmt.Errorf("No enchant with id: %d", enchantID)
+		}
+	}
+
+	return optimizer, nil
+}
+
+func (optimizer *gearOptimizer) hasProfession(profession proto.Profession) bool {
+	if profession == proto.Profession_ProfessionUnknown {
+		return true
+	}
+	for _, p := range optimizer.professions {
+		if p == profession {
+			return true
+		}
+	}
+	return false
+}
+
+func (optimizer *gearOptimizer) score(s stats.Stats) float64 {
+	total := 0.0
+	for _, value := range s.DotProduct(optimizer.weights) {
+		total += value
+	}
+	return total
+}
+
+// Highest scoring gem which matches the socket color. Prismatic sockets match
+// all non-meta gems.
+func (optimizer *gearOptimizer) bestGem(socketColor proto.GemColor) items.Gem {
+	gems := optimizer.gems
+	if socketColor == proto.GemColor_GemColorMeta {
+		gems = optimizer.metaGems
+	}
+
+	var best items.Gem
+	bestScore := math.Inf(-1)
+	for _, gem := range gems {
+		if !items.ColorIntersects(socketColor, gem.Color) {
+			continue
+		}
+		if score := optimizer.score(gem.Stats); score > bestScore {
+			best, bestScore = gem, score
+		}
+	}
+	return best
+}
+
+func (optimizer *gearOptimizer) canUseEnchant(enchant items.Enchant, item items.Item) bool {
+	if enchant.ItemType != item.Type || !optimizer.hasProfession(enchant.RequiredProfession) {
+		return false
+	}
+	if len(enchant.ClassAllowlist) > 0 {
+		allowed := false
+		for _, class := range enchant.ClassAllowlist {
+			allowed = allowed || class == optimizer.class
+		}
+		if !allowed {
+			return false
+		}
+	}
+	if enchant.EnchantType == proto.EnchantType_EnchantTypeTwoHand && item.HandType != proto.HandType_HandTypeTwoHand {
+		return false
+	}
+	if (enchant.EnchantType == proto.EnchantType_EnchantTypeShield) != (item.WeaponType == proto.WeaponType_WeaponTypeShield) {
+		return false
+	}
+	if item.WeaponType == proto.WeaponType_WeaponTypeOffHand {
+		return false
+	}
+	if item.Type == proto.ItemType_ItemTypeRanged {
+		switch item.RangedWeaponType {
+		case proto.RangedWeaponType_RangedWeaponTypeBow, proto.RangedWeaponType_RangedWeaponTypeCrossbow, proto.RangedWeaponType_RangedWeaponTypeGun:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (optimizer *gearOptimizer) bestEnchant(item items.Item) items.Enchant {
+	var best items.Enchant
+	bestScore := math.Inf(-1)
+	for _, enchant := range optimizer.enchants {
+		if !optimizer.canUseEnchant(enchant, item) {
+			continue
+		}
+		if score := optimizer.score(enchant.Bonus); score > bestScore {
+			best, bestScore = enchant, score
+		}
+	}
+	return best
+}
+
+// Socket colors of an item, including the prismatic socket from a belt buckle
+// or blacksmithing.
+func (optimizer *gearOptimizer) socketColors(item items.Item) []proto.GemColor {
+	sockets := append([]proto.GemColor{}, item.GemSockets...)
+	if item.Type == proto.ItemType_ItemTypeWaist ||
+		(optimizer.hasProfession(proto.Profession_Blacksmithing) && (item.Type == proto.ItemType_ItemTypeWrist || item.Type == proto.ItemType_ItemTypeHands)) {
+		sockets = append(sockets, proto.GemColor_GemColorPrismatic)
+	}
+	return sockets
+}
+
+// Fills the empty sockets and enchant of an item with the highest scoring
+// choices. Gems either all match their sockets for the socket bonus, or are
+// the best gems regardless of color, whichever scores higher.
+func (optimizer *gearOptimizer) fillItem(item items.Item) *gearOption {
+	sockets := optimizer.socketColors(item)
+	gems := make([]items.Gem, MaxInt(len(sockets), len(item.Gems)))
+	copy(gems, item.Gems)
+	fixedGems := make([]bool, len(gems))
+	for i, gem := range gems {
+		fixedGems[i] = gem.ID != 0
+	}
+
+	if len(gems) > 0 {
+		matched := append([]items.Gem{}, gems...)
+		unmatched := append([]items.Gem{}, gems...)
+		for i, socketColor := range sockets {
+			if fixedGems[i] {
+				continue
+			}
+			matched[i] = optimizer.bestGem(socketColor)
+			if socketColor == proto.GemColor_GemColorMeta {
+				unmatched[i] = matched[i]
+			} else {
+				unmatched[i] = optimizer.bestGem(proto.GemColor_GemColorPrismatic)
+			}
+		}
+
+		item.Gems = matched
+		matchedScore := optimizer.score(item.TotalStats())
+		item.Gems = unmatched
+		if optimizer.score(item.TotalStats()) < matchedScore {
+			item.Gems = matched
+		}
+	}
+
+	if item.Enchant.ID == 0 {
+		item.Enchant = optimizer.bestEnchant(item)
+	}
+
+	return newGearOption(item, fixedGems, optimizer.score(item.TotalStats()))
+}
+
+func newGearOption(item items.Item, fixedGems []bool, score float64) *gearOption {
+	return &gearOption{
+		item:      item,
+		fixedGems: fixedGems,
+		score:     score,
+		key:       gearOptimizerItemKey(item),
+	}
+}
+
+func gearOptimizerItemKey(item items.Item) string {
+	gemIDs := make([]int32, len(item.Gems))
+	for i, gem := range item.Gems {
+		gemIDs[i] = gem.ID
+	}
+	return fmt.Sprintf("%d/%d/%v", item.ID, item.Enchant.ID, gemIDs)
+}
+
+// Equipment slots an item type can go in, besides the one given by ItemTypeToSlot.
+var gearOptimizerPairedSlots = map[items.ItemSlot]items.ItemSlot{
+	items.ItemSlotFinger2:  items.ItemSlotFinger1,
+	items.ItemSlotTrinket2: items.ItemSlotTrinket1,
+	items.ItemSlotOffHand:  items.ItemSlotMainHand,
+}
+
+// Builds the options for each slot from the request's candidates, or the
+// equipped item for slots without any.
+func (optimizer *gearOptimizer) slotOptions(slots []*proto.GearOptimizerSlot, equipment items.Equipment) ([items.ItemSlotRanged + 1][]*gearOption, error) {
+	var options [items.ItemSlotRanged + 1][]*gearOption
+	seen := make(map[string]bool)
+
+	for _, slotCandidates := range slots {
+		slot := items.ItemSlot(slotCandidates.Slot)
+		if slot > items.ItemSlotRanged {
+			return options, fmt.Errorf("Invalid item slot: %d", slotCandidates.Slot)
+		}
+		for _, spec := range slotCandidates.Items {
+			item, err := newGearOptimizerItem(spec)
+			if err != nil {
+				return options, err
+			}
+			typeSlot := items.ItemTypeToSlot(item.Type)
+			if typeSlot != slot && gearOptimizerPairedSlots[slot] != typeSlot {
+				return options, fmt.Errorf("%s can't be equipped in %s", item.Name, proto.ItemSlot(slot))
+			}
+			if !optimizer.hasProfession(item.RequiredProfession) {
+				continue
+			}
+
+			option := optimizer.fillItem(item)
+			if key := fmt.Sprintf("%d/%s", slot, option.key); !seen[key] {
+				seen[key] = true
+				options[slot] = append(options[slot], option)
+			}
+		}
+		if len(options[slot]) == 0 {
+			return options, fmt.Errorf("No usable candidates for %s", proto.ItemSlot(slot))
+		}
+	}
+
+	for slot := range options {
+		if len(options[slot]) > 0 {
+			continue
+		}
+		item := equipment[slot]
+		fixedGems := make([]bool, len(item.Gems))
+		for i := range fixedGems {
+			fixedGems[i] = true
+		}
+		options[slot] = []*gearOption{newGearOption(item, fixedGems, optimizer.score(item.TotalStats()))}
+	}
+
+	// Two-handers can only be used with an empty off hand.
+	if !optimizer.titansGrip {
+		for _, option := range options[items.ItemSlotMainHand] {
+			if option.item.HandType == proto.HandType_HandTypeTwoHand {
+				options[items.ItemSlotOffHand] = append(options[items.ItemSlotOffHand], newGearOption(items.Item{}, nil, 0))
+				break
+			}
+		}
+	}
+
+	return options, nil
+}
+
+func newGearOptimizerItem(spec *proto.ItemSpec) (items.Item, error) {
+	item, ok := items.ByID[spec.Id]
+	if !ok {
+		return item, fmt.Errorf("No item with id: %d", spec.Id)
+	}
+	if _, ok := items.EnchantsByItemByID[item.Type][spec.Enchant]; spec.Enchant != 0 && !ok {
+		return item, fmt.Errorf("No enchant with id %d for %s", spec.Enchant, item.Name)
+	}
+	for _, gemID := range spec.Gems {
+		if _, ok := items.GemsByID[gemID]; gemID != 0 && !ok {
+			return item, fmt.Errorf("No gem with id: %d", gemID)
+		}
+	}
+	return items.NewItem(items.ItemSpec{ID: spec.Id, Enchant: spec.Enchant, Gems: spec.Gems}), nil
+}
+
+// Beam search over the slot options, keeping the highest scoring partial sets
+// after each slot. The best sets are then regemmed for the meta gem and
+// limited gems, and the numSets highest scoring are returned.
+func (optimizer *gearOptimizer) search(options [items.ItemSlotRanged + 1][]*gearOption, numSets int) []*proto.GearOptimizerCandidate {
+	states := []gearSearchState{{}}
+	for slot := range options {
+		var nextStates []gearSearchState
+		seen := make(map[string]bool)
+		for _, state := range states {
+			for _, option := range options[slot] {
+				if !optimizer.canAdd(&state, items.ItemSlot(slot), option) {
+					continue
+				}
+				nextState := state
+				nextState.options[slot] = option
+				nextState.score += option.score
+
+				// Swapping two rings or trinkets gives the same set.
+				if slot == int(items.ItemSlotFinger2) || slot == int(items.ItemSlotTrinket2) {
+					key := nextState.key(items.ItemSlot(slot))
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+				}
+				nextStates = append(nextStates, nextState)
+			}
+		}
+
+		sort.SliceStable(nextStates, func(i, j int) bool {
+			return nextStates[i].score > nextStates[j].score
+		})
+		if len(nextStates) > gearOptimizerBeamWidth {
+			nextStates = nextStates[:gearOptimizerBeamWidth]
+		}
+		states = nextStates
+	}
+
+	var candidates []*proto.GearOptimizerCandidate
+	seen := make(map[string]bool)
+	for _, state := range states {
+		equipment := items.Equipment{}
+		var fixedGems [items.ItemSlotRanged + 1][]bool
+		for slot, option := range state.options {
+			equipment[slot] = option.item
+			equipment[slot].Gems = append([]items.Gem{}, option.item.Gems...)
+			fixedGems[slot] = option.fixedGems
+		}
+		optimizer.regem(&equipment, fixedGems)
+
+		keys := make([]string, len(equipment))
+		for slot, item := range equipment {
+			keys[slot] = gearOptimizerItemKey(item)
+		}
+		if key := strings.Join(keys, ","); seen[key] {
+			continue
+		} else {
+			seen[key] = true
+		}
+		candidates = append(candidates, &proto.GearOptimizerCandidate{
+			Equipment: equipment.ToEquipmentSpecProto(),
+			Score:     optimizer.score(equipment.Stats()),
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > numSets {
+		candidates = candidates[:numSets]
+	}
+	return candidates
+}
+
+func (state *gearSearchState) key(lastSlot items.ItemSlot) string {
+	keys := make([]string, 0, lastSlot+1)
+	for slot := items.ItemSlot(0); slot <= lastSlot; slot++ {
+		keys = append(keys, state.options[slot].key)
+	}
+	for _, pair := range [][2]items.ItemSlot{{items.ItemSlotFinger1, items.ItemSlotFinger2}, {items.ItemSlotTrinket1, items.ItemSlotTrinket2}} {
+		if pair[1] <= lastSlot && keys[pair[0]] > keys[pair[1]] {
+			keys[pair[0]], keys[pair[1]] = keys[pair[1]], keys[pair[0]]
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// Whether an option can be added to a partial set, checking two-handers,
+// unique items and limited gems.
+func (optimizer *gearOptimizer) canAdd(state *gearSearchState, slot items.ItemSlot, option *gearOption) bool {
+	if slot == items.ItemSlotOffHand && !optimizer.titansGrip &&
+		state.options[items.ItemSlotMainHand].item.HandType == proto.HandType_HandTypeTwoHand {
+		return option.item.ID == 0
+	}
+
+	if option.item.ID == 0 {
+		return true
+	}
+
+	gemCounts := make(map[int32]int)
+	for _, gem := range option.item.Gems {
+		gemCounts[gem.ID]++
+	}
+	for s := items.ItemSlot(0); s < slot; s++ {
+		item := state.options[s].item
+		if item.ID == option.item.ID && item.Unique {
+			return false
+		}
+		for _, gem := range item.Gems {
+			gemCounts[gem.ID]++
+		}
+	}
+	return gemCountsAllowed(gemCounts)
+}
+
+// Whether unique-equipped gem limits are respected.
+func gemCountsAllowed(gemCounts map[int32]int) bool {
+	numJewelcrafting := 0
+	for gemID, count := range gemCounts {
+		gem := items.GemsByID[gemID]
+		if gem.Unique && count > 1 {
+			return false
+		}
+		if gem.RequiredProfession == proto.Profession_Jewelcrafting {
+			numJewelcrafting += count
+		}
+	}
+	return numJewelcrafting <= maxJewelcraftingGems
+}
+
+// A socket which the optimizer may change.
+type gearSocket struct {
+	slot  items.ItemSlot
+	index int
+}
+
+// Changes gems to activate the meta gem, or removes it if that isn't possible,
+// then swaps in unique and profession gems where they score higher.
+func (optimizer *gearOptimizer) regem(equipment *items.Equipment, fixedGems [items.ItemSlotRanged + 1][]bool) {
+	var sockets []gearSocket
+	for slot, item := range equipment {
+		socketColors := optimizer.socketColors(item)
+		for i := range item.Gems {
+			if !fixedGems[slot][i] && (i >= len(socketColors) || socketColors[i] != proto.GemColor_GemColorMeta) {
+				sockets = append(sockets, gearSocket{slot: items.ItemSlot(slot), index: i})
+			}
+		}
+	}
+
+	if equipment.MetaGem().ID != 0 && !optimizer.activateMetaGem(equipment, sockets) {
+		head := &equipment[items.ItemSlotHead]
+		for i, gem := range head.Gems {
+			if gem.Color == proto.GemColor_GemColorMeta && !fixedGems[items.ItemSlotHead][i] {
+				head.Gems[i] = items.Gem{}
+			}
+		}
+	}
+
+	optimizer.addLimitedGems(equipment, sockets)
+}
+
+// Greedily changes gems until the meta gem is active, each time picking the
+// change with the lowest score lost per missing gem color. Returns whether the
+// meta gem ends up active.
+func (optimizer *gearOptimizer) activateMetaGem(equipment *items.Equipment, sockets []gearSocket) bool {
+	// The best gem of each color is enough to consider.
+	var colorGems []items.Gem
+	for _, color := range []proto.GemColor{
+		proto.GemColor_GemColorRed, proto.GemColor_GemColorYellow, proto.GemColor_GemColorBlue,
+		proto.GemColor_GemColorOrange, proto.GemColor_GemColorGreen, proto.GemColor_GemColorPurple,
+		proto.GemColor_GemColorPrismatic,
+	} {
+		var best items.Gem
+		bestScore := math.Inf(-1)
+		for _, gem := range optimizer.gems {
+			if score := optimizer.score(gem.Stats); gem.Color == color && score > bestScore {
+				best, bestScore = gem, score
+			}
+		}
+		if best.ID != 0 {
+			colorGems = append(colorGems, best)
+		}
+	}
+
+	for deficit := equipment.MetaGemDeficit(); deficit > 0; deficit = equipment.MetaGemDeficit() {
+		var bestSocket gearSocket
+		var bestGem items.Gem
+		bestLoss := math.Inf(1)
+		for _, socket := range sockets {
+			item := &equipment[socket.slot]
+			oldGem := item.Gems[socket.index]
+			oldScore := optimizer.score(item.TotalStats())
+			for _, gem := range colorGems {
+				if gem.ID == oldGem.ID {
+					continue
+				}
+				item.Gems[socket.index] = gem
+				if newDeficit := equipment.MetaGemDeficit(); newDeficit < deficit {
+					loss := (oldScore - optimizer.score(item.TotalStats())) / float64(deficit-newDeficit)
+					if loss < bestLoss {
+						bestSocket, bestGem, bestLoss = socket, gem, loss
+					}
+				}
+			}
+			item.Gems[socket.index] = oldGem
+		}
+
+		if math.IsInf(bestLoss, 1) {
+			return false
+		}
+		equipment[bestSocket.slot].Gems[bestSocket.index] = bestGem
+	}
+	return true
+}
+
+// Greedily swaps in unique and profession gems, as long as they score higher,
+// respect unique-equipped limits and keep the meta gem active.
+func (optimizer *gearOptimizer) addLimitedGems(equipment *items.Equipment, sockets []gearSocket) {
+	metaActive := equipment.HasActiveMetaGem()
+	for {
+		var bestSocket gearSocket
+		var bestGem items.Gem
+		bestGain := 0.0
+		for _, socket := range sockets {
+			item := &equipment[socket.slot]
+			oldGem := item.Gems[socket.index]
+			oldScore := optimizer.score(item.TotalStats())
+			for _, gem := range optimizer.limitedGems {
+				if gem.ID == oldGem.ID {
+					continue
+				}
+				item.Gems[socket.index] = gem
+				gain := optimizer.score(item.TotalStats()) - oldScore
+				if gain > bestGain && gemCountsAllowed(equipmentGemCounts(equipment)) && (!metaActive || equipment.HasActiveMetaGem()) {
+					bestSocket, bestGem, bestGain = socket, gem, gain
+				}
+			}
+			item.Gems[socket.index] = oldGem
+		}
+
+		if bestGain == 0 {
+			return
+		}
+		equipment[bestSocket.slot].Gems[bestSocket.index] = bestGem
+	}
+}
+
+func equipmentGemCounts(equipment *items.Equipment) map[int32]int {
+	gemCounts := make(map[int32]int)
+	for _, item := range equipment {
+		for _, gem := range item.Gems {
+			gemCounts[gem.ID]++
+		}
+	}
+	return gemCounts
+}
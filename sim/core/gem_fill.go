@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+
+	"github.com/wowsims/wotlk/sim/core/items"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Number of sims a gem fill request runs, for the stat weights presim.
+func GemFillSims(request *proto.GemFillRequest) int {
+	return statWeightsPresimSims(request.StatWeights, request.StatsToWeigh)
+}
+
+// Replaces the gems and enchants of the player's gear with the highest scoring
+// ones, using the same rules as the gear optimizer.
+func FillGems(ctx context.Context, request *proto.GemFillRequest) *proto.GemFillResult {
+	player := googleProto.Clone(request.Player).(*proto.Player)
+	if player.BonusStats == nil {
+		player.BonusStats = make([]float64, stats.Len)
+	}
+
+	weights, errorResult := gearStatWeights(ctx, request.StatWeights, proto.StatWeightsRequest{
+		Player:       player,
+		RaidBuffs:    request.RaidBuffs,
+		PartyBuffs:   request.PartyBuffs,
+		Debuffs:      request.Debuffs,
+		Encounter:    request.Encounter,
+		SimOptions:   request.SimOptions,
+		Tanks:        request.Tanks,
+		StatsToWeigh: request.StatsToWeigh,
+	}, nil)
+	if errorResult != "" {
+		return &proto.GemFillResult{ErrorResult: errorResult}
+	}
+
+	gemIDs := request.Gems
+	if len(gemIDs) == 0 {
+		for _, gem := range items.Gems {
+			if request.Phase == 0 || int32(gem.Phase) <= request.Phase {
+				gemIDs = append(gemIDs, gem.ID)
+			}
+		}
+	}
+	enchantIDs := request.Enchants
+	if len(enchantIDs) == 0 {
+		for _, enchant := range items.Enchants {
+			if request.Phase == 0 || enchant.Phase <= request.Phase {
+				enchantIDs = append(enchantIDs, enchant.ID)
+			}
+		}
+	}
+
+	optimizer, err := newGearOptimizer(player, weights, gemIDs, enchantIDs)
+	if err != nil {
+		return &proto.GemFillResult{ErrorResult: err.Error()}
+	}
+
+	equipment := items.ProtoToEquipment(*player.Equipment)
+	var fixedGems [items.ItemSlotRanged + 1][]bool
+	for slot, item := range equipment {
+		if item.ID == 0 {
+			continue
+		}
+		item.Gems = nil
+		item.Enchant = items.Enchant{}
+		equipment[slot] = optimizer.fillItem(item).item
+		fixedGems[slot] = make([]bool, len(equipment[slot].Gems))
+	}
+	optimizer.regem(&equipment, fixedGems)
+
+	socketBonuses := make([]bool, len(equipment))
+	for slot, item := range equipment {
+		socketBonuses[slot] = item.HasSocketBonus()
+	}
+
+	return &proto.GemFillResult{
+		Equipment:     equipment.ToEquipmentSpecProto(),
+		SocketBonuses: socketBonuses,
+		MetaGemActive: equipment.HasActiveMetaGem(),
+		Score:         optimizer.score(equipment.Stats()),
+		StatWeights:   weights[:],
+	}
+}
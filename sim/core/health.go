@@ -58,6 +58,9 @@ func (hb *healthBar) GainHealth(sim *Simulation, amount float64, metrics *Resour
 	if sim.Log != nil {
 		hb.unit.Log(sim, "Gained %0.3f health from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, oldHealth, newHealth)
 	}
+	if sim.EventLog != nil {
+		hb.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, proto.ResourceType_ResourceTypeHealth, metrics.ActionID, amount, oldHealth, newHealth)
+	}
 
 	hb.currentHealth = newHealth
 }
@@ -75,6 +78,9 @@ func (hb *healthBar) RemoveHealth(sim *Simulation, amount float64) {
 	if sim.Log != nil {
 		hb.unit.Log(sim, "Spent %0.3f health from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, oldHealth, newHealth)
 	}
+	if sim.EventLog != nil {
+		hb.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceSpent, proto.ResourceType_ResourceTypeHealth, metrics.ActionID, amount, oldHealth, newHealth)
+	}
 
 	hb.currentHealth = newHealth
 }
@@ -92,7 +98,8 @@ func (character *Character) trackChanceOfDeath(healingModel *proto.HealingModel)
 			isTanking = true
 		}
 	}
-	if !isTanking {
+	// Raid damage can hit anyone.
+	if !isTanking && !character.Env.Encounter.hasRaidDamage {
 		return
 	}
 
@@ -150,7 +157,7 @@ func (character *Character) applyHealingModel(healingModel proto.HealingModel) {
 		StartPeriodicAction(sim, PeriodicActionOptions{
 			Period: cadence,
 			OnAction: func(sim *Simulation) {
-				character.GainHealth(sim, healPerTick, healthMetrics)
+				character.GainHealth(sim, healPerTick*character.PseudoStats.HealingTakenMultiplier, healthMetrics)
 
 				if ardentDefenderAura != nil && character.CurrentHealthPercent() >= 0.35 {
 					ardentDefenderAura.Deactivate(sim)
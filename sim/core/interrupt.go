@@ -0,0 +1,94 @@
+package core
+
+import (
+	"time"
+)
+
+// How long it takes players to react to an enemy starting an interruptible
+// cast. Casts shorter than this can't be interrupted.
+const InterruptReactionTime = time.Millisecond * 500
+
+// Whether this unit is in the middle of a hardcast.
+func (unit *Unit) IsCasting(sim *Simulation) bool {
+	return unit.Hardcast.Expires > sim.CurrentTime
+}
+
+// Registers the spell this character uses to interrupt enemy casts, e.g. Kick.
+// Interrupts are used automatically when a target begins an interruptible cast,
+// so rotations don't need to cast them. canUse is optional, for requirements
+// the cast system doesn't check such as stances. config.ApplyEffects is also
+// optional, and runs before the interrupt.
+func (character *Character) RegisterInterruptSpell(config SpellConfig, canUse func(*Simulation) bool) *Spell {
+	applyEffects := config.ApplyEffects
+	config.ApplyEffects = func(sim *Simulation, target *Unit, spell *Spell) {
+		if applyEffects != nil {
+			applyEffects(sim, target, spell)
+		}
+		target.InterruptCast(sim, spell)
+	}
+	character.interruptSpell = character.RegisterSpell(config)
+	character.canInterrupt = canUse
+	return character.interruptSpell
+}
+
+// Interrupts this unit's current cast, if it can be interrupted. The interrupted
+// spell stays on cooldown. Returns whether a cast was interrupted.
+func (unit *Unit) InterruptCast(sim *Simulation, interrupter *Spell) bool {
+	spell := unit.Hardcast.Spell
+	if !unit.IsCasting(sim) || spell == nil || !spell.Flags.Matches(SpellFlagInterruptible) {
+		return false
+	}
+
+	if sim.Log != nil {
+		unit.Log(sim, "Cast %s interrupted by %s %s.", spell.ActionID, interrupter.Unit.Label, interrupter.ActionID)
+	}
+
+	unit.Hardcast = Hardcast{}
+	if unit.hardcastAction != nil {
+		unit.hardcastAction.Cancel(sim)
+	}
+
+	interrupter.Unit.Metrics.Interrupts++
+	interrupter.Unit.Metrics.DamagePrevented += spell.ExpectedDamage
+	return true
+}
+
+// Called when a unit begins an interruptible hardcast. After a reaction delay,
+// the first raid member with a ready interrupt who isn't busy casting uses it.
+func (env *Environment) onInterruptibleCast(sim *Simulation, caster *Unit) {
+	if caster.Type != EnemyUnit {
+		return
+	}
+
+	hardcast := caster.Hardcast
+	interruptAt := sim.CurrentTime + InterruptReactionTime
+	if interruptAt >= hardcast.Expires {
+		return
+	}
+
+	sim.AddPendingAction(&PendingAction{
+		NextActionAt: interruptAt,
+		OnAction: func(sim *Simulation) {
+			// Make sure this is still the same cast.
+			if caster.Hardcast.Spell != hardcast.Spell || caster.Hardcast.Expires != hardcast.Expires {
+				return
+			}
+			for _, unit := range env.Raid.AllUnits {
+				if unit.tryInterrupt(sim, caster) {
+					return
+				}
+			}
+		},
+	})
+}
+
+func (unit *Unit) tryInterrupt(sim *Simulation, caster *Unit) bool {
+	spell := unit.interruptSpell
+	if spell == nil || unit.IsCasting(sim) || !spell.IsReady(sim) {
+		return false
+	}
+	if unit.canInterrupt != nil && !unit.canInterrupt(sim) {
+		return false
+	}
+	return spell.Cast(sim, caster) && !caster.IsCasting(sim)
+}
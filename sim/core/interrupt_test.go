@@ -0,0 +1,97 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Target which only casts when told to, so tests can control its casts.
+type fakeCasterAI struct {
+	target *Target
+}
+
+func (ai *fakeCasterAI) Initialize(target *Target) { ai.target = target }
+func (ai *fakeCasterAI) Reset(sim *Simulation)     {}
+func (ai *fakeCasterAI) DoAction(sim *Simulation)  { ai.target.DoNothing() }
+
+var fakeCasterTarget = proto.Target{Id: 99999, Name: "Fake Caster", Level: 83}
+
+func init() {
+	AddPresetTarget(PresetTarget{
+		PathPrefix: "Test",
+		Config:     fakeCasterTarget,
+		AI: func() TargetAI {
+			return &fakeCasterAI{}
+		},
+	})
+}
+
+func TestInterrupts(t *testing.T) {
+	request := fakeSimRequest()
+	request.Encounter.Targets = []*proto.Target{&fakeCasterTarget}
+	sim := NewSim(*request)
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.GetTargetUnit(0)
+
+	interrupt := fa.RegisterInterruptSpell(SpellConfig{
+		ActionID: ActionID{SpellID: 57994},
+		Cast: CastConfig{
+			CD: Cooldown{
+				Timer:    fa.NewTimer(),
+				Duration: time.Second * 6,
+			},
+		},
+	}, nil)
+	interrupt.finalize()
+
+	numLanded := 0
+	registerEnemySpell := func(spellID int32, castTime time.Duration) *Spell {
+		spell := target.RegisterSpell(SpellConfig{
+			ActionID:       ActionID{SpellID: spellID},
+			Flags:          SpellFlagInterruptible,
+			ExpectedDamage: 4000,
+			Cast: CastConfig{
+				DefaultCast: Cast{
+					CastTime: castTime,
+				},
+			},
+			ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {
+				numLanded++
+			},
+		})
+		spell.finalize()
+		return spell
+	}
+	quickCast := registerEnemySpell(28157, time.Millisecond*400)
+	slowCast := registerEnemySpell(28158, time.Second*2)
+
+	sim.Reset()
+	// The fake player has no rotation.
+	fa.gcdAction.Cancel(sim)
+
+	// Casts shorter than the reaction time can't be interrupted.
+	quickCast.Cast(sim, &fa.Unit)
+	runPendingActionsUntil(sim, time.Second)
+	if numLanded != 1 || fa.Metrics.Interrupts != 0 {
+		t.Fatalf("Expected the quick cast to land, got %d casts landed and %d interrupts", numLanded, fa.Metrics.Interrupts)
+	}
+
+	slowCast.Cast(sim, &fa.Unit)
+	runPendingActionsUntil(sim, time.Second*4)
+	if numLanded != 1 || fa.Metrics.Interrupts != 1 || fa.Metrics.DamagePrevented != 4000 {
+		t.Fatalf("Expected the slow cast to be interrupted, got %d casts landed and %d interrupts preventing %0.0f damage",
+			numLanded, fa.Metrics.Interrupts, fa.Metrics.DamagePrevented)
+	}
+	if target.IsCasting(sim) || interrupt.IsReady(sim) {
+		t.Fatalf("Expected the cast to be cancelled and the interrupt to be on cooldown")
+	}
+
+	// The interrupt is on cooldown, so the next cast lands.
+	slowCast.Cast(sim, &fa.Unit)
+	runPendingActionsUntil(sim, time.Second*7)
+	if numLanded != 2 || fa.Metrics.Interrupts != 1 {
+		t.Fatalf("Expected the cast to land while the interrupt is on cooldown, got %d casts landed and %d interrupts", numLanded, fa.Metrics.Interrupts)
+	}
+}
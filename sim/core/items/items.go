@@ -333,29 +333,35 @@ func (equipment Equipment) Clone() Equipment {
 func (equipment Equipment) Stats() stats.Stats {
 	equipStats := stats.Stats{}
 	for _, item := range equipment {
-		equipStats = equipStats.Add(item.Stats)
-		equipStats = equipStats.Add(item.Enchant.Bonus)
+		equipStats = equipStats.Add(item.TotalStats())
+	}
+	return equipStats
+}
 
-		for _, gem := range item.Gems {
-			equipStats = equipStats.Add(gem.Stats)
-		}
+// Stats from the item, including its enchant, gems and socket bonus.
+func (item Item) TotalStats() stats.Stats {
+	itemStats := item.Stats.Add(item.Enchant.Bonus)
+	for _, gem := range item.Gems {
+		itemStats = itemStats.Add(gem.Stats)
+	}
 
-		// Check socket bonus
-		if len(item.GemSockets) > 0 && len(item.Gems) >= len(item.GemSockets) {
-			allMatch := true
-			for gemIndex, socketColor := range item.GemSockets {
-				if !ColorIntersects(socketColor, item.Gems[gemIndex].Color) {
-					allMatch = false
-					break
-				}
-			}
+	if item.HasSocketBonus() {
+		itemStats = itemStats.Add(item.SocketBonus)
+	}
+	return itemStats
+}
 
-			if allMatch {
-				equipStats = equipStats.Add(item.SocketBonus)
-			}
+// Whether the item has sockets and its gems match all of them.
+func (item Item) HasSocketBonus() bool {
+	if len(item.GemSockets) == 0 || len(item.Gems) < len(item.GemSockets) {
+		return false
+	}
+	for gemIndex, socketColor := range item.GemSockets {
+		if !ColorIntersects(socketColor, item.Gems[gemIndex].Color) {
+			return false
 		}
 	}
-	return equipStats
+	return true
 }
 
 type ItemSlot byte
@@ -0,0 +1,141 @@
+package items
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Requirement for a meta gem to be active, based on the colors of the other
+// gems equipped. Mirrors the conditions in the UI's gems.ts.
+type MetaGemCondition struct {
+	MinRed    int
+	MinYellow int
+	MinBlue   int
+
+	// For conditions like "more red gems than blue gems".
+	MoreOf proto.GemColor
+	LessOf proto.GemColor
+}
+
+var MetaGemConditions = map[int32]MetaGemCondition{
+	41285: {MinBlue: 2},                                                                 // Chaotic Skyflare Diamond
+	41307: {MinRed: 1, MinYellow: 1, MinBlue: 1},                                        // Destructive Skyflare Diamond
+	41333: {MinRed: 3},                                                                  // Ember Skyflare Diamond
+	41335: {MinRed: 2, MinYellow: 1},                                                    // Enigmatic Skyflare Diamond
+	41377: {MinRed: 1, MinBlue: 2},                                                      // Effulgent Skyflare Diamond
+	41339: {MinRed: 1, MinYellow: 2},                                                    // Swift Skyflare Diamond
+	41375: {MinRed: 1, MinYellow: 1, MinBlue: 1},                                        // Tireless Skyflare Diamond
+	41376: {MinRed: 2},                                                                  // Revitalizing Skyflare Diamond
+	41378: {MinYellow: 2, MinBlue: 1},                                                   // Forlorn Skyflare Diamond
+	41379: {MinRed: 2, MinBlue: 1},                                                      // Impassive Skyflare Diamond
+	41380: {MinRed: 1, MinBlue: 2},                                                      // Austere Earthsiege Diamond
+	41381: {MinYellow: 2, MinBlue: 1},                                                   // Persistent Earthsiege Diamond
+	41382: {MinRed: 1, MinYellow: 1, MinBlue: 1},                                        // Trenchant Earthsiege Diamond
+	41385: {MinRed: 1, MinBlue: 2},                                                      // Invigorating Earthsiege Diamond
+	41389: {MinRed: 2, MinYellow: 1},                                                    // Beaming Earthsiege Diamond
+	41395: {MinRed: 2, MinBlue: 1},                                                      // Bracing Earthsiege Diamond
+	41396: {MinRed: 2, MinBlue: 1},                                                      // Eternal Earthsiege Diamond
+	41397: {MinBlue: 3},                                                                 // Powerful Earthsiege Diamond
+	41398: {MinRed: 1, MinYellow: 1, MinBlue: 1},                                        // Relentless Earthsiege Diamond
+	41400: {MinRed: 1, MinYellow: 1, MinBlue: 1},                                        // Thundering Skyflare Diamond
+	41401: {MinRed: 1, MinYellow: 1, MinBlue: 1},                                        // Insightful Earthsiege Diamond
+	44076: {MinRed: 1, MinYellow: 2},                                                    // Swift Starflare Diamond
+	44078: {MinRed: 1, MinYellow: 1, MinBlue: 1},                                        // Tireless Starflare Diamond
+	44081: {MinRed: 2, MinBlue: 1},                                                      // Enigmatic Starflare Diamond
+	44082: {MinRed: 1, MinBlue: 2},                                                      // Impassive Starflare Diamond
+	44084: {MinYellow: 2, MinBlue: 1},                                                   // Forlorn Starflare Diamond
+	44087: {MinBlue: 3},                                                                 // Persistent Earthshatter Diamond
+	44088: {MinYellow: 1, MinBlue: 2},                                                   // Powerful Earthshatter Diamond
+	44089: {MinRed: 1, MinYellow: 1, MinBlue: 1},                                        // Trenchant Earthshatter Diamond
+	25899: {MinRed: 2, MinYellow: 2, MinBlue: 2},                                        // Brutal Earthstorm Diamond
+	34220: {MinBlue: 2},                                                                 // Chaotic Skyfire Diamond
+	25890: {MinRed: 2, MinYellow: 2, MinBlue: 2},                                        // Destructive Skyfire Diamond
+	35503: {MinRed: 3},                                                                  // Ember Skyfire Diamond
+	35501: {MinYellow: 1, MinBlue: 2},                                                   // Eternal Earthstorm Diamond
+	32641: {MinYellow: 3},                                                               // Imbued Unstable Diamond
+	25901: {MinRed: 2, MinYellow: 2, MinBlue: 2},                                        // Insightful Earthstorm Diamond
+	25896: {MinBlue: 3},                                                                 // Powerful Earthstorm Diamond
+	32409: {MinRed: 2, MinYellow: 2, MinBlue: 2},                                        // Relentless Earthstorm Diamond
+	25894: {MinRed: 1, MinYellow: 2},                                                    // Swift Skyfire Diamond
+	28557: {MinRed: 1, MinYellow: 2},                                                    // Swift Starfire Diamond
+	28556: {MinRed: 1, MinYellow: 2},                                                    // Swift Windfire Diamond
+	25898: {MinBlue: 5},                                                                 // Tenacious Earthstorm Diamond
+	32410: {MinRed: 2, MinYellow: 2, MinBlue: 2},                                        // Thundering Skyfire Diamond
+	25897: {MoreOf: proto.GemColor_GemColorRed, LessOf: proto.GemColor_GemColorBlue},    // Bracing Earthstorm Diamond
+	25895: {MoreOf: proto.GemColor_GemColorRed, LessOf: proto.GemColor_GemColorYellow},  // Enigmatic Skyfire Diamond
+	25893: {MoreOf: proto.GemColor_GemColorBlue, LessOf: proto.GemColor_GemColorYellow}, // Mystical Skyfire Diamond
+	32640: {MoreOf: proto.GemColor_GemColorBlue, LessOf: proto.GemColor_GemColorYellow}, // Potent Unstable Diamond
+}
+
+func (condition MetaGemCondition) IsMet(numRed int, numYellow int, numBlue int) bool {
+	return condition.Deficit(numRed, numYellow, numBlue) == 0
+}
+
+// Number of gems which would need to change color for the condition to be met,
+// or 0 if it already is.
+func (condition MetaGemCondition) Deficit(numRed int, numYellow int, numBlue int) int {
+	deficit := 0
+	for _, need := range [][2]int{{condition.MinRed, numRed}, {condition.MinYellow, numYellow}, {condition.MinBlue, numBlue}} {
+		if need[1] < need[0] {
+			deficit += need[0] - need[1]
+		}
+	}
+
+	if condition.MoreOf != proto.GemColor_GemColorUnknown {
+		counts := map[proto.GemColor]int{
+			proto.GemColor_GemColorRed:    numRed,
+			proto.GemColor_GemColorYellow: numYellow,
+			proto.GemColor_GemColorBlue:   numBlue,
+		}
+		if diff := counts[condition.LessOf] - counts[condition.MoreOf] + 1; diff > 0 {
+			deficit += diff
+		}
+	}
+	return deficit
+}
+
+// Returns the meta gem in the head slot, or an empty gem if there is none.
+func (equipment *Equipment) MetaGem() Gem {
+	for _, gem := range equipment[ItemSlotHead].Gems {
+		if gem.Color == proto.GemColor_GemColorMeta {
+			return gem
+		}
+	}
+	return Gem{}
+}
+
+// Counts the equipped non-meta gems which count as red, yellow and blue.
+// Multi-colored gems count for each of their colors.
+func (equipment *Equipment) GemColorCounts() (numRed int, numYellow int, numBlue int) {
+	for _, item := range equipment {
+		for _, gem := range item.Gems {
+			if gem.ID == 0 || gem.Color == proto.GemColor_GemColorMeta {
+				continue
+			}
+			if ColorIntersects(proto.GemColor_GemColorRed, gem.Color) {
+				numRed++
+			}
+			if ColorIntersects(proto.GemColor_GemColorYellow, gem.Color) {
+				numYellow++
+			}
+			if ColorIntersects(proto.GemColor_GemColorBlue, gem.Color) {
+				numBlue++
+			}
+		}
+	}
+	return
+}
+
+// Number of gems which would need to change color to activate the meta gem, or
+// 0 if it's active. Meta gems without a known condition are always active.
+func (equipment *Equipment) MetaGemDeficit() int {
+	condition, ok := MetaGemConditions[equipment.MetaGem().ID]
+	if !ok {
+		return 0
+	}
+	return condition.Deficit(equipment.GemColorCounts())
+}
+
+// Whether a meta gem is equipped and its condition is met.
+func (equipment *Equipment) HasActiveMetaGem() bool {
+	return equipment.MetaGem().ID != 0 && equipment.MetaGemDeficit() == 0
+}
@@ -102,6 +102,9 @@ func (unit *Unit) AddMana(sim *Simulation, amount float64, metrics *ResourceMetr
 	if sim.Log != nil {
 		unit.Log(sim, "Gained %0.3f mana from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, oldMana, newMana)
 	}
+	if sim.EventLog != nil {
+		unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, proto.ResourceType_ResourceTypeMana, metrics.ActionID, amount, oldMana, newMana)
+	}
 
 	unit.currentMana = newMana
 	unit.Metrics.ManaGained += newMana - oldMana
@@ -121,6 +124,9 @@ func (unit *Unit) SpendMana(sim *Simulation, amount float64, metrics *ResourceMe
 	if sim.Log != nil {
 		unit.Log(sim, "Spent %0.3f mana from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, unit.CurrentMana(), newMana)
 	}
+	if sim.EventLog != nil {
+		unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceSpent, proto.ResourceType_ResourceTypeMana, metrics.ActionID, amount, unit.CurrentMana(), newMana)
+	}
 
 	unit.currentMana = newMana
 	unit.Metrics.ManaSpent += amount
@@ -24,6 +24,9 @@ type DistributionMetrics struct {
 	maxSeed    int64
 	minSeed    int64
 	hist       map[int32]int32 // rounded DPS to count
+
+	saveAllValues bool
+	allValues     []float64 // Value of each iteration, if saveAllValues is set.
 }
 
 func (distMetrics *DistributionMetrics) reset() {
@@ -47,6 +50,10 @@ func (distMetrics *DistributionMetrics) doneIteration(seed int64, encounterDurat
 
 	dpsRounded := int32(math.Round(dps/10) * 10)
 	distMetrics.hist[dpsRounded]++
+
+	if distMetrics.saveAllValues {
+		distMetrics.allValues = append(distMetrics.allValues, dps)
+	}
 }
 
 func (distMetrics *DistributionMetrics) ToProto(numIterations int32) *proto.DistributionMetrics {
@@ -60,6 +67,8 @@ func (distMetrics *DistributionMetrics) ToProto(numIterations int32) *proto.Dist
 		MaxSeed: distMetrics.maxSeed,
 		MinSeed: distMetrics.minSeed,
 		Hist:    distMetrics.hist,
+
+		AllValues: distMetrics.allValues,
 	}
 }
 
@@ -70,6 +79,25 @@ func NewDistributionMetrics() DistributionMetrics {
 	}
 }
 
+// Combines the aggregate values from another set of iterations into these
+// metrics, as if they had been run after this set.
+func (distMetrics *DistributionMetrics) merge(other *DistributionMetrics) {
+	distMetrics.sum += other.sum
+	distMetrics.sumSquared += other.sumSquared
+	if other.max > distMetrics.max {
+		distMetrics.max = other.max
+		distMetrics.maxSeed = other.maxSeed
+	}
+	if other.min >= 0 && (other.min <= distMetrics.min || distMetrics.min < 0) {
+		distMetrics.min = other.min
+		distMetrics.minSeed = other.minSeed
+	}
+	for dps, count := range other.hist {
+		distMetrics.hist[dps] += count
+	}
+	distMetrics.allValues = append(distMetrics.allValues, other.allValues...)
+}
+
 type UnitMetrics struct {
 	dps    DistributionMetrics
 	threat DistributionMetrics
@@ -80,10 +108,13 @@ type UnitMetrics struct {
 	CharacterIterationMetrics
 
 	// Aggregate values. These are updated after each iteration.
-	numItersDead int32
-	oomTimeSum   float64
-	actions      map[ActionID]*ActionMetrics
-	resources    []*ResourceMetrics
+	numItersDead       int32
+	oomTimeSum         float64
+	interruptsSum      int32
+	damagePreventedSum float64
+	actions            map[ActionID]*ActionMetrics
+	actionIDs          []ActionID // Keys of actions, in insertion order.
+	resources          []*ResourceMetrics
 }
 
 // Metrics for the current iteration, for 1 agent. Keep this as a separate
@@ -99,6 +130,9 @@ type CharacterIterationMetrics struct {
 	OOMTime time.Duration // time spent not casting and waiting for regen.
 
 	FirstOOMTimestamp time.Duration // Timestamp at which unit first went OOM.
+
+	Interrupts      int32   // Number of enemy casts interrupted by this unit.
+	DamagePrevented float64 // Expected damage of the casts this unit interrupted.
 }
 
 type ActionMetrics struct {
@@ -132,6 +166,7 @@ type TargetedActionMetrics struct {
 	Parries int32
 	Blocks  int32
 	Glances int32
+	Crushes int32
 
 	Damage    float64
 	Threat    float64
@@ -152,6 +187,7 @@ func (tam *TargetedActionMetrics) ToProto() *proto.TargetedActionMetrics {
 		Parries:    tam.Parries,
 		Blocks:     tam.Blocks,
 		Glances:    tam.Glances,
+		Crushes:    tam.Crushes,
 		Damage:     tam.Damage,
 		Threat:     tam.Threat,
 		Healing:    tam.Healing,
@@ -160,6 +196,23 @@ func (tam *TargetedActionMetrics) ToProto() *proto.TargetedActionMetrics {
 	}
 }
 
+func (tam *TargetedActionMetrics) merge(other *TargetedActionMetrics) {
+	tam.Casts += other.Casts
+	tam.Hits += other.Hits
+	tam.Crits += other.Crits
+	tam.Misses += other.Misses
+	tam.Dodges += other.Dodges
+	tam.Parries += other.Parries
+	tam.Blocks += other.Blocks
+	tam.Glances += other.Glances
+	tam.Crushes += other.Crushes
+	tam.Damage += other.Damage
+	tam.Threat += other.Threat
+	tam.Healing += other.Healing
+	tam.Shielding += other.Shielding
+	tam.CastTime += other.CastTime
+}
+
 func NewUnitMetrics() UnitMetrics {
 	return UnitMetrics{
 		dps:     NewDistributionMetrics(),
@@ -262,6 +315,7 @@ func (unitMetrics *UnitMetrics) addSpell(spell *Spell) {
 	if !ok {
 		actionMetrics = &ActionMetrics{IsMelee: spell.Flags.Matches(SpellFlagMeleeMetrics)}
 		unitMetrics.actions[spell.ActionID] = actionMetrics
+		unitMetrics.actionIDs = append(unitMetrics.actionIDs, spell.ActionID)
 	}
 
 	if len(actionMetrics.Targets) == 0 {
@@ -282,6 +336,7 @@ func (unitMetrics *UnitMetrics) addSpell(spell *Spell) {
 		tam.Parries += spellTargetMetrics.Parries
 		tam.Blocks += spellTargetMetrics.Blocks
 		tam.Glances += spellTargetMetrics.Glances
+		tam.Crushes += spellTargetMetrics.Crushes
 		tam.Damage += spellTargetMetrics.TotalDamage
 		tam.Threat += spellTargetMetrics.TotalThreat
 		tam.Healing += spellTargetMetrics.TotalHealing
@@ -318,6 +373,15 @@ func (unitMetrics *UnitMetrics) MarkOOM(sim *Simulation) {
 	}
 }
 
+// Records the value of every iteration, see SimOptions.save_all_values.
+func (unitMetrics *UnitMetrics) saveAllValues() {
+	unitMetrics.dps.saveAllValues = true
+	unitMetrics.threat.saveAllValues = true
+	unitMetrics.dtps.saveAllValues = true
+	unitMetrics.hps.saveAllValues = true
+	unitMetrics.tto.saveAllValues = true
+}
+
 func (unitMetrics *UnitMetrics) reset() {
 	unitMetrics.dps.reset()
 	unitMetrics.threat.reset()
@@ -354,6 +418,8 @@ func (unitMetrics *UnitMetrics) doneIteration(unit *Unit, seed int64, encounterD
 	unitMetrics.tto.doneIteration(seed, encounterDurationSeconds)
 
 	unitMetrics.oomTimeSum += unitMetrics.OOMTime.Seconds()
+	unitMetrics.interruptsSum += unitMetrics.Interrupts
+	unitMetrics.damagePreventedSum += unitMetrics.DamagePrevented
 	if unitMetrics.Died {
 		unitMetrics.numItersDead++
 	}
@@ -368,10 +434,13 @@ func (unitMetrics *UnitMetrics) ToProto(numIterations int32) *proto.UnitMetrics
 		Tto:           unitMetrics.tto.ToProto(numIterations),
 		SecondsOomAvg: unitMetrics.oomTimeSum / float64(numIterations),
 		ChanceOfDeath: float64(unitMetrics.numItersDead) / float64(numIterations),
+
+		InterruptsAvg:      float64(unitMetrics.interruptsSum) / float64(numIterations),
+		DamagePreventedAvg: unitMetrics.damagePreventedSum / float64(numIterations),
 	}
 
-	for actionID, action := range unitMetrics.actions {
-		protoMetrics.Actions = append(protoMetrics.Actions, action.ToProto(actionID))
+	for _, actionID := range unitMetrics.actionIDs {
+		protoMetrics.Actions = append(protoMetrics.Actions, unitMetrics.actions[actionID].ToProto(actionID))
 	}
 	for _, resource := range unitMetrics.resources {
 		if resource.Events > 0 {
@@ -382,6 +451,66 @@ func (unitMetrics *UnitMetrics) ToProto(numIterations int32) *proto.UnitMetrics
 	return protoMetrics
 }
 
+// Combines the aggregate values of the same unit from another Simulation into
+// these metrics.
+func (unitMetrics *UnitMetrics) merge(other *UnitMetrics) {
+	unitMetrics.dps.merge(&other.dps)
+	unitMetrics.threat.merge(&other.threat)
+	unitMetrics.dtps.merge(&other.dtps)
+	unitMetrics.hps.merge(&other.hps)
+	unitMetrics.tto.merge(&other.tto)
+
+	unitMetrics.numItersDead += other.numItersDead
+	unitMetrics.oomTimeSum += other.oomTimeSum
+	unitMetrics.interruptsSum += other.interruptsSum
+	unitMetrics.damagePreventedSum += other.damagePreventedSum
+
+	for _, actionID := range other.actionIDs {
+		otherAction := other.actions[actionID]
+		action, ok := unitMetrics.actions[actionID]
+		if !ok {
+			action = &ActionMetrics{IsMelee: otherAction.IsMelee}
+			unitMetrics.actions[actionID] = action
+			unitMetrics.actionIDs = append(unitMetrics.actionIDs, actionID)
+		}
+		if len(action.Targets) == 0 {
+			action.Targets = make([]TargetedActionMetrics, len(otherAction.Targets))
+			for i := range action.Targets {
+				action.Targets[i].UnitIndex = otherAction.Targets[i].UnitIndex
+			}
+		}
+		for i := range otherAction.Targets {
+			action.Targets[i].merge(&otherAction.Targets[i])
+		}
+	}
+
+	// Resource metrics may be created lazily, so match them by key rather than
+	// by position. The same key can be registered more than once.
+	keyOccurrences := make(map[ResourceKey]int)
+	for _, otherResource := range other.resources {
+		key := ResourceKey{ActionID: otherResource.ActionID, Type: otherResource.Type}
+		occurrence := keyOccurrences[key]
+		keyOccurrences[key]++
+
+		var resource *ResourceMetrics
+		for _, r := range unitMetrics.resources {
+			if r.ActionID == key.ActionID && r.Type == key.Type {
+				if occurrence == 0 {
+					resource = r
+					break
+				}
+				occurrence--
+			}
+		}
+		if resource == nil {
+			resource = unitMetrics.NewResourceMetrics(key.ActionID, key.Type)
+		}
+		resource.Events += otherResource.Events
+		resource.Gain += otherResource.Gain
+		resource.ActualGain += otherResource.ActualGain
+	}
+}
+
 type AuraMetrics struct {
 	ID ActionID
 
@@ -408,6 +537,12 @@ func (auraMetrics *AuraMetrics) doneIteration() {
 	auraMetrics.procsSum += auraMetrics.Procs
 }
 
+func (auraMetrics *AuraMetrics) merge(other *AuraMetrics) {
+	auraMetrics.uptimeSum += other.uptimeSum
+	auraMetrics.uptimeSumSquared += other.uptimeSumSquared
+	auraMetrics.procsSum += other.procsSum
+}
+
 func (auraMetrics *AuraMetrics) ToProto(numIterations int32) *proto.AuraMetrics {
 	uptimeAvg := auraMetrics.uptimeSum.Seconds() / float64(numIterations)
 	procsAvg := float64(auraMetrics.procsSum) / float64(numIterations)
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// A period of time where some players have to move, see proto.MovementEvent.
+type MovementEvent struct {
+	StartTime time.Duration
+	Duration  time.Duration
+	Interval  time.Duration // 0 if the movement happens once.
+	EndTime   time.Duration // 0 if the movement repeats until the end of the fight.
+
+	Roles         []proto.PlayerRole
+	PlayerIndices []int32
+
+	// Players are out of range rather than moving, so can't do anything.
+	OutOfRange bool
+}
+
+func newMovementEvents(options []*proto.MovementEvent) []*MovementEvent {
+	var events []*MovementEvent
+	for _, eventOptions := range options {
+		if eventOptions.Duration <= 0 {
+			continue
+		}
+		events = append(events, &MovementEvent{
+			StartTime:     DurationFromSeconds(eventOptions.StartTime),
+			Duration:      DurationFromSeconds(eventOptions.Duration),
+			Interval:      DurationFromSeconds(eventOptions.Interval),
+			EndTime:       DurationFromSeconds(eventOptions.EndTime),
+			Roles:         eventOptions.Roles,
+			PlayerIndices: eventOptions.PlayerIndices,
+			OutOfRange:    eventOptions.OutOfRange,
+		})
+	}
+	return events
+}
+
+// Whether this event makes the character move.
+func (event *MovementEvent) affects(character *Character) bool {
+	if len(event.Roles) == 0 && len(event.PlayerIndices) == 0 {
+		return true
+	}
+	role := character.Role()
+	for _, eventRole := range event.Roles {
+		if eventRole == role {
+			return true
+		}
+	}
+	for _, index := range event.PlayerIndices {
+		if index == character.Index {
+			return true
+		}
+	}
+	return false
+}
+
+func (event *MovementEvent) hasOccurrence(start time.Duration) bool {
+	return start >= event.StartTime && (event.EndTime == 0 || start <= event.EndTime)
+}
+
+// Returns the end of the occurrence of this event which covers t, or 0 if there isn't one.
+func (event *MovementEvent) activeUntil(t time.Duration) time.Duration {
+	// Find the latest start at or before t.
+	last := t
+	if event.EndTime != 0 && event.EndTime < last {
+		last = event.EndTime
+	}
+	if last < event.StartTime {
+		return 0
+	}
+	start := event.StartTime
+	if event.Interval > 0 {
+		start += (last - event.StartTime) / event.Interval * event.Interval
+	}
+
+	if end := start + event.Duration; end > t {
+		return end
+	}
+	return 0
+}
+
+// Returns the first start of this event at or after t, or NeverExpires.
+func (event *MovementEvent) nextStart(t time.Duration) time.Duration {
+	start := event.StartTime
+	if t > start {
+		if event.Interval == 0 {
+			return NeverExpires
+		}
+		start += (t - event.StartTime + event.Interval - 1) / event.Interval * event.Interval
+	}
+	if !event.hasOccurrence(start) {
+		return NeverExpires
+	}
+	return start
+}
+
+// Returns the broad role of this character, based on their spec.
+func (character *Character) Role() proto.PlayerRole {
+	switch character.Spec {
+	case proto.Spec_SpecFeralTankDruid, proto.Spec_SpecProtectionPaladin,
+		proto.Spec_SpecProtectionWarrior, proto.Spec_SpecTankDeathknight:
+		return proto.PlayerRole_PlayerRoleTank
+	case proto.Spec_SpecEnhancementShaman, proto.Spec_SpecFeralDruid,
+		proto.Spec_SpecRetributionPaladin, proto.Spec_SpecRogue,
+		proto.Spec_SpecWarrior, proto.Spec_SpecDeathknight:
+		return proto.PlayerRole_PlayerRoleMelee
+	case proto.Spec_SpecHealingPriest:
+		return proto.PlayerRole_PlayerRoleHealer
+	default:
+		return proto.PlayerRole_PlayerRoleRanged
+	}
+}
+
+// Returns true if this unit is currently moving.
+func (unit *Unit) IsMoving(sim *Simulation) bool {
+	return unit.MovementEndsAt(sim) > sim.CurrentTime
+}
+
+// Returns when the unit's current movement ends, including any movement which
+// immediately follows it. Returns the current time if the unit isn't moving.
+func (unit *Unit) MovementEndsAt(sim *Simulation) time.Duration {
+	end := sim.CurrentTime
+	for extended := true; extended; {
+		extended = false
+		for _, event := range unit.movementEvents {
+			if eventEnd := event.activeUntil(end); eventEnd > end {
+				end = eventEnd
+				extended = true
+			}
+		}
+	}
+	return end
+}
+
+// Returns when the unit is back in range of its targets, or the current time if
+// it isn't out of range.
+func (unit *Unit) OutOfRangeUntil(sim *Simulation) time.Duration {
+	end := sim.CurrentTime
+	for _, event := range unit.movementEvents {
+		if event.OutOfRange {
+			end = MaxDuration(end, event.activeUntil(sim.CurrentTime))
+		}
+	}
+	return end
+}
+
+// Returns how long until the unit next has to move, 0 if it is already moving,
+// or NeverExpires if it never moves again.
+func (unit *Unit) TimeUntilMovement(sim *Simulation) time.Duration {
+	if unit.IsMoving(sim) {
+		return 0
+	}
+	next := unit.nextMovementStart(sim.CurrentTime)
+	if next == NeverExpires {
+		return NeverExpires
+	}
+	return next - sim.CurrentTime
+}
+
+// Returns whether a cast of this spell would finish before its caster next has
+// to move. Rotations use this to pick instants instead of hard casts.
+func (spell *Spell) FinishesBeforeMovement(sim *Simulation) bool {
+	castTime := spell.DefaultCast.CastTime + spell.DefaultCast.ChannelTime
+	if castTime == 0 {
+		return true
+	}
+	return spell.Unit.TimeUntilMovement(sim) >= spell.Unit.ApplyCastSpeedForSpell(castTime, spell)
+}
+
+func (unit *Unit) nextMovementStart(t time.Duration) time.Duration {
+	next := NeverExpires
+	for _, event := range unit.movementEvents {
+		next = MinDuration(next, event.nextStart(t))
+	}
+	return next
+}
+
+// Schedules the unit's next movement which starts at or after t.
+func (unit *Unit) scheduleMovement(sim *Simulation, t time.Duration) {
+	next := unit.nextMovementStart(t)
+	if next == NeverExpires {
+		return
+	}
+	sim.AddPendingAction(&PendingAction{
+		NextActionAt: next,
+		Priority:     ActionPriorityAuto,
+		OnAction: func(sim *Simulation) {
+			unit.startMoving(sim)
+			unit.scheduleMovement(sim, sim.CurrentTime+1)
+		},
+	})
+}
+
+// Stops attacks which can't happen while moving. Hard casts and channels are
+// delayed when they are cast, see wrapCastFuncMovement.
+func (unit *Unit) startMoving(sim *Simulation) {
+	end := unit.MovementEndsAt(sim)
+	outOfRangeUntil := unit.OutOfRangeUntil(sim)
+	if sim.Log != nil {
+		if outOfRangeUntil > sim.CurrentTime {
+			unit.Log(sim, "Out of range for %s.", outOfRangeUntil-sim.CurrentTime)
+		} else {
+			unit.Log(sim, "Moving for %s.", end-sim.CurrentTime)
+		}
+	}
+
+	// Melee can keep swinging while they move, as long as they stay in range.
+	if unit.AutoAttacks.IsEnabled() {
+		if unit.AutoAttacks.AutoSwingRanged {
+			unit.AutoAttacks.DelayRangedUntil(sim, end)
+		} else if outOfRangeUntil > sim.CurrentTime {
+			unit.AutoAttacks.DelayMeleeUntil(sim, outOfRangeUntil)
+		}
+	}
+
+	if outOfRangeUntil > sim.CurrentTime && unit.gcdAction != nil && unit.GCD.ReadyAt() < outOfRangeUntil {
+		unit.SetGCDTimer(sim, outOfRangeUntil)
+	}
+}
+
+func (env *Environment) setupMovement(events []*MovementEvent) {
+	for _, party := range env.Raid.Parties {
+		for _, player := range party.Players {
+			character := player.GetCharacter()
+			for _, event := range events {
+				if event.affects(character) {
+					character.movementEvents = append(character.movementEvents, event)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestMovementEvents(t *testing.T) {
+	request := fakeSimRequest()
+	request.Encounter.Movement = []*proto.MovementEvent{
+		{StartTime: 10, Duration: 5, Interval: 20},
+		{StartTime: 0, Duration: 100, Roles: []proto.PlayerRole{proto.PlayerRole_PlayerRoleMelee}},
+	}
+	sim := NewSim(*request)
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	spell := fa.RegisterSpell(SpellConfig{
+		ActionID: ActionID{SpellID: 43},
+		Cast: CastConfig{
+			DefaultCast: Cast{
+				GCD:      GCDDefault,
+				CastTime: time.Second * 2,
+			},
+		},
+		ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {},
+	})
+	spell.finalize()
+	sim.Reset()
+	// The fake player has no rotation.
+	fa.gcdAction.Cancel(sim)
+
+	// Only the event for everyone affects the caster.
+	if fa.TimeUntilMovement(sim) != time.Second*10 {
+		t.Fatalf("Expected the caster to first move at 10s, got %s", fa.TimeUntilMovement(sim))
+	}
+	if !spell.FinishesBeforeMovement(sim) {
+		t.Fatalf("Expected the cast to finish before the movement at 10s")
+	}
+
+	runPendingActionsUntil(sim, time.Second*9)
+	if spell.FinishesBeforeMovement(sim) {
+		t.Fatalf("Expected a cast at 9s not to finish before the movement at 10s")
+	}
+
+	runPendingActionsUntil(sim, time.Second*12)
+	if !fa.IsMoving(sim) || fa.MovementEndsAt(sim) != time.Second*15 {
+		t.Fatalf("Expected the caster to move until 15s, got %s", fa.MovementEndsAt(sim))
+	}
+
+	spell.Cast(sim, fa.CurrentTarget)
+	if fa.Hardcast.Expires != time.Second*17 {
+		t.Fatalf("Expected the cast to begin once the movement ends and finish at 17s, got %s", fa.Hardcast.Expires)
+	}
+	if next := fa.nextMovementStart(time.Second * 16); next != time.Second*30 {
+		t.Fatalf("Expected the movement to repeat at 30s, got %s", next)
+	}
+}
+
+func TestMovementDelaysChannels(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	fa.movementEvents = []*MovementEvent{{StartTime: 0, Duration: time.Second * 2}}
+
+	channeledAt := NeverExpires
+	channel := fa.RegisterSpell(SpellConfig{
+		ActionID: ActionID{SpellID: 43},
+		Cast: CastConfig{
+			DefaultCast: Cast{
+				GCD:         GCDDefault,
+				ChannelTime: time.Second * 3,
+			},
+		},
+		ApplyEffects: func(sim *Simulation, _ *Unit, _ *Spell) {
+			channeledAt = sim.CurrentTime
+		},
+	})
+	channel.finalize()
+	sim.Reset()
+
+	channel.Cast(sim, fa.CurrentTarget)
+	if channeledAt != NeverExpires {
+		t.Fatalf("Expected the channel to wait until the movement ends, but it began at %s", channeledAt)
+	}
+	if fa.Hardcast.Expires != time.Second*2 {
+		t.Fatalf("Expected the channel to begin at 2s, got %s", fa.Hardcast.Expires)
+	}
+	if fa.GCD.ReadyAt() != time.Second*5 {
+		t.Fatalf("Expected the channel to end at 5s, got %s", fa.GCD.ReadyAt())
+	}
+}
+
+func TestOutOfRangeBlocksInstants(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	fa.movementEvents = []*MovementEvent{{StartTime: 0, Duration: time.Second * 4, OutOfRange: true}}
+
+	fa.startMoving(sim)
+	if fa.OutOfRangeUntil(sim) != time.Second*4 {
+		t.Fatalf("Expected to be out of range until 4s, got %s", fa.OutOfRangeUntil(sim))
+	}
+	if fa.GCD.ReadyAt() != time.Second*4 {
+		t.Fatalf("Expected the GCD to be blocked until 4s, got %s", fa.GCD.ReadyAt())
+	}
+}
+
+func TestMeleeMovingInRange(t *testing.T) {
+	sim := NewSim(*fakeSimRequest())
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	fa.EnableAutoAttacks(fa, AutoAttackOptions{
+		MainHand: Weapon{
+			BaseDamageMin:  100,
+			BaseDamageMax:  100,
+			SwingSpeed:     2,
+			SwingDuration:  time.Second * 2,
+			CritMultiplier: 2,
+		},
+		AutoSwingMelee: true,
+	})
+	fa.AutoAttacks.finalize()
+	sim.Reset()
+	fa.movementEvents = []*MovementEvent{{StartTime: 0, Duration: time.Second * 4}}
+
+	fa.startMoving(sim)
+	if fa.GCD.ReadyAt() != 0 || fa.AutoAttacks.MainhandSwingAt != 0 {
+		t.Fatalf("Expected moving in range not to delay the GCD or swings, got GCD at %s and swing at %s",
+			fa.GCD.ReadyAt(), fa.AutoAttacks.MainhandSwingAt)
+	}
+
+	// The fake player casts on every GCD.
+	runPendingActionsUntil(sim, time.Second*4)
+	if casts := fa.Spell.SpellMetrics[0].Casts; casts != 3 {
+		t.Fatalf("Expected 3 casts while moving in range, got %d", casts)
+	}
+	if swings := fa.AutoAttacks.MHAuto.SpellMetrics[0].Casts; swings != 3 {
+		t.Fatalf("Expected swings at 0s, 2s and 4s while moving in range, got %d", swings)
+	}
+}
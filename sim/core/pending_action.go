@@ -20,6 +20,10 @@ const (
 
 	// DOTs need to be higher than anything else so that dots can properly expire before we take other actions.
 	ActionPriorityDOT ActionPriority = 3
+
+	// Encounter phase changes happen before anything else at the same time, so
+	// actions see which targets are active.
+	ActionPriorityPhase ActionPriority = 4
 )
 
 type PendingAction struct {
@@ -31,6 +35,13 @@ type PendingAction struct {
 
 	cancelled bool
 	consumed  bool
+
+	// Scheduling state while this action is in the pending action queue.
+	queued        bool
+	queueIndex    int
+	queueSeq      uint64
+	queueAt       time.Duration
+	queuePriority ActionPriority
 }
 
 func (pa *PendingAction) Cancel(sim *Simulation) {
@@ -44,4 +55,130 @@ func (pa *PendingAction) Cancel(sim *Simulation) {
 	}
 
 	pa.cancelled = true
+
+	if pa.queued {
+		sim.pendingActions.remove(pa)
+	}
+}
+
+// Returns whether pa should be executed before other.
+//
+// Actions are ordered by time, then by descending priority. Actions with equal
+// time and priority execute in the order they were added.
+func (pa *PendingAction) before(other *PendingAction) bool {
+	if pa.queueAt != other.queueAt {
+		return pa.queueAt < other.queueAt
+	}
+	if pa.queuePriority != other.queuePriority {
+		return pa.queuePriority > other.queuePriority
+	}
+	return pa.queueSeq < other.queueSeq
+}
+
+// Binary min-heap of the actions waiting to be executed, using
+// PendingAction.before as the ordering.
+type pendingActionQueue struct {
+	actions []*PendingAction
+	nextSeq uint64
+}
+
+func (queue *pendingActionQueue) reset() {
+	for _, pa := range queue.actions {
+		pa.queued = false
+	}
+	queue.actions = queue.actions[:0]
+	queue.nextSeq = 0
+}
+
+func (queue *pendingActionQueue) len() int {
+	return len(queue.actions)
+}
+
+// Adds pa to the queue. If pa is already queued it is rescheduled instead,
+// using its current NextActionAt.
+func (queue *pendingActionQueue) push(pa *PendingAction) {
+	pa.queueAt = pa.NextActionAt
+	pa.queuePriority = pa.Priority
+	pa.queueSeq = queue.nextSeq
+	queue.nextSeq++
+
+	if pa.queued {
+		queue.fix(pa.queueIndex)
+		return
+	}
+
+	pa.queued = true
+	pa.queueIndex = len(queue.actions)
+	queue.actions = append(queue.actions, pa)
+	queue.up(pa.queueIndex)
+}
+
+// Removes and returns the next action to execute.
+func (queue *pendingActionQueue) pop() *PendingAction {
+	pa := queue.actions[0]
+	queue.removeAt(0)
+	return pa
+}
+
+func (queue *pendingActionQueue) remove(pa *PendingAction) {
+	queue.removeAt(pa.queueIndex)
+}
+
+func (queue *pendingActionQueue) removeAt(i int) {
+	pa := queue.actions[i]
+	last := len(queue.actions) - 1
+	if i != last {
+		queue.swap(i, last)
+	}
+	queue.actions[last] = nil
+	queue.actions = queue.actions[:last]
+	pa.queued = false
+
+	if i != last {
+		queue.fix(i)
+	}
+}
+
+func (queue *pendingActionQueue) fix(i int) {
+	if !queue.down(i) {
+		queue.up(i)
+	}
+}
+
+func (queue *pendingActionQueue) swap(i, j int) {
+	queue.actions[i], queue.actions[j] = queue.actions[j], queue.actions[i]
+	queue.actions[i].queueIndex = i
+	queue.actions[j].queueIndex = j
+}
+
+func (queue *pendingActionQueue) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !queue.actions[i].before(queue.actions[parent]) {
+			break
+		}
+		queue.swap(i, parent)
+		i = parent
+	}
+}
+
+// Returns whether the element moved.
+func (queue *pendingActionQueue) down(i int) bool {
+	start := i
+	n := len(queue.actions)
+	for {
+		child := 2*i + 1
+		if child >= n {
+			break
+		}
+		if right := child + 1; right < n && queue.actions[right].before(queue.actions[child]) {
+			child = right
+		}
+		if !queue.actions[child].before(queue.actions[i]) {
+			break
+		}
+		queue.swap(i, child)
+		i = child
+	}
+	return i > start
 }
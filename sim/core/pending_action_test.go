@@ -0,0 +1,50 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPendingActionOrder(t *testing.T) {
+	sim := &Simulation{}
+	sim.pendingActions.reset()
+
+	var order []string
+	add := func(label string, at time.Duration, priority ActionPriority) *PendingAction {
+		pa := &PendingAction{
+			NextActionAt: at,
+			Priority:     priority,
+			OnAction: func(sim *Simulation) {
+				order = append(order, label)
+			},
+		}
+		sim.AddPendingAction(pa)
+		return pa
+	}
+
+	add("late", time.Second*2, ActionPriorityDOT)
+	add("gcd1", time.Second, ActionPriorityGCD)
+	add("dot", time.Second, ActionPriorityDOT)
+	add("gcd2", time.Second, ActionPriorityGCD)
+	cancelled := add("cancelled", time.Second, ActionPriorityAuto)
+	add("auto", time.Second, ActionPriorityAuto)
+	add("early", 0, ActionPriorityLow)
+	cancelled.Cancel(sim)
+
+	for sim.pendingActions.len() > 0 {
+		pa := sim.pendingActions.pop()
+		if !pa.cancelled {
+			pa.OnAction(sim)
+		}
+	}
+
+	expected := []string{"early", "dot", "auto", "gcd1", "gcd2", "late"}
+	if len(order) != len(expected) {
+		t.Fatalf("Expected actions %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("Expected actions %v, got %v", expected, order)
+		}
+	}
+}
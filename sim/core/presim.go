@@ -75,7 +75,7 @@ func (sim *Simulation) runPresims(request proto.RaidSimRequest) *proto.RaidSimRe
 	var lastResult *proto.RaidSimResult
 
 	doOne := sim.Encounter.EndFightAtHealth > 0
-	for doOne || remainingAgents > 0 {
+	for round := 0; doOne || remainingAgents > 0; round++ {
 		// ** Run a presim round. **
 
 		// Let each Agent modify their own settings.
@@ -93,8 +93,14 @@ func (sim *Simulation) runPresims(request proto.RaidSimRequest) *proto.RaidSimRe
 			}
 		}
 
-		// Run the presim.
-		presimResult := runSim(*presimRequest, nil, true)
+		// Run the presim, unless another worker already has.
+		var presimResult *proto.RaidSimResult
+		if round < len(sim.presimRounds) {
+			presimResult = sim.presimRounds[round]
+		} else {
+			presimResult = runSim(sim.ctx, *presimRequest, nil, true)
+			sim.presimRounds = append(sim.presimRounds, presimResult)
+		}
 		lastResult = presimResult
 
 		if presimResult.ErrorResult != "" {
@@ -121,6 +121,9 @@ func (rb *rageBar) AddRage(sim *Simulation, amount float64, metrics *ResourceMet
 	if sim.Log != nil {
 		rb.unit.Log(sim, "Gained %0.3f rage from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, rb.currentRage, newRage)
 	}
+	if sim.EventLog != nil {
+		rb.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, proto.ResourceType_ResourceTypeRage, metrics.ActionID, amount, rb.currentRage, newRage)
+	}
 
 	rb.currentRage = newRage
 	rb.onRageGain(sim)
@@ -137,6 +140,9 @@ func (rb *rageBar) SpendRage(sim *Simulation, amount float64, metrics *ResourceM
 	if sim.Log != nil {
 		rb.unit.Log(sim, "Spent %0.3f rage from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, rb.currentRage, newRage)
 	}
+	if sim.EventLog != nil {
+		rb.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceSpent, proto.ResourceType_ResourceTypeRage, metrics.ActionID, amount, rb.currentRage, newRage)
+	}
 
 	rb.currentRage = newRage
 }
@@ -401,6 +401,22 @@ func (raid *Raid) doneIteration(sim *Simulation) {
 	raid.hpsMetrics.doneIteration(sim.rand.GetSeed(), sim.CurrentTime.Seconds())
 }
 
+// Combines the metrics of an identically-constructed Raid from another
+// Simulation into this one.
+func (raid *Raid) mergeMetrics(other *Raid) {
+	raid.dpsMetrics.merge(&other.dpsMetrics)
+	raid.hpsMetrics.merge(&other.hpsMetrics)
+	for i, party := range raid.Parties {
+		party.dpsMetrics.merge(&other.Parties[i].dpsMetrics)
+		party.hpsMetrics.merge(&other.Parties[i].hpsMetrics)
+	}
+	for i, unit := range raid.AllUnits {
+		otherUnit := other.AllUnits[i]
+		unit.Metrics.merge(&otherUnit.Metrics)
+		unit.auraTracker.mergeMetrics(&otherUnit.auraTracker)
+	}
+}
+
 func (raid *Raid) GetMetrics(numIterations int32) *proto.RaidMetrics {
 	metrics := &proto.RaidMetrics{
 		Dps: raid.dpsMetrics.ToProto(numIterations),
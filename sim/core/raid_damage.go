@@ -0,0 +1,146 @@
+package core
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Damage to the raid from the encounter, see proto.RaidDamageEvent. The damage
+// is dealt by the first target, so it goes through the normal spell pipeline
+// and triggers effects on the players it hits.
+type raidDamageEvent struct {
+	config *proto.RaidDamageEvent
+
+	startTime time.Duration
+	interval  time.Duration
+	endTime   time.Duration
+
+	spell *Spell
+	dots  []*Dot // Indexed by UnitIndex, only for DoT events.
+}
+
+func (env *Environment) setupRaidDamage(configs []*proto.RaidDamageEvent) {
+	if len(configs) == 0 {
+		return
+	}
+
+	source := env.Encounter.Targets[0]
+	var players []*Unit
+	for _, unit := range env.Raid.AllUnits {
+		if unit.Type == PlayerUnit {
+			players = append(players, unit)
+		}
+	}
+
+	var events []*raidDamageEvent
+	for i, config := range configs {
+		event := &raidDamageEvent{
+			config:    config,
+			startTime: DurationFromSeconds(config.StartTime),
+			interval:  DurationFromSeconds(config.Interval),
+			endTime:   DurationFromSeconds(config.EndTime),
+		}
+		event.register(source, players, i)
+		events = append(events, event)
+	}
+
+	source.RegisterResetEffect(func(sim *Simulation) {
+		for _, event := range events {
+			event.schedule(sim, players, event.startTime)
+		}
+	})
+}
+
+func (event *raidDamageEvent) register(source *Target, players []*Unit, eventIndex int) {
+	config := event.config
+	school := SpellSchoolFromProto(config.School)
+	procMask := ProcMaskSpellDamage
+	if school == SpellSchoolPhysical {
+		procMask = ProcMaskMeleeMHSpecial
+	}
+
+	event.spell = source.RegisterSpell(SpellConfig{
+		ActionID:    ActionID{SpellID: config.SpellId, Tag: int32(eventIndex)},
+		SpellSchool: school,
+		ProcMask:    procMask,
+
+		DamageMultiplier: 1,
+		CritMultiplier:   2,
+
+		ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+			if event.dots != nil {
+				event.dots[target.UnitIndex].Apply(sim)
+				return
+			}
+
+			baseDamage := sim.Roll(config.MinDamage, config.MaxDamage)
+			if school == SpellSchoolPhysical {
+				spell.CalcAndDealDamageEnemyMeleeWhite(sim, target, baseDamage)
+			} else {
+				spell.CalcAndDealDamageMagicHit(sim, target, baseDamage)
+			}
+		},
+	})
+
+	if config.DotDuration <= 0 || config.TickInterval <= 0 {
+		return
+	}
+	tickLength := DurationFromSeconds(config.TickInterval)
+	numTicks := MaxInt(1, int(DurationFromSeconds(config.DotDuration)/tickLength))
+
+	event.dots = make([]*Dot, len(source.Env.AllUnits))
+	for _, player := range players {
+		dot := NewDot(Dot{
+			Spell: event.spell,
+			Aura: player.RegisterAura(Aura{
+				Label:    "RaidDamage-" + strconv.Itoa(eventIndex),
+				ActionID: event.spell.ActionID,
+			}),
+			NumberOfTicks: numTicks,
+			TickLength:    tickLength,
+			TickEffects: TickFuncSnapshot(player, SpellEffect{
+				IsPeriodic:     true,
+				BaseDamage:     BaseDamageConfigRoll(config.MinDamage, config.MaxDamage),
+				OutcomeApplier: source.OutcomeFuncTick(),
+			}),
+		})
+		event.dots[player.UnitIndex] = dot
+	}
+}
+
+// Schedules the next hit at or after t.
+func (event *raidDamageEvent) schedule(sim *Simulation, players []*Unit, t time.Duration) {
+	if event.endTime != 0 && t > event.endTime {
+		return
+	}
+
+	sim.AddPendingAction(&PendingAction{
+		NextActionAt: t,
+		OnAction: func(sim *Simulation) {
+			for _, player := range event.pickTargets(sim, players) {
+				event.spell.Cast(sim, player)
+			}
+			if event.interval > 0 {
+				event.schedule(sim, players, sim.CurrentTime+event.interval)
+			}
+		},
+	})
+}
+
+// Returns the players hit by one occurrence of this event.
+func (event *raidDamageEvent) pickTargets(sim *Simulation, players []*Unit) []*Unit {
+	numTargets := int(event.config.NumTargets)
+	if numTargets <= 0 || numTargets >= len(players) {
+		return players
+	}
+
+	// Partial shuffle of a copy, so the picks are distinct.
+	candidates := append([]*Unit{}, players...)
+	for i := 0; i < numTargets; i++ {
+		j := i + int(sim.RandomFloat("Raid Damage Target")*float64(len(candidates)-i))
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	}
+	return candidates[:numTargets]
+}
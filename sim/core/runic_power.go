@@ -191,6 +191,9 @@ func (rp *RunicPowerBar) addRunicPowerInterval(sim *Simulation, amount float64,
 		if sim.Log != nil {
 			rp.unit.Log(sim, "Gained %0.3f runic power from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, rp.currentRunicPower, newRunicPower)
 		}
+		if sim.EventLog != nil {
+			rp.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, proto.ResourceType_ResourceTypeRunicPower, metrics.ActionID, amount, rp.currentRunicPower, newRunicPower)
+		}
 	}
 
 	rp.currentRunicPower = newRunicPower
@@ -216,6 +219,9 @@ func (rp *RunicPowerBar) spendRunicPower(sim *Simulation, amount float64, metric
 		if sim.Log != nil {
 			rp.unit.Log(sim, "Spent %0.3f runic power from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, rp.currentRunicPower, newRunicPower)
 		}
+		if sim.EventLog != nil {
+			rp.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceSpent, proto.ResourceType_ResourceTypeRunicPower, metrics.ActionID, amount, rp.currentRunicPower, newRunicPower)
+		}
 	}
 
 	rp.currentRunicPower = newRunicPower
@@ -718,8 +724,7 @@ func (rp *RunicPowerBar) GainRuneMetrics(sim *Simulation, metrics *ResourceMetri
 	if !rp.isACopy {
 		metrics.AddEvent(float64(gainAmount), float64(gainAmount))
 
-		if sim.Log != nil {
-
+		if sim.Log != nil || sim.EventLog != nil {
 			var name string
 			var currRunes int8
 
@@ -740,7 +745,12 @@ func (rp *RunicPowerBar) GainRuneMetrics(sim *Simulation, metrics *ResourceMetri
 				panic("invalid metrics for rune gaining")
 			}
 
-			rp.unit.Log(sim, "Gained %0.3f %s rune from %s (%d --> %d).", float64(gainAmount), name, metrics.ActionID, currRunes-gainAmount, currRunes)
+			if sim.Log != nil {
+				rp.unit.Log(sim, "Gained %0.3f %s rune from %s (%d --> %d).", float64(gainAmount), name, metrics.ActionID, currRunes-gainAmount, currRunes)
+			}
+			if sim.EventLog != nil {
+				rp.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, metrics.Type, metrics.ActionID, float64(gainAmount), float64(currRunes-gainAmount), float64(currRunes))
+			}
 		}
 	}
 }
@@ -750,7 +760,7 @@ func (rp *RunicPowerBar) SpendRuneMetrics(sim *Simulation, metrics *ResourceMetr
 	if !rp.isACopy {
 		metrics.AddEvent(-float64(spendAmount), -float64(spendAmount))
 
-		if sim.Log != nil {
+		if sim.Log != nil || sim.EventLog != nil {
 			var name string
 			var currRunes int8
 
@@ -771,7 +781,12 @@ func (rp *RunicPowerBar) SpendRuneMetrics(sim *Simulation, metrics *ResourceMetr
 				panic("invalid metrics for rune spending")
 			}
 
-			rp.unit.Log(sim, "Spent 1.000 %s rune from %s (%d --> %d).", name, metrics.ActionID, currRunes+spendAmount, currRunes)
+			if sim.Log != nil {
+				rp.unit.Log(sim, "Spent 1.000 %s rune from %s (%d --> %d).", name, metrics.ActionID, currRunes+spendAmount, currRunes)
+			}
+			if sim.EventLog != nil {
+				rp.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceSpent, metrics.Type, metrics.ActionID, float64(spendAmount), float64(currRunes+spendAmount), float64(currRunes))
+			}
 		}
 	}
 }
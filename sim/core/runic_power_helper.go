@@ -3,6 +3,8 @@ package core
 import (
 	"fmt"
 	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
 )
 
 type RuneCost uint16
@@ -116,6 +118,9 @@ func (rp *RunicPowerBar) GainDeathRuneMetrics(sim *Simulation, spell *Spell, cur
 		if sim.Log != nil {
 			rp.unit.Log(sim, "Gained 1.000 death rune from %s (%d --> %d).", metrics.ActionID, currRunes, newRunes)
 		}
+		if sim.EventLog != nil {
+			rp.unit.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, proto.ResourceType_ResourceTypeDeathRune, metrics.ActionID, 1, float64(currRunes), float64(newRunes))
+		}
 	}
 }
 
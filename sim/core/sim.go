@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"runtime/debug"
@@ -17,12 +18,15 @@ type Simulation struct {
 
 	rand Rand
 
+	// Cancelling this stops the sim between iterations.
+	ctx context.Context
+
 	// Used for testing only, see RandomFloat().
 	isTest    bool
 	testRands map[string]Rand
 
 	// Current Simulation State
-	pendingActions []*PendingAction
+	pendingActions pendingActionQueue
 	CurrentTime    time.Duration // duration that has elapsed in the sim since starting
 	Duration       time.Duration // Duration of current iteration
 
@@ -31,6 +35,19 @@ type Simulation struct {
 	Log  func(string, ...interface{})
 	logs []string
 
+	// Receives structured combat log events. Nil when structured logging is
+	// disabled, so callers should check before building an event.
+	EventLog func(*proto.CombatLogEvent)
+
+	// Global index of the current iteration, used to tag structured log events.
+	// Parallel workers start at an offset into the full iteration range.
+	firstIteration int32
+	iteration      int32
+
+	// Results of each presim round. Parallel workers replay the rounds of the
+	// first worker instead of running their own.
+	presimRounds []*proto.RaidSimResult
+
 	executePhase20        bool
 	executePhase25        bool
 	executePhase35        bool
@@ -38,10 +55,15 @@ type Simulation struct {
 }
 
 func RunSim(rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics) (result *proto.RaidSimResult) {
-	return runSim(rsr, progress, false)
+	return runSim(context.Background(), rsr, progress, false)
 }
 
-func runSim(rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) (result *proto.RaidSimResult) {
+// Like RunSim, but stops early with an error result if ctx is cancelled.
+func RunSimWithContext(ctx context.Context, rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics) (result *proto.RaidSimResult) {
+	return runSim(ctx, rsr, progress, false)
+}
+
+func runSim(ctx context.Context, rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) (result *proto.RaidSimResult) {
 	defer func() {
 		if err := recover(); err != nil {
 			errStr := ""
@@ -67,7 +89,13 @@ func runSim(rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skip
 		}
 	}()
 
+	if rsr.SimOptions.NumWorkers > 1 && rsr.SimOptions.Iterations > 1 && runtime.GOARCH != "wasm" {
+		result = runParallelSim(ctx, rsr, progress, skipPresim)
+		return result
+	}
+
 	sim := NewSim(rsr)
+	sim.ctx = ctx
 
 	if !skipPresim {
 		if progress != nil {
@@ -77,8 +105,7 @@ func runSim(rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skip
 			}
 			runtime.Gosched() // allow time for message to make it back out.
 		}
-		presimResult := sim.runPresims(rsr)
-		if presimResult != nil && presimResult.ErrorResult != "" {
+		if presimResult := sim.applyPresims(rsr); presimResult != nil {
 			if progress != nil {
 				progress <- &proto.ProgressMetrics{
 					TotalIterations: sim.Options.Iterations,
@@ -97,12 +124,6 @@ func runSim(rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skip
 			}
 			runtime.Gosched() // allow time for message to make it back out.
 		}
-		// Use pre-sim as estimate for length of fight (when using health fight)
-		if sim.Encounter.EndFightAtHealth > 0 && presimResult != nil {
-			sim.BaseDuration = time.Duration(presimResult.AvgIterationDuration) * time.Second
-			sim.Duration = time.Duration(presimResult.AvgIterationDuration) * time.Second
-			sim.Encounter.DurationIsEstimate = false // we now have a pretty good value for duration
-		}
 	}
 
 	// using a variable here allows us to mutate it in the deferred recover, sending out error info
@@ -111,6 +132,23 @@ func runSim(rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skip
 	return result
 }
 
+// Runs the presims and applies their results to this Simulation. Returns a
+// non-nil result only if a presim failed.
+func (sim *Simulation) applyPresims(rsr proto.RaidSimRequest) *proto.RaidSimResult {
+	presimResult := sim.runPresims(rsr)
+	if presimResult != nil && presimResult.ErrorResult != "" {
+		return presimResult
+	}
+
+	// Use pre-sim as estimate for length of fight (when using health fight)
+	if sim.Encounter.EndFightAtHealth > 0 && presimResult != nil {
+		sim.BaseDuration = time.Duration(presimResult.AvgIterationDuration) * time.Second
+		sim.Duration = time.Duration(presimResult.AvgIterationDuration) * time.Second
+		sim.Encounter.DurationIsEstimate = false // we now have a pretty good value for duration
+	}
+	return nil
+}
+
 func NewSim(rsr proto.RaidSimRequest) *Simulation {
 	simOptions := *rsr.SimOptions
 	rseed := simOptions.RandomSeed
@@ -124,6 +162,7 @@ func NewSim(rsr proto.RaidSimRequest) *Simulation {
 		Options:     simOptions,
 
 		rand: NewSplitMix(uint64(rseed)),
+		ctx:  context.Background(),
 
 		isTest:    simOptions.IsTest,
 		testRands: make(map[string]Rand),
@@ -171,8 +210,8 @@ func (sim *Simulation) reset() {
 		sim.Log("----------------------")
 	}
 
-	// Reset primary targets damage taken for tracking health fights.
-	sim.Encounter.DamageTaken = 0
+	// Reset damage taken and deaths for tracking health fights.
+	sim.Encounter.resetTargetHealth()
 
 	if sim.Encounter.DurationIsEstimate && sim.CurrentTime != 0 {
 		sim.BaseDuration = sim.CurrentTime
@@ -186,7 +225,7 @@ func (sim *Simulation) reset() {
 
 	sim.CurrentTime = 0.0
 
-	sim.pendingActions = make([]*PendingAction, 0, 64)
+	sim.pendingActions.reset()
 
 	sim.executePhase20 = false
 	sim.executePhase25 = false
@@ -195,18 +234,26 @@ func (sim *Simulation) reset() {
 
 	// Targets need to be reset before the raid, so that players can check for
 	// the presence of permanent target auras in their Reset handlers.
+	sim.Encounter.resetActiveTargets()
 	for _, target := range sim.Encounter.Targets {
 		target.Reset(sim)
 	}
 
 	sim.Raid.reset(sim)
 
+	// After the raid, so phases can change player targets.
+	sim.Encounter.resetPhases(sim)
+
 	sim.initManaTickAction()
 }
 
 // Run runs the simulation for the configured number of iterations, and
 // collects all the metrics together.
 func (sim *Simulation) run() *proto.RaidSimResult {
+	if sim.ctx.Err() != nil {
+		return sim.cancelledResult()
+	}
+
 	logsBuffer := &strings.Builder{}
 	if sim.Options.Debug || sim.Options.DebugFirstIteration {
 		sim.Log = func(message string, vals ...interface{}) {
@@ -214,6 +261,14 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 		}
 	}
 
+	var combatLog []*proto.CombatLogEvent
+	if sim.Options.StructuredLog {
+		sim.EventLog = func(event *proto.CombatLogEvent) {
+			event.Iteration = sim.iteration
+			combatLog = append(combatLog, event)
+		}
+	}
+
 	// Uncomment this to print logs directly to console.
 	// sim.Options.Debug = true
 	// sim.Log = func(message string, vals ...interface{}) {
@@ -235,6 +290,13 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 		}
 	}
 
+	if sim.Options.SaveAllValues {
+		for _, unit := range sim.AllUnits {
+			unit.Metrics.saveAllValues()
+		}
+	}
+
+	sim.iteration = sim.firstIteration
 	sim.runOnce()
 	firstIterationDuration := sim.Duration
 	if sim.Encounter.EndFightAtHealth != 0 {
@@ -244,10 +306,15 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 
 	if !sim.Options.Debug {
 		sim.Log = nil
+		sim.EventLog = nil
 	}
 
 	var st time.Time
 	for i := int32(1); i < sim.Options.Iterations; i++ {
+		if sim.ctx.Err() != nil {
+			return sim.cancelledResult()
+		}
+
 		// fmt.Printf("Iteration: %d\n", i)
 		if sim.ProgressReport != nil && time.Since(st) > time.Millisecond*100 {
 			metrics := sim.Raid.GetMetrics(i + 1)
@@ -259,6 +326,7 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 		// Before each iteration, reset state to seed+iterations
 		sim.rand.Seed(sim.Options.RandomSeed + int64(i))
 
+		sim.iteration = sim.firstIteration + i
 		sim.runOnce()
 		iterDuration := sim.Duration
 		if sim.Encounter.EndFightAtHealth != 0 {
@@ -273,6 +341,8 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 		Logs:                   logsBuffer.String(),
 		FirstIterationDuration: firstIterationDuration.Seconds(),
 		AvgIterationDuration:   totalDuration.Seconds() / float64(sim.Options.Iterations),
+
+		CombatLog: combatLog,
 	}
 
 	// Final progress report
@@ -283,14 +353,23 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 	return result
 }
 
+// Result for a sim that was stopped early because its context was cancelled.
+func (sim *Simulation) cancelledResult() *proto.RaidSimResult {
+	result := &proto.RaidSimResult{
+		ErrorResult: "Sim cancelled: " + sim.ctx.Err().Error(),
+	}
+	if sim.ProgressReport != nil {
+		sim.ProgressReport(&proto.ProgressMetrics{TotalIterations: sim.Options.Iterations, FinalRaidResult: result})
+	}
+	return result
+}
+
 // RunOnce is the main event loop. It will run the simulation for number of seconds.
 func (sim *Simulation) runOnce() {
 	sim.reset()
 
 	for {
-		last := len(sim.pendingActions) - 1
-		pa := sim.pendingActions[last]
-		sim.pendingActions = sim.pendingActions[:last]
+		pa := sim.pendingActions.pop()
 		if pa.cancelled {
 			continue
 		}
@@ -300,7 +379,7 @@ func (sim *Simulation) runOnce() {
 			if pa.NextActionAt > sim.Duration {
 				break
 			}
-		} else if sim.Encounter.EndFightAtHealth < sim.Encounter.DamageTaken {
+		} else if sim.Encounter.healthFightOver() {
 			break
 		}
 
@@ -311,8 +390,13 @@ func (sim *Simulation) runOnce() {
 		pa.OnAction(sim)
 	}
 
-	for _, pa := range sim.pendingActions {
-		if pa.CleanUp != nil {
+	// Clean up remaining actions, latest first.
+	remaining := make([]*PendingAction, 0, sim.pendingActions.len())
+	for sim.pendingActions.len() > 0 {
+		remaining = append(remaining, sim.pendingActions.pop())
+	}
+	for i := len(remaining) - 1; i >= 0; i-- {
+		if pa := remaining[i]; pa.CleanUp != nil {
 			pa.CleanUp(sim)
 		}
 	}
@@ -330,15 +414,18 @@ func (sim *Simulation) runOnce() {
 
 func (sim *Simulation) AddPendingAction(pa *PendingAction) {
 	pa.consumed = false
-	for index, v := range sim.pendingActions {
-		if v.NextActionAt < pa.NextActionAt || (v.NextActionAt == pa.NextActionAt && v.Priority >= pa.Priority) {
-			sim.pendingActions = append(sim.pendingActions, pa)
-			copy(sim.pendingActions[index+1:], sim.pendingActions[index:])
-			sim.pendingActions[index] = pa
-			return
-		}
+	sim.pendingActions.push(pa)
+
+	if sim.EventLog != nil {
+		sim.EventLog(&proto.CombatLogEvent{
+			Timestamp:   sim.CurrentTime.Seconds(),
+			Type:        proto.CombatLogEventType_CombatLogEventTypeActionScheduled,
+			UnitIndex:   -1,
+			TargetIndex: -1,
+			ScheduledAt: pa.NextActionAt.Seconds(),
+			Priority:    int32(pa.Priority),
+		})
 	}
-	sim.pendingActions = append(sim.pendingActions, pa)
 }
 
 // Advance moves time forward counting down auras, CDs, mana regen, etc
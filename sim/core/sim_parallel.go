@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Runs the iterations of a sim request across multiple Simulations in parallel,
+// then merges their metrics into a single result.
+//
+// Each worker gets a contiguous range of iterations and seeds each of them like
+// the serial sim would (random_seed + iteration index), so a given seed and
+// worker count always produce the same result, on any machine. Only the number
+// of workers running at once depends on the CPU count. Some state carries over
+// from one iteration to the next though, so results only match the serial sim
+// within the usual sim noise, not exactly.
+func runParallelSim(ctx context.Context, rsr proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool) *proto.RaidSimResult {
+	totalIterations := rsr.SimOptions.Iterations
+	numWorkers := MinInt32(rsr.SimOptions.NumWorkers, totalIterations)
+
+	baseSeed := rsr.SimOptions.RandomSeed
+	if baseSeed == 0 {
+		baseSeed = time.Now().UnixNano()
+	}
+
+	sims := make([]*Simulation, numWorkers)
+	requests := make([]*proto.RaidSimRequest, numWorkers)
+	startIteration := int32(0)
+	for i := range sims {
+		workerIterations := totalIterations / numWorkers
+		if int32(i) < totalIterations%numWorkers {
+			workerIterations++
+		}
+
+		workerRequest := googleProto.Clone(&rsr).(*proto.RaidSimRequest)
+		workerRequest.SimOptions.NumWorkers = 0
+		workerRequest.SimOptions.Iterations = workerIterations
+		workerRequest.SimOptions.RandomSeed = baseSeed + int64(startIteration)
+		if i != 0 {
+			workerRequest.SimOptions.DebugFirstIteration = false
+			if !workerRequest.SimOptions.Debug {
+				workerRequest.SimOptions.StructuredLog = false
+			}
+		}
+
+		requests[i] = workerRequest
+		sims[i] = NewSim(*workerRequest)
+		sims[i].ctx = ctx
+		sims[i].firstIteration = startIteration
+		startIteration += workerIterations
+	}
+
+	if !skipPresim {
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				TotalIterations: totalIterations,
+				PresimRunning:   true,
+			}
+		}
+
+		// Presims always use the same seed, so every worker would get the same
+		// results. Only the first worker runs them, the others replay its rounds
+		// so their agents apply the same settings.
+		presimResult := sims[0].applyPresims(*requests[0])
+		for i, sim := range sims[1:] {
+			if presimResult != nil {
+				break
+			}
+			sim.presimRounds = sims[0].presimRounds
+			presimResult = sim.applyPresims(*requests[i+1])
+		}
+		if presimResult != nil {
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{
+					TotalIterations: totalIterations,
+					FinalRaidResult: presimResult,
+				}
+			}
+			return presimResult
+		}
+
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				TotalIterations: totalIterations,
+				PresimRunning:   false,
+			}
+			reporter := newParallelProgressReporter(progress, totalIterations, int(numWorkers))
+			for i, sim := range sims {
+				sim.ProgressReport = reporter.forWorker(i)
+			}
+		}
+	}
+
+	workerResults := make([]*proto.RaidSimResult, numWorkers)
+	runWorkers(sims, func(i int, sim *Simulation) {
+		workerResults[i] = sim.run()
+	})
+
+	for _, workerResult := range workerResults {
+		if workerResult.ErrorResult != "" {
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{
+					TotalIterations: totalIterations,
+					FinalRaidResult: workerResult,
+				}
+			}
+			return workerResult
+		}
+	}
+
+	mainSim := sims[0]
+	logs := &strings.Builder{}
+	var combatLog []*proto.CombatLogEvent
+	totalDurationSeconds := 0.0
+	for i, sim := range sims {
+		if i != 0 {
+			mainSim.Raid.mergeMetrics(sim.Raid)
+			mainSim.Encounter.mergeMetrics(&sim.Encounter)
+		}
+		logs.WriteString(workerResults[i].Logs)
+		combatLog = append(combatLog, workerResults[i].CombatLog...)
+		totalDurationSeconds += workerResults[i].AvgIterationDuration * float64(sim.Options.Iterations)
+	}
+
+	result := &proto.RaidSimResult{
+		RaidMetrics:      mainSim.Raid.GetMetrics(totalIterations),
+		EncounterMetrics: mainSim.Encounter.GetMetricsProto(totalIterations),
+
+		Logs:                   logs.String(),
+		FirstIterationDuration: workerResults[0].FirstIterationDuration,
+		AvgIterationDuration:   totalDurationSeconds / float64(totalIterations),
+
+		CombatLog: combatLog,
+	}
+
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{TotalIterations: totalIterations, CompletedIterations: totalIterations, Dps: result.RaidMetrics.Dps.Avg, FinalRaidResult: result}
+	}
+
+	return result
+}
+
+// Max number of parallel sim workers which run at once.
+var maxRunningWorkers = runtime.NumCPU()
+
+// Invokes f for each sim on its own goroutine and waits for all of them to finish.
+// At most maxRunningWorkers run at once. A panic in any worker is re-raised on
+// the calling goroutine.
+func runWorkers(sims []*Simulation, f func(int, *Simulation)) {
+	var waitGroup sync.WaitGroup
+	workerErrors := make([]string, len(sims))
+	running := make(chan struct{}, MaxInt(1, maxRunningWorkers))
+
+	for i, sim := range sims {
+		waitGroup.Add(1)
+		go func(i int, sim *Simulation) {
+			defer waitGroup.Done()
+			running <- struct{}{}
+			defer func() { <-running }()
+			defer func() {
+				if err := recover(); err != nil {
+					workerErrors[i] = fmt.Sprintf("%v\nWorker %d Stack Trace:\n%s", err, i, debug.Stack())
+				}
+			}()
+			f(i, sim)
+		}(i, sim)
+	}
+	waitGroup.Wait()
+
+	for _, errStr := range workerErrors {
+		if errStr != "" {
+			panic(errStr)
+		}
+	}
+}
+
+// Combines progress reports from parallel workers into a single stream.
+type parallelProgressReporter struct {
+	mutex sync.Mutex
+
+	progress        chan *proto.ProgressMetrics
+	totalIterations int32
+
+	completedIterations []int32
+	dps                 []float64
+	hps                 []float64
+}
+
+func newParallelProgressReporter(progress chan *proto.ProgressMetrics, totalIterations int32, numWorkers int) *parallelProgressReporter {
+	return &parallelProgressReporter{
+		progress:            progress,
+		totalIterations:     totalIterations,
+		completedIterations: make([]int32, numWorkers),
+		dps:                 make([]float64, numWorkers),
+		hps:                 make([]float64, numWorkers),
+	}
+}
+
+func (reporter *parallelProgressReporter) forWorker(worker int) func(*proto.ProgressMetrics) {
+	return func(progMetric *proto.ProgressMetrics) {
+		// The final result for the whole sim is sent once all workers are merged.
+		if progMetric.FinalRaidResult != nil {
+			return
+		}
+
+		reporter.mutex.Lock()
+		reporter.completedIterations[worker] = progMetric.CompletedIterations
+		reporter.dps[worker] = progMetric.Dps
+		reporter.hps[worker] = progMetric.Hps
+
+		completed := int32(0)
+		dpsSum := 0.0
+		hpsSum := 0.0
+		for i, iterations := range reporter.completedIterations {
+			completed += iterations
+			dpsSum += reporter.dps[i] * float64(iterations)
+			hpsSum += reporter.hps[i] * float64(iterations)
+		}
+		reporter.mutex.Unlock()
+
+		// Nothing to average until a worker has finished an iteration.
+		if completed == 0 {
+			return
+		}
+		reporter.progress <- &proto.ProgressMetrics{
+			TotalIterations:     reporter.totalIterations,
+			CompletedIterations: completed,
+			Dps:                 dpsSum / float64(completed),
+			Hps:                 hpsSum / float64(completed),
+		}
+	}
+}
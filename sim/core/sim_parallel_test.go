@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+func TestRunWorkersLimit(t *testing.T) {
+	defer func(limit int) { maxRunningWorkers = limit }(maxRunningWorkers)
+	maxRunningWorkers = 2
+
+	var mutex sync.Mutex
+	running, maxRunning, numRan := 0, 0, 0
+	runWorkers(make([]*Simulation, 6), func(i int, _ *Simulation) {
+		mutex.Lock()
+		running++
+		maxRunning = MaxInt(maxRunning, running)
+		mutex.Unlock()
+
+		time.Sleep(time.Millisecond * 10)
+
+		mutex.Lock()
+		running--
+		numRan++
+		mutex.Unlock()
+	})
+
+	if numRan != 6 || maxRunning != 2 {
+		t.Fatalf("Expected all 6 workers to run, 2 at once, got %d workers and %d at once", numRan, maxRunning)
+	}
+}
+
+// The number of workers sets how iterations are split and seeded, so results
+// don't depend on how many of them can run at once.
+func TestParallelSimIndependentOfCPUs(t *testing.T) {
+	defer func(limit int) { maxRunningWorkers = limit }(maxRunningWorkers)
+
+	request := fakeSimRequest()
+	request.SimOptions.Iterations = 20
+	request.SimOptions.NumWorkers = 4
+
+	var results [2]*proto.RaidSimResult
+	for i, limit := range []int{1, 4} {
+		maxRunningWorkers = limit
+		results[i] = runParallelSim(context.Background(), *request, nil, false)
+		if results[i].ErrorResult != "" {
+			t.Fatalf("Sim failed with error: %s", results[i].ErrorResult)
+		}
+	}
+	// The fake player's spell can miss, so its outcomes depend on each worker's seed.
+	if outcomes := results[0].RaidMetrics.Parties[0].Players[0].Actions[0].Targets[0]; outcomes.Hits == 0 || outcomes.Misses == 0 {
+		t.Fatalf("Expected the fake player's spell to both hit and miss, got %v", outcomes)
+	}
+	if !googleProto.Equal(results[0], results[1]) {
+		t.Fatalf("Expected the same results regardless of how many workers run at once")
+	}
+}
+
+func TestParallelProgressBeforeFirstIteration(t *testing.T) {
+	progress := make(chan *proto.ProgressMetrics, 10)
+	reporter := newParallelProgressReporter(progress, 100, 2)
+
+	reporter.forWorker(0)(&proto.ProgressMetrics{CompletedIterations: 0})
+	if len(progress) != 0 {
+		t.Fatalf("Expected no progress before any iterations finish, got %v", <-progress)
+	}
+
+	reporter.forWorker(1)(&proto.ProgressMetrics{CompletedIterations: 10, Dps: 1000})
+	if progMetric := <-progress; progMetric.CompletedIterations != 10 || progMetric.Dps != 1000 {
+		t.Fatalf("Expected progress of 10 iterations at 1000 DPS, got %v", progMetric)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
 )
 
@@ -24,7 +25,8 @@ type SpellConfig struct {
 
 	Cast CastConfig
 
-	ApplyEffects ApplySpellEffects
+	ApplyEffects   ApplySpellEffects
+	ExpectedDamage float64
 
 	BonusHitRating       float64
 	BonusCritRating      float64
@@ -112,6 +114,10 @@ type Spell struct {
 
 	ApplyEffects ApplySpellEffects
 
+	// Average damage of one cast, used for the damage prevented by interrupts.
+	// Only set for interruptible enemy spells.
+	ExpectedDamage float64
+
 	// The current or most recent cast data.
 	CurCast Cast
 
@@ -183,7 +189,8 @@ func (unit *Unit) RegisterSpell(config SpellConfig) *Spell {
 		CD:          config.Cast.CD,
 		SharedCD:    config.Cast.SharedCD,
 
-		ApplyEffects: config.ApplyEffects,
+		ApplyEffects:   config.ApplyEffects,
+		ExpectedDamage: config.ExpectedDamage,
 
 		BonusHitRating:           config.BonusHitRating,
 		BonusCritRating:          config.BonusCritRating,
@@ -403,6 +410,10 @@ func (spell *Spell) SkipCastAndApplyEffects(sim *Simulation, target *Unit) {
 			spell.ActionID, spell.DefaultCast.Cost, time.Duration(0))
 		spell.Unit.Log(sim, "Completed cast %s", spell.ActionID)
 	}
+	if sim.EventLog != nil && !spell.Flags.Matches(SpellFlagNoLogs) {
+		spell.logCastEvent(sim, proto.CombatLogEventType_CombatLogEventTypeCastStart, target, spell.DefaultCast.Cost, 0)
+		spell.logCastEvent(sim, proto.CombatLogEventType_CombatLogEventTypeCastComplete, target, 0, 0)
+	}
 	spell.applyEffects(sim, target)
 }
 
@@ -418,9 +429,8 @@ func (spell *Spell) applyEffects(sim *Simulation, target *Unit) {
 }
 
 func (spell *Spell) ApplyAOEThreatIgnoreMultipliers(threatAmount float64) {
-	numTargets := spell.Unit.Env.GetNumTargets()
-	for i := int32(0); i < numTargets; i++ {
-		spell.SpellMetrics[i].TotalThreat += threatAmount
+	for _, target := range spell.Unit.Env.Encounter.ActiveTargets {
+		spell.SpellMetrics[target.UnitIndex].TotalThreat += threatAmount
 	}
 }
 func (spell *Spell) ApplyAOEThreat(threatAmount float64) {
@@ -479,12 +489,18 @@ func ApplyEffectFuncDamageMultiple(baseEffects []SpellEffect) ApplySpellEffects
 	return func(sim *Simulation, _ *Unit, spell *Spell) {
 		for i := range baseEffects {
 			effect := &baseEffects[i]
+			if sim.Encounter.IsInactiveTarget(effect.Target) {
+				continue
+			}
 			effect.Damage = effect.calculateBaseDamage(sim, spell)
 			attackTable := spell.Unit.AttackTables[effect.Target.UnitIndex]
 			effect.calcDamageSingle(sim, spell, attackTable)
 		}
 		for i := range baseEffects {
 			effect := &baseEffects[i]
+			if sim.Encounter.IsInactiveTarget(effect.Target) {
+				continue
+			}
 			effect.finalize(sim, spell)
 		}
 	}
@@ -526,6 +542,41 @@ func ApplyEffectFuncAOEDamage(env *Environment, baseEffect SpellEffect) ApplySpe
 	return ApplyEffectFuncDamageMultiple(effects)
 }
 
+// Like ApplyEffectFuncAOEDamage, but only hits the first maxHits active targets.
+func ApplyEffectFuncAOEDamageMaxHits(env *Environment, baseEffect SpellEffect, maxHits int32) ApplySpellEffects {
+	numTargets := env.GetNumTargets()
+	if maxHits >= numTargets {
+		return ApplyEffectFuncAOEDamage(env, baseEffect)
+	}
+
+	baseEffect.Validate()
+	effects := make([]SpellEffect, numTargets)
+	for i := int32(0); i < numTargets; i++ {
+		effects[i] = baseEffect
+		effects[i].Target = &env.GetTarget(i).Unit
+	}
+
+	hits := make([]*SpellEffect, 0, maxHits)
+	return func(sim *Simulation, _ *Unit, spell *Spell) {
+		hits = hits[:0]
+		for _, target := range sim.Encounter.ActiveTargets {
+			if len(hits) == int(maxHits) {
+				break
+			}
+			hits = append(hits, &effects[target.Index])
+		}
+
+		for _, effect := range hits {
+			effect.Damage = effect.calculateBaseDamage(sim, spell)
+			attackTable := spell.Unit.AttackTables[effect.Target.UnitIndex]
+			effect.calcDamageSingle(sim, spell, attackTable)
+		}
+		for _, effect := range hits {
+			effect.finalize(sim, spell)
+		}
+	}
+}
+
 func ApplyEffectFuncDot(dot *Dot) ApplySpellEffects {
 	return func(sim *Simulation, _ *Unit, _ *Spell) {
 		dot.Apply(sim)
@@ -591,16 +642,32 @@ func ApplyEffectFuncAOEDamageCappedWithDeferredEffects(env *Environment, baseEff
 	return ApplyEffectFuncMultipleDamageCapped(baseEffects, true)
 }
 
+// Returns the number of effects whose target can currently be attacked.
+func numActiveEffects(sim *Simulation, baseEffects []SpellEffect) int {
+	if len(sim.Encounter.ActiveTargets) == len(sim.Encounter.Targets) {
+		return len(baseEffects)
+	}
+	numActive := 0
+	for i := range baseEffects {
+		if !sim.Encounter.IsInactiveTarget(baseEffects[i].Target) {
+			numActive++
+		}
+	}
+	return numActive
+}
+
 func ApplyEffectFuncMultipleDamageCapped(baseEffects []SpellEffect, deferFinalization bool) ApplySpellEffects {
-	numTargets := len(baseEffects)
 	for _, effect := range baseEffects {
 		effect.Validate()
 	}
 
 	return func(sim *Simulation, _ *Unit, spell *Spell) {
-		capMultiplier := math.Min(10.0/float64(numTargets), 1.0)
+		capMultiplier := math.Min(10.0/float64(numActiveEffects(sim, baseEffects)), 1.0)
 		for i := range baseEffects {
 			effect := &baseEffects[i]
+			if sim.Encounter.IsInactiveTarget(effect.Target) {
+				continue
+			}
 			attackTable := spell.Unit.AttackTables[effect.Target.UnitIndex]
 			effect.Damage = effect.calculateBaseDamage(sim, spell)
 			effect.Damage *= capMultiplier
@@ -615,6 +682,9 @@ func ApplyEffectFuncMultipleDamageCapped(baseEffects []SpellEffect, deferFinaliz
 		if deferFinalization {
 			for i := range baseEffects {
 				effect := &baseEffects[i]
+				if sim.Encounter.IsInactiveTarget(effect.Target) {
+					continue
+				}
 				effect.finalize(sim, spell)
 			}
 		}
@@ -650,17 +720,22 @@ func ApplyEffectFuncWithOutcome(baseEffects []SpellEffect, onOutcome func(*Simul
 			effect.Validate()
 		}
 		return func(sim *Simulation, _ *Unit, spell *Spell) {
-			capMultiplier := math.Min(10.0/float64(len(baseEffects)), 1.0)
+			capMultiplier := math.Min(10.0/float64(numActiveEffects(sim, baseEffects)), 1.0)
+			firstHit := true
 			for i := range baseEffects {
 				effect := &baseEffects[i]
+				if sim.Encounter.IsInactiveTarget(effect.Target) {
+					continue
+				}
 				attackTable := spell.Unit.AttackTables[effect.Target.UnitIndex]
 				effect.Damage = effect.calculateBaseDamage(sim, spell)
 				effect.Damage *= capMultiplier
 				effect.applyAttackerModifiers(sim, spell)
 				effect.applyResistances(sim, spell, attackTable)
 				effect.OutcomeApplier(sim, spell, effect, attackTable)
-				if i == 0 {
+				if firstHit {
 					onOutcome(sim, effect.Outcome)
+					firstHit = false
 				}
 				effect.applyTargetModifiers(spell, attackTable)
 				effect.finalize(sim, spell)
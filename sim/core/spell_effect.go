@@ -5,6 +5,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
 )
 
@@ -228,12 +229,15 @@ func (spell *Spell) DealDamage(sim *Simulation, result *SpellEffect) {
 	// Mark total damage done in raid so far for health based fights.
 	// Don't include damage done by EnemyUnits to Players
 	if result.Target.Type == EnemyUnit {
-		sim.Encounter.DamageTaken += result.Damage
+		sim.Encounter.onTargetDamaged(sim, spell.Unit, result.Target, result.Damage)
 	}
 
 	if sim.Log != nil {
 		spell.Unit.Log(sim, "%s %s %s. (Threat: %0.3f)", result.Target.LogLabel(), spell.ActionID, result, result.calcThreat(spell))
 	}
+	if sim.EventLog != nil {
+		spell.logResultEvent(sim, proto.CombatLogEventType_CombatLogEventTypeDamage, result)
+	}
 
 	spell.Unit.OnSpellHitDealt(sim, spell, result)
 	result.Target.OnSpellHitTaken(sim, spell, result)
@@ -264,6 +268,9 @@ func (spell *Spell) DealHealing(sim *Simulation, result *SpellEffect) {
 	if sim.Log != nil {
 		spell.Unit.Log(sim, "%s %s %s. (Threat: %0.3f)", result.Target.LogLabel(), spell.ActionID, result, result.calcThreat(spell))
 	}
+	if sim.EventLog != nil {
+		spell.logResultEvent(sim, proto.CombatLogEventType_CombatLogEventTypeHealing, result)
+	}
 
 	spell.Unit.OnHealDealt(sim, spell, result)
 	result.Target.OnHealTaken(sim, spell, result)
@@ -351,7 +358,7 @@ func (spellEffect *SpellEffect) finalizeInternal(sim *Simulation, spell *Spell)
 	// Mark total damage done in raid so far for health based fights.
 	// Don't include damage done by EnemyUnits to Players
 	if spellEffect.Target.Type == EnemyUnit {
-		sim.Encounter.DamageTaken += spellEffect.Damage
+		sim.Encounter.onTargetDamaged(sim, spell.Unit, spellEffect.Target, spellEffect.Damage)
 	}
 
 	if sim.Log != nil {
@@ -361,6 +368,9 @@ func (spellEffect *SpellEffect) finalizeInternal(sim *Simulation, spell *Spell)
 			spell.Unit.Log(sim, "%s %s %s. (Threat: %0.3f)", spellEffect.Target.LogLabel(), spell.ActionID, spellEffect, spellEffect.calcThreat(spell))
 		}
 	}
+	if sim.EventLog != nil {
+		spell.logResultEvent(sim, proto.CombatLogEventType_CombatLogEventTypeDamage, spellEffect)
+	}
 
 	if !spellEffect.IsPeriodic {
 		if spellEffect.OnSpellHitDealt != nil {
@@ -391,6 +401,9 @@ func (spellEffect *SpellEffect) finalizeHealingInternal(sim *Simulation, spell *
 			spell.Unit.Log(sim, "%s %s %s. (Threat: %0.3f)", spellEffect.Target.LogLabel(), spell.ActionID, spellEffect, spellEffect.calcThreat(spell))
 		}
 	}
+	if sim.EventLog != nil {
+		spell.logResultEvent(sim, proto.CombatLogEventType_CombatLogEventTypeHealing, spellEffect)
+	}
 
 	if !spellEffect.IsPeriodic {
 		if spellEffect.OnSpellHitDealt != nil {
@@ -436,19 +436,27 @@ func (spell *Spell) CalcAndDealDamageRangedCritOnly(sim *Simulation, target *Uni
 	spell.DealDamage(sim, &result)
 }
 
+func (spell *Spell) OutcomeEnemyMeleeWhite(sim *Simulation, result *SpellEffect, attackTable *AttackTable) {
+	unit := spell.Unit
+	roll := sim.RandomFloat("Enemy White Hit Table")
+	chance := 0.0
+
+	if !result.applyEnemyAttackTableMiss(spell, unit, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableDodge(spell, unit, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableParry(spell, unit, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableBlock(spell, unit, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableCrit(spell, unit, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableCrush(spell, unit, attackTable, roll, &chance) {
+		result.applyAttackTableHit(spell)
+	}
+}
+func (spell *Spell) CalcAndDealDamageEnemyMeleeWhite(sim *Simulation, target *Unit, baseDamage float64) {
+	result := spell.CalcDamage(sim, target, baseDamage, spell.OutcomeEnemyMeleeWhite)
+	spell.DealDamage(sim, &result)
+}
 func (unit *Unit) OutcomeFuncEnemyMeleeWhite() OutcomeApplier {
 	return func(sim *Simulation, spell *Spell, spellEffect *SpellEffect, attackTable *AttackTable) {
-		unit := spell.Unit
-		roll := sim.RandomFloat("Enemy White Hit Table")
-		chance := 0.0
-
-		if !spellEffect.applyEnemyAttackTableMiss(spell, unit, attackTable, roll, &chance) &&
-			!spellEffect.applyEnemyAttackTableDodge(spell, unit, attackTable, roll, &chance) &&
-			!spellEffect.applyEnemyAttackTableParry(spell, unit, attackTable, roll, &chance) &&
-			!spellEffect.applyEnemyAttackTableBlock(spell, unit, attackTable, roll, &chance) &&
-			!spellEffect.applyEnemyAttackTableCrit(spell, unit, attackTable, roll, &chance) {
-			spellEffect.applyAttackTableHit(spell)
-		}
+		spell.OutcomeEnemyMeleeWhite(sim, spellEffect, attackTable)
 	}
 }
 
@@ -662,7 +670,7 @@ func (spellEffect *SpellEffect) applyEnemyAttackTableCrit(spell *Spell, unit *Un
 		panic("Spell " + spell.ActionID.String() + " missing CritMultiplier")
 	}
 	critRating := unit.stats[stats.MeleeCrit] + spell.BonusCritRating
-	critChance := critRating / (CritRatingPerCritChance * 100)
+	critChance := critRating/(CritRatingPerCritChance*100) + attackTable.BaseCritChance
 	critChance -= spellEffect.Target.stats[stats.Defense] * DefenseRatingToChanceReduction
 	critChance -= spellEffect.Target.stats[stats.Resilience] / ResilienceRatingPerCritReductionChance / 100
 	critChance -= spellEffect.Target.PseudoStats.ReducedCritTakenChance
@@ -677,3 +685,18 @@ func (spellEffect *SpellEffect) applyEnemyAttackTableCrit(spell *Spell, unit *Un
 	}
 	return false
 }
+
+func (spellEffect *SpellEffect) applyEnemyAttackTableCrush(spell *Spell, unit *Unit, attackTable *AttackTable, roll float64, chance *float64) bool {
+	if attackTable.BaseCrushChance == 0 {
+		return false
+	}
+	*chance += attackTable.BaseCrushChance
+
+	if roll < *chance {
+		spellEffect.Outcome = OutcomeCrush
+		spell.SpellMetrics[spellEffect.Target.UnitIndex].Crushes++
+		spellEffect.Damage *= 1.5
+		return true
+	}
+	return false
+}
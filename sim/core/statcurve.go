@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Limit on the number of sims in a stat curve, so a bad step can't start
+// millions of them.
+const maxStatCurvePoints = 2500
+
+// Number of points a stat curve request sims, or 0 if its ranges are invalid.
+func StatCurvePoints(request *proto.StatCurveRequest) int {
+	_, numPoints, errorResult := statCurveAxes(request.Ranges)
+	if errorResult != "" {
+		return 0
+	}
+	return numPoints
+}
+
+// Returns the values of each stat range, and the number of points in their grid.
+func statCurveAxes(ranges []*proto.StatRange) ([][]float64, int, string) {
+	if len(ranges) == 0 || len(ranges) > 2 {
+		return nil, 0, "Stat curves need one or two stat ranges"
+	}
+
+	var axes [][]float64
+	numPoints := 1
+	for _, statRange := range ranges {
+		if statRange.Step <= 0 || statRange.Max < statRange.Min {
+			return nil, 0, fmt.Sprintf("Invalid range for %s", statRange.Stat)
+		}
+		numValues := int(math.Floor((statRange.Max-statRange.Min)/statRange.Step+1e-6)) + 1
+		numPoints *= numValues
+		if numValues > maxStatCurvePoints || numPoints > maxStatCurvePoints {
+			return nil, 0, fmt.Sprintf("Too many points in stat curve, at most %d are allowed", maxStatCurvePoints)
+		}
+
+		values := make([]float64, numValues)
+		for i := range values {
+			values[i] = statRange.Min + float64(i)*statRange.Step
+		}
+		axes = append(axes, values)
+	}
+	return axes, numPoints, ""
+}
+
+// Sims the player at every point of the requested stat ranges. All points use
+// the same random seeds, so differences between neighbouring points aren't
+// drowned out by noise.
+func CalcStatCurve(ctx context.Context, request *proto.StatCurveRequest, progress chan *proto.ProgressMetrics) *proto.StatCurveResult {
+	axes, numPoints, errorResult := statCurveAxes(request.Ranges)
+	if errorResult != "" {
+		return &proto.StatCurveResult{ErrorResult: errorResult}
+	}
+
+	player := googleProto.Clone(request.Player).(*proto.Player)
+	if player.BonusStats == nil {
+		player.BonusStats = make([]float64, stats.Len)
+	}
+	raidProto := SinglePlayerRaidProto(player, request.PartyBuffs, request.RaidBuffs, request.Debuffs)
+	raidProto.Tanks = request.Tanks
+
+	// Bonus stats needed to reach each value depend on stat multipliers, so
+	// measure how much of each bonus point shows up in the final stats.
+	baseStats := statCurveFinalStats(raidProto)
+	var bonusScales []float64
+	for _, statRange := range request.Ranges {
+		stat := stats.Stat(statRange.Stat)
+		const probe = 1000.0
+		probeRaid := googleProto.Clone(raidProto).(*proto.Raid)
+		probeRaid.Parties[0].Players[0].BonusStats[stat] += probe
+		scale := (statCurveFinalStats(probeRaid)[stat] - baseStats[stat]) / probe
+		if scale <= 0 {
+			return &proto.StatCurveResult{ErrorResult: fmt.Sprintf("Stat %s can't be changed with bonus stats", statRange.Stat)}
+		}
+		bonusScales = append(bonusScales, scale)
+	}
+
+	baseSimRequest := &proto.RaidSimRequest{
+		Raid:       raidProto,
+		Encounter:  request.Encounter,
+		SimOptions: request.SimOptions,
+	}
+	baseSimRequest = googleProto.Clone(baseSimRequest).(*proto.RaidSimRequest)
+	simOptions := baseSimRequest.SimOptions
+	if simOptions.RandomSeed == 0 {
+		simOptions.RandomSeed = time.Now().UnixNano()
+	}
+
+	points := make([]*proto.StatCurvePoint, numPoints)
+	for i := range points {
+		point := &proto.StatCurvePoint{}
+		remainder := i
+		for axis := len(axes) - 1; axis >= 0; axis-- {
+			point.StatValues = append([]float64{axes[axis][remainder%len(axes[axis])]}, point.StatValues...)
+			remainder /= len(axes[axis])
+		}
+		points[i] = point
+	}
+
+	// Cancelled on the first failed point, so the remaining points aren't simmed.
+	simCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mutex sync.Mutex
+	simsCompleted := 0
+
+	runPoint := func(point *proto.StatCurvePoint) {
+		simRequest := googleProto.Clone(baseSimRequest).(*proto.RaidSimRequest)
+		bonusStats := simRequest.Raid.Parties[0].Players[0].BonusStats
+		for axis, statRange := range request.Ranges {
+			stat := stats.Stat(statRange.Stat)
+			bonusStats[stat] += (point.StatValues[axis] - baseStats[stat]) / bonusScales[axis]
+		}
+
+		result := RunSimWithContext(simCtx, *simRequest, nil)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if result.ErrorResult != "" {
+			if errorResult == "" {
+				errorResult = result.ErrorResult
+				cancel()
+			}
+			return
+		}
+		metrics := result.RaidMetrics.Parties[0].Players[0]
+		point.Dps = newStatCurveValue(metrics.Dps, simOptions.Iterations)
+		point.Tps = newStatCurveValue(metrics.Threat, simOptions.Iterations)
+		point.Dtps = newStatCurveValue(metrics.Dtps, simOptions.Iterations)
+
+		simsCompleted++
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				TotalIterations:     int32(numPoints) * simOptions.Iterations,
+				CompletedIterations: int32(simsCompleted) * simOptions.Iterations,
+				CompletedSims:       int32(simsCompleted),
+				TotalSims:           int32(numPoints),
+			}
+		}
+	}
+
+	pointsToRun := make(chan *proto.StatCurvePoint, numPoints)
+	for _, point := range points {
+		pointsToRun <- point
+	}
+	close(pointsToRun)
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < MinInt(runtime.NumCPU(), numPoints); i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for point := range pointsToRun {
+				if simCtx.Err() != nil {
+					return
+				}
+				runPoint(point)
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	if ctx.Err() != nil {
+		return &proto.StatCurveResult{ErrorResult: "Stat curve cancelled: " + ctx.Err().Error()}
+	}
+	if errorResult != "" {
+		return &proto.StatCurveResult{ErrorResult: errorResult}
+	}
+	return &proto.StatCurveResult{Points: points}
+}
+
+func statCurveFinalStats(raidProto *proto.Raid) stats.Stats {
+	result := ComputeStats(&proto.ComputeStatsRequest{Raid: raidProto})
+	return stats.FromFloatArray(result.RaidStats.Parties[0].Players[0].FinalStats)
+}
+
+func newStatCurveValue(metrics *proto.DistributionMetrics, iterations int32) *proto.StatCurveValue {
+	margin := 1.96 * metrics.Stdev / math.Sqrt(float64(iterations))
+	return &proto.StatCurveValue{
+		Avg:   metrics.Avg,
+		Stdev: metrics.Stdev,
+		Lower: metrics.Avg - margin,
+		Upper: metrics.Avg + margin,
+	}
+}
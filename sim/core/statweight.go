@@ -1,9 +1,11 @@
 package core
 
 import (
+	"context"
 	"math"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
@@ -12,6 +14,9 @@ import (
 
 const DTPSReferenceStat = stats.Armor
 
+// Default for StatWeightsRequest.max_iterations.
+const defaultMaxStatWeightIterations = 100000
+
 type StatWeightValues struct {
 	Weights       stats.Stats
 	WeightsStdev  stats.Stats
@@ -33,18 +38,26 @@ type StatWeightsResult struct {
 	Hps  StatWeightValues
 	Tps  StatWeightValues
 	Dtps StatWeightValues
+
+	Iterations int32 // Iterations run by each stat sim.
+
+	ErrorResult string
 }
 
 func (swr StatWeightsResult) ToProto() *proto.StatWeightsResult {
 	return &proto.StatWeightsResult{
 		Dps:  swr.Dps.ToProto(),
-		Hps:  swr.Dps.ToProto(),
+		Hps:  swr.Hps.ToProto(),
 		Tps:  swr.Tps.ToProto(),
 		Dtps: swr.Dtps.ToProto(),
+
+		Iterations: swr.Iterations,
+
+		ErrorResult: swr.ErrorResult,
 	}
 }
 
-func CalcStatWeight(swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, referenceStat stats.Stat, progress chan *proto.ProgressMetrics) StatWeightsResult {
+func CalcStatWeight(ctx context.Context, swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, referenceStat stats.Stat, progress chan *proto.ProgressMetrics) StatWeightsResult {
 	if swr.Player.BonusStats == nil {
 		swr.Player.BonusStats = make([]float64, stats.Len)
 	}
@@ -59,15 +72,156 @@ func CalcStatWeight(swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, ref
 	})
 	baseStats := baseStatsResult.RaidStats.Parties[0].Players[0].FinalStats
 
+	// Melee hit cap is 8% in WoTLK
+	melee2HHitCap := 8 * MeleeHitRatingPerHitChance
+	// Spell hit cap is 17% in WoTLK
+	spellHitCap := 17 * SpellHitRatingPerHitChance
+	if swr.Debuffs != nil && (swr.Debuffs.Misery || swr.Debuffs.FaerieFire == proto.TristateEffect_TristateEffectImproved) {
+		spellHitCap -= 3 * SpellHitRatingPerHitChance
+	}
+
+	const defaultStatMod = 50.0
+	const meleeHitStatMod = MeleeHitRatingPerHitChance * 0.5
+	const spellHitStatMod = SpellHitRatingPerHitChance * 0.5
+	statModsLow := stats.Stats{}
+	statModsHigh := stats.Stats{}
+
+	// Make sure reference stat is included.
+	statModsLow[referenceStat] = defaultStatMod
+	statModsHigh[referenceStat] = defaultStatMod
+
+	for _, stat := range statsToWeigh {
+		statMod := defaultStatMod
+		if stat == stats.SpellHit {
+			statMod = spellHitStatMod
+			if baseStats[stat] < spellHitCap && baseStats[stat]+statMod > spellHitCap {
+				// Check that newMod is atleast half of the previous mod, or we introduce a lot of deviation in the weight calc
+				newMod := baseStats[stat] - spellHitCap
+				if newMod > 0.5*statMod {
+					statModsHigh[stat] = newMod
+					statModsLow[stat] = -newMod
+				} else {
+					// Otherwise we go the opposite way of cap
+					statModsHigh[stat] = -statMod
+					statModsLow[stat] = -statMod
+				}
+
+				continue
+			}
+		} else if stat == stats.MeleeHit {
+			statMod = meleeHitStatMod
+			if baseStats[stat] < melee2HHitCap && baseStats[stat]+statMod > melee2HHitCap {
+				// Check that newMod is atleast half of the previous mod, or we introduce a lot of deviation in the weight calc
+				newMod := baseStats[stat] - melee2HHitCap
+				if newMod > 0.5*statMod {
+					statModsHigh[stat] = newMod
+					statModsLow[stat] = -newMod
+				} else {
+					// Otherwise we go the opposite way of cap
+					statModsHigh[stat] = -statMod
+					statModsLow[stat] = -statMod
+				}
+				continue
+			}
+		}
+		statModsHigh[stat] = statMod
+		statModsLow[stat] = -statMod
+	}
+
 	baseSimRequest := &proto.RaidSimRequest{
 		Raid:       raidProto,
 		Encounter:  swr.Encounter,
 		SimOptions: simOptions,
 	}
-	baselineResult := RunRaidSim(baseSimRequest)
+	var resultLow, resultHigh StatWeightsResult
+	var errorResult string
+	iterations := simOptions.Iterations / 2
+	if swr.PairedIterations || swr.MaxWeightError > 0 {
+		resultLow, resultHigh, iterations, errorResult = calcPairedStatWeights(ctx, baseSimRequest, statModsLow, statModsHigh, swr.MaxWeightError, swr.MaxIterations, progress)
+	} else {
+		resultLow, resultHigh, errorResult = calcUnpairedStatWeights(ctx, baseSimRequest, statModsLow, statModsHigh, progress)
+	}
+	if errorResult != "" {
+		return StatWeightsResult{ErrorResult: errorResult}
+	}
+
+	for _, stat := range statsToWeigh {
+		// Check for hard caps.
+		if stat == stats.SpellHit || stat == stats.MeleeHit || stat == stats.Expertise {
+			if resultHigh.Dps.Weights[stat] < 0.1 {
+				statModsHigh[stat] = 0
+				continue
+			}
+		}
+
+		// For spell/melee hit, only use the direction facing away from the nearest soft/hard cap.
+		//
+		if stat == stats.SpellHit {
+			if baseStats[stat] >= spellHitCap {
+				statModsLow[stat] = statModsHigh[stat]
+				resultLow.Dps.Weights[stat] = resultHigh.Dps.Weights[stat]
+				resultLow.Hps.Weights[stat] = resultHigh.Hps.Weights[stat]
+				resultLow.Tps.Weights[stat] = resultHigh.Tps.Weights[stat]
+				resultLow.Dtps.Weights[stat] = resultHigh.Dtps.Weights[stat]
+			}
+		} else if stat == stats.MeleeHit {
+			if baseStats[stat] >= melee2HHitCap {
+				statModsLow[stat] = statModsHigh[stat]
+				resultLow.Dps.Weights[stat] = resultHigh.Dps.Weights[stat]
+				resultLow.Hps.Weights[stat] = resultHigh.Hps.Weights[stat]
+				resultLow.Tps.Weights[stat] = resultHigh.Tps.Weights[stat]
+				resultLow.Dtps.Weights[stat] = resultHigh.Dtps.Weights[stat]
+			}
+		}
+	}
+
+	result := StatWeightsResult{Iterations: iterations}
+	for statIdx, _ := range statModsLow {
+		stat := stats.Stat(statIdx)
+		if statModsLow[stat] == 0 || statModsHigh[stat] == 0 {
+			continue
+		}
+
+		result.Dps.Weights[stat] = (resultLow.Dps.Weights[stat] + resultHigh.Dps.Weights[stat]) / 2
+		result.Hps.Weights[stat] = (resultLow.Hps.Weights[stat] + resultHigh.Hps.Weights[stat]) / 2
+		result.Tps.Weights[stat] = (resultLow.Tps.Weights[stat] + resultHigh.Tps.Weights[stat]) / 2
+		result.Dtps.Weights[stat] = (resultLow.Dtps.Weights[stat] + resultHigh.Dtps.Weights[stat]) / 2
+
+		result.Dps.WeightsStdev[stat] = (resultLow.Dps.WeightsStdev[stat] + resultHigh.Dps.WeightsStdev[stat]) / 2
+		result.Hps.WeightsStdev[stat] = (resultLow.Hps.WeightsStdev[stat] + resultHigh.Hps.WeightsStdev[stat]) / 2
+		result.Tps.WeightsStdev[stat] = (resultLow.Tps.WeightsStdev[stat] + resultHigh.Tps.WeightsStdev[stat]) / 2
+		result.Dtps.WeightsStdev[stat] = (resultLow.Dtps.WeightsStdev[stat] + resultHigh.Dtps.WeightsStdev[stat]) / 2
+	}
+
+	for statIdx, _ := range statModsLow {
+		stat := stats.Stat(statIdx)
+		if statModsLow[stat] == 0 || statModsHigh[stat] == 0 {
+			continue
+		}
+
+		result.Dps.EpValues[stat] = result.Dps.Weights[stat] / result.Dps.Weights[referenceStat]
+		result.Dps.EpValuesStdev[stat] = result.Dps.WeightsStdev[stat] / math.Abs(result.Dps.Weights[referenceStat])
+
+		result.Hps.EpValues[stat] = result.Hps.Weights[stat] / result.Hps.Weights[referenceStat]
+		result.Hps.EpValuesStdev[stat] = result.Hps.WeightsStdev[stat] / math.Abs(result.Hps.Weights[referenceStat])
+
+		result.Tps.EpValues[stat] = result.Tps.Weights[stat] / result.Tps.Weights[referenceStat]
+		result.Tps.EpValuesStdev[stat] = result.Tps.WeightsStdev[stat] / math.Abs(result.Tps.Weights[referenceStat])
+
+		if result.Dtps.Weights[DTPSReferenceStat] != 0 {
+			result.Dtps.EpValues[stat] = result.Dtps.Weights[stat] / result.Dtps.Weights[DTPSReferenceStat]
+			result.Dtps.EpValuesStdev[stat] = result.Dtps.WeightsStdev[stat] / math.Abs(result.Dtps.Weights[DTPSReferenceStat])
+		}
+	}
+
+	return result
+}
+
+// Runs each stat sim with half the iterations independently from the baseline.
+func calcUnpairedStatWeights(ctx context.Context, baseSimRequest *proto.RaidSimRequest, statModsLow stats.Stats, statModsHigh stats.Stats, progress chan *proto.ProgressMetrics) (resultLow StatWeightsResult, resultHigh StatWeightsResult, errorResult string) {
+	baselineResult := RunSimWithContext(ctx, *baseSimRequest, nil)
 	if baselineResult.ErrorResult != "" {
-		// TODO: get stack trace out.
-		return StatWeightsResult{}
+		return StatWeightsResult{}, StatWeightsResult{}, baselineResult.ErrorResult
 	}
 	baselineDpsMetrics := baselineResult.RaidMetrics.Parties[0].Players[0].Dps
 	baselineHpsMetrics := baselineResult.RaidMetrics.Parties[0].Players[0].Hps
@@ -77,8 +231,6 @@ func CalcStatWeight(swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, ref
 	var waitGroup sync.WaitGroup
 
 	// Do half the iterations with a positive, and half with a negative value for better accuracy.
-	resultLow := StatWeightsResult{}
-	resultHigh := StatWeightsResult{}
 	dpsHistsLow := [stats.Len]map[int32]int32{}
 	dpsHistsHigh := [stats.Len]map[int32]int32{}
 	hpsHistsLow := [stats.Len]map[int32]int32{}
@@ -101,7 +253,7 @@ func CalcStatWeight(swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, ref
 		simRequest.SimOptions.Iterations /= 2 // Cut in half since we're doing above and below separately.
 
 		reporter := make(chan *proto.ProgressMetrics, 10)
-		go RunSim(*simRequest, reporter) // RunRaidSim(simRequest)
+		go RunSimWithContext(ctx, *simRequest, reporter)
 
 		var localIterations int32
 		var errorStr string
@@ -135,6 +287,10 @@ func CalcStatWeight(swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, ref
 		}
 		// TODO: get stack trace out if final result error is set.
 		if errorStr != "" {
+			if ctx.Err() != nil {
+				// Cancelled, the caller will return an error result once all sims stop.
+				return
+			}
 			panic("Stat weights error: " + errorStr)
 		}
 		dpsMetrics := simResult.RaidMetrics.Parties[0].Players[0].Dps
@@ -161,7 +317,7 @@ func CalcStatWeight(swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, ref
 			dtpsHistsLow[stat] = dtpsMetrics.Hist
 		} else {
 			resultHigh.Dps.Weights[stat] = dpsDiff
-			resultHigh.Hps.Weights[stat] = tpsDiff
+			resultHigh.Hps.Weights[stat] = hpsDiff
 			resultHigh.Tps.Weights[stat] = tpsDiff
 			resultHigh.Dtps.Weights[stat] = dtpsDiff
 			resultHigh.Dps.WeightsStdev[stat] = dpsMetrics.Stdev / math.Abs(value)
@@ -175,68 +331,12 @@ func CalcStatWeight(swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, ref
 		}
 	}
 
-	// Melee hit cap is 8% in WoTLK
-	melee2HHitCap := 8 * MeleeHitRatingPerHitChance
-	// Spell hit cap is 17% in WoTLK
-	spellHitCap := 17 * SpellHitRatingPerHitChance
-	if swr.Debuffs != nil && (swr.Debuffs.Misery || swr.Debuffs.FaerieFire == proto.TristateEffect_TristateEffectImproved) {
-		spellHitCap -= 3 * SpellHitRatingPerHitChance
-	}
-
-	const defaultStatMod = 50.0
-	const meleeHitStatMod = MeleeHitRatingPerHitChance * 0.5
-	const spellHitStatMod = SpellHitRatingPerHitChance * 0.5
-	statModsLow := stats.Stats{}
-	statModsHigh := stats.Stats{}
-
-	// Make sure reference stat is included.
-	statModsLow[referenceStat] = defaultStatMod
-	statModsHigh[referenceStat] = defaultStatMod
-
-	for _, stat := range statsToWeigh {
-		statMod := defaultStatMod
-		if stat == stats.SpellHit {
-			statMod = spellHitStatMod
-			if baseStats[stat] < spellHitCap && baseStats[stat]+statMod > spellHitCap {
-				// Check that newMod is atleast half of the previous mod, or we introduce a lot of deviation in the weight calc
-				newMod := baseStats[stat] - spellHitCap
-				if newMod > 0.5*statMod {
-					statModsHigh[stat] = newMod
-					statModsLow[stat] = -newMod
-				} else {
-					// Otherwise we go the opposite way of cap
-					statModsHigh[stat] = -statMod
-					statModsLow[stat] = -statMod
-				}
-
-				continue
-			}
-		} else if stat == stats.MeleeHit {
-			statMod = meleeHitStatMod
-			if baseStats[stat] < melee2HHitCap && baseStats[stat]+statMod > melee2HHitCap {
-				// Check that newMod is atleast half of the previous mod, or we introduce a lot of deviation in the weight calc
-				newMod := baseStats[stat] - melee2HHitCap
-				if newMod > 0.5*statMod {
-					statModsHigh[stat] = newMod
-					statModsLow[stat] = -newMod
-				} else {
-					// Otherwise we go the opposite way of cap
-					statModsHigh[stat] = -statMod
-					statModsLow[stat] = -statMod
-				}
-				continue
-			}
-		}
-		statModsHigh[stat] = statMod
-		statModsLow[stat] = -statMod
-	}
-
 	for stat, _ := range statModsLow {
 		if statModsLow[stat] == 0 {
 			continue
 		}
 		waitGroup.Add(2)
-		atomic.AddInt32(&iterationsTotal, swr.SimOptions.Iterations)
+		atomic.AddInt32(&iterationsTotal, baseSimRequest.SimOptions.Iterations)
 		atomic.AddInt32(&simsTotal, 2)
 
 		go doStat(stats.Stat(stat), statModsLow[stat], true)
@@ -244,75 +344,173 @@ func CalcStatWeight(swr proto.StatWeightsRequest, statsToWeigh []stats.Stat, ref
 	}
 
 	waitGroup.Wait()
+	if ctx.Err() != nil {
+		return resultLow, resultHigh, "Stat weights cancelled: " + ctx.Err().Error()
+	}
+	return resultLow, resultHigh, ""
+}
 
-	for _, stat := range statsToWeigh {
-		// Check for hard caps.
-		if stat == stats.SpellHit || stat == stats.MeleeHit || stat == stats.Expertise {
-			if resultHigh.Dps.Weights[stat] < 0.1 {
-				statModsHigh[stat] = 0
-				continue
-			}
-		}
+// Running totals of the per-iteration weights from one stat sim, each paired
+// with the baseline iteration which used the same seed.
+type pairedWeights struct {
+	sum        float64
+	sumSquared float64
+	count      int
+}
 
-		// For spell/melee hit, only use the direction facing away from the nearest soft/hard cap.
-		//
-		if stat == stats.SpellHit {
-			if baseStats[stat] >= spellHitCap {
-				statModsLow[stat] = statModsHigh[stat]
-				resultLow.Dps.Weights[stat] = resultHigh.Dps.Weights[stat]
-				resultLow.Hps.Weights[stat] = resultHigh.Hps.Weights[stat]
-				resultLow.Tps.Weights[stat] = resultHigh.Tps.Weights[stat]
-				resultLow.Dtps.Weights[stat] = resultHigh.Dtps.Weights[stat]
-			}
-		} else if stat == stats.MeleeHit {
-			if baseStats[stat] >= melee2HHitCap {
-				statModsLow[stat] = statModsHigh[stat]
-				resultLow.Dps.Weights[stat] = resultHigh.Dps.Weights[stat]
-				resultLow.Hps.Weights[stat] = resultHigh.Hps.Weights[stat]
-				resultLow.Tps.Weights[stat] = resultHigh.Tps.Weights[stat]
-				resultLow.Dtps.Weights[stat] = resultHigh.Dtps.Weights[stat]
-			}
-		}
+func (pw *pairedWeights) add(weight float64) {
+	pw.sum += weight
+	pw.sumSquared += weight * weight
+	pw.count++
+}
+
+func (pw *pairedWeights) mean() float64 {
+	return pw.sum / float64(pw.count)
+}
+
+func (pw *pairedWeights) stdev() float64 {
+	mean := pw.mean()
+	return math.Sqrt(MaxFloat(0, pw.sumSquared/float64(pw.count)-mean*mean))
+}
+
+// Standard error of the mean.
+func (pw *pairedWeights) stdErr() float64 {
+	return pw.stdev() / math.Sqrt(float64(pw.count))
+}
+
+// Dps, Hps, Tps and Dtps distributions of the player whose stats are weighed.
+func statWeightDistributions(result *proto.RaidSimResult) [4]*proto.DistributionMetrics {
+	player := result.RaidMetrics.Parties[0].Players[0]
+	return [4]*proto.DistributionMetrics{player.Dps, player.Hps, player.Threat, player.Dtps}
+}
+
+// Runs every stat sim with the same seeds as the baseline, in batches of half
+// the requested iterations. Without max_weight_error, this is a single batch.
+func calcPairedStatWeights(ctx context.Context, baseSimRequest *proto.RaidSimRequest, statModsLow stats.Stats, statModsHigh stats.Stats, maxWeightError float64, maxIterations int32, progress chan *proto.ProgressMetrics) (resultLow StatWeightsResult, resultHigh StatWeightsResult, iterations int32, errorResult string) {
+	baseSimRequest = googleProto.Clone(baseSimRequest).(*proto.RaidSimRequest)
+	baseSimRequest.SimOptions.SaveAllValues = true
+	seed := baseSimRequest.SimOptions.RandomSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
 
-	result := StatWeightsResult{}
-	for statIdx, _ := range statModsLow {
-		stat := stats.Stat(statIdx)
-		if statModsLow[stat] == 0 || statModsHigh[stat] == 0 {
+	batchSize := MaxInt32(1, baseSimRequest.SimOptions.Iterations/2)
+	if maxIterations <= 0 {
+		maxIterations = defaultMaxStatWeightIterations
+	}
+
+	var simRequests []*proto.RaidSimRequest
+	var simStats []stats.Stat
+	var simValues []float64
+	var simIsLow []bool
+	for stat := range statModsLow {
+		if statModsLow[stat] == 0 {
 			continue
 		}
+		for _, isLow := range []bool{true, false} {
+			value := statModsHigh[stat]
+			if isLow {
+				value = statModsLow[stat]
+			}
+			simRequest := googleProto.Clone(baseSimRequest).(*proto.RaidSimRequest)
+			simRequest.Raid.Parties[0].Players[0].BonusStats[stat] += value
+			simRequests = append(simRequests, simRequest)
+			simStats = append(simStats, stats.Stat(stat))
+			simValues = append(simValues, value)
+			simIsLow = append(simIsLow, isLow)
+		}
+	}
+	// The baseline runs alongside the stat sims in each batch.
+	simRequests = append(simRequests, baseSimRequest)
+	numStatSims := len(simRequests) - 1
 
-		result.Dps.Weights[stat] = (resultLow.Dps.Weights[stat] + resultHigh.Dps.Weights[stat]) / 2
-		result.Hps.Weights[stat] = (resultLow.Hps.Weights[stat] + resultHigh.Hps.Weights[stat]) / 2
-		result.Tps.Weights[stat] = (resultLow.Tps.Weights[stat] + resultHigh.Tps.Weights[stat]) / 2
-		result.Dtps.Weights[stat] = (resultLow.Dtps.Weights[stat] + resultHigh.Dtps.Weights[stat]) / 2
+	// Indexed by stat sim, then Dps/Hps/Tps/Dtps.
+	weights := make([][4]pairedWeights, numStatSims)
 
-		result.Dps.WeightsStdev[stat] = (resultLow.Dps.WeightsStdev[stat] + resultHigh.Dps.WeightsStdev[stat]) / 2
-		result.Hps.WeightsStdev[stat] = (resultLow.Hps.WeightsStdev[stat] + resultHigh.Hps.WeightsStdev[stat]) / 2
-		result.Tps.WeightsStdev[stat] = (resultLow.Tps.WeightsStdev[stat] + resultHigh.Tps.WeightsStdev[stat]) / 2
-		result.Dtps.WeightsStdev[stat] = (resultLow.Dtps.WeightsStdev[stat] + resultHigh.Dtps.WeightsStdev[stat]) / 2
-	}
+	var iterationsTotal int32
+	var iterationsDone int32
+	var simsTotal int32
+	var simsCompleted int32
 
-	for statIdx, _ := range statModsLow {
-		stat := stats.Stat(statIdx)
-		if statModsLow[stat] == 0 || statModsHigh[stat] == 0 {
-			continue
+	for iterations < maxIterations {
+		batchIterations := MinInt32(batchSize, maxIterations-iterations)
+		results := make([]*proto.RaidSimResult, len(simRequests))
+		iterationsTotal += batchIterations * int32(len(simRequests))
+		simsTotal += int32(len(simRequests))
+
+		var waitGroup sync.WaitGroup
+		for i, simRequest := range simRequests {
+			simRequest.SimOptions.Iterations = batchIterations
+			simRequest.SimOptions.RandomSeed = seed + int64(iterations)
+
+			waitGroup.Add(1)
+			go func(i int, simRequest *proto.RaidSimRequest) {
+				defer waitGroup.Done()
+				results[i] = RunSimWithContext(ctx, *simRequest, nil)
+				atomic.AddInt32(&iterationsDone, batchIterations)
+				atomic.AddInt32(&simsCompleted, 1)
+				if progress != nil {
+					progress <- &proto.ProgressMetrics{
+						TotalIterations:     iterationsTotal,
+						CompletedIterations: atomic.LoadInt32(&iterationsDone),
+						CompletedSims:       atomic.LoadInt32(&simsCompleted),
+						TotalSims:           simsTotal,
+					}
+				}
+			}(i, simRequest)
 		}
+		waitGroup.Wait()
 
-		result.Dps.EpValues[stat] = result.Dps.Weights[stat] / result.Dps.Weights[referenceStat]
-		result.Dps.EpValuesStdev[stat] = result.Dps.WeightsStdev[stat] / math.Abs(result.Dps.Weights[referenceStat])
+		if ctx.Err() != nil {
+			return resultLow, resultHigh, iterations, "Stat weights cancelled: " + ctx.Err().Error()
+		}
+		for _, result := range results {
+			if result.ErrorResult != "" {
+				return resultLow, resultHigh, iterations, "Stat weights error: " + result.ErrorResult
+			}
+		}
 
-		result.Hps.EpValues[stat] = result.Hps.Weights[stat] / result.Hps.Weights[referenceStat]
-		result.Hps.EpValuesStdev[stat] = result.Hps.WeightsStdev[stat] / math.Abs(result.Hps.Weights[referenceStat])
+		baseline := statWeightDistributions(results[numStatSims])
+		for i := 0; i < numStatSims; i++ {
+			distributions := statWeightDistributions(results[i])
+			for metric, distribution := range distributions {
+				sign := 1.0
+				if metric == 3 {
+					sign = -1 // Lower DTPS is better.
+				}
+				for iteration, value := range distribution.AllValues {
+					weights[i][metric].add(sign * (value - baseline[metric].AllValues[iteration]) / simValues[i])
+				}
+			}
+		}
+		iterations += batchIterations
 
-		result.Tps.EpValues[stat] = result.Tps.Weights[stat] / result.Tps.Weights[referenceStat]
-		result.Tps.EpValuesStdev[stat] = result.Tps.WeightsStdev[stat] / math.Abs(result.Tps.Weights[referenceStat])
+		if maxWeightError <= 0 || pairedWeightsConverged(weights, maxWeightError) {
+			break
+		}
+	}
 
-		if result.Dtps.Weights[DTPSReferenceStat] != 0 {
-			result.Dtps.EpValues[stat] = result.Dtps.Weights[stat] / result.Dtps.Weights[DTPSReferenceStat]
-			result.Dtps.EpValuesStdev[stat] = result.Dtps.WeightsStdev[stat] / math.Abs(result.Dtps.Weights[DTPSReferenceStat])
+	for i := 0; i < numStatSims; i++ {
+		result := &resultHigh
+		if simIsLow[i] {
+			result = &resultLow
+		}
+		stat := simStats[i]
+		for metric, values := range []*StatWeightValues{&result.Dps, &result.Hps, &result.Tps, &result.Dtps} {
+			values.Weights[stat] = weights[i][metric].mean()
+			values.WeightsStdev[stat] = weights[i][metric].stdev()
 		}
 	}
+	return resultLow, resultHigh, iterations, ""
+}
 
-	return result
+func pairedWeightsConverged(weights [][4]pairedWeights, maxWeightError float64) bool {
+	for i := range weights {
+		for metric := range weights[i] {
+			if weights[i][metric].stdErr() > maxWeightError {
+				return false
+			}
+		}
+	}
+	return true
 }
@@ -0,0 +1,318 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Replaces the talents in the player's spec options with those of a talent
+// string, in the format used by the UI.
+func SetTalentsFromString(player *proto.Player, talentsString string) error {
+	talentsField, specOptions, err := playerTalentsField(player)
+	if err != nil {
+		return err
+	}
+	talents := specOptions.NewField(talentsField).Message()
+	if err := parseTalentsString(classTalentTrees[player.Class], talents, talentsString); err != nil {
+		return err
+	}
+
+	specOptions.Set(talentsField, protoreflect.ValueOfMessage(talents))
+	player.TalentsString = talentsString
+	return nil
+}
+
+// Sets the fields of a talents proto from a talent string.
+func parseTalentsString(trees [][]talentConfig, talents protoreflect.Message, talentsString string) error {
+	treeStrings := strings.Split(talentsString, "-")
+	if len(treeStrings) > len(trees) {
+		return fmt.Errorf("Talent string %s has more than %d trees", talentsString, len(trees))
+	}
+	for treeIdx, treeString := range treeStrings {
+		tree := trees[treeIdx]
+		if len(treeString) > len(tree) {
+			return fmt.Errorf("Talent string %s has too many talents in tree %d", talentsString, treeIdx+1)
+		}
+		for i, c := range treeString {
+			if c < '0' || c > '9' {
+				return fmt.Errorf("Invalid character %q in talent string %s", c, talentsString)
+			}
+			points := int32(c - '0')
+			talent := tree[i]
+			if points > talent.maxPoints {
+				return fmt.Errorf("Talent %s has at most %d points, got %d", talent.fieldName, talent.maxPoints, points)
+			}
+			field := talents.Descriptor().Fields().ByJSONName(talent.fieldName)
+			if field == nil {
+				return fmt.Errorf("No talent field %s in %s", talent.fieldName, talents.Descriptor().Name())
+			}
+			if field.Kind() == protoreflect.BoolKind {
+				talents.Set(field, protoreflect.ValueOfBool(points == 1))
+			} else {
+				talents.Set(field, protoreflect.ValueOfInt32(points))
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the talents field of the player's spec options, and the mutable spec
+// options message.
+func playerTalentsField(player *proto.Player) (protoreflect.FieldDescriptor, protoreflect.Message, error) {
+	if _, ok := classTalentTrees[player.Class]; !ok {
+		return nil, nil, fmt.Errorf("No talents for class %s", player.Class)
+	}
+
+	playerMessage := player.ProtoReflect()
+	specField := playerMessage.WhichOneof(playerMessage.Descriptor().Oneofs().ByName("spec"))
+	if specField == nil {
+		return nil, nil, fmt.Errorf("Player has no spec options")
+	}
+	specOptions := playerMessage.Mutable(specField).Message()
+	talentsField := specOptions.Descriptor().Fields().ByName("talents")
+	if talentsField == nil {
+		return nil, nil, fmt.Errorf("Spec %s has no talents", specField.Name())
+	}
+	return talentsField, specOptions, nil
+}
+
+// Returns a copy of the player for each of their talent points, with that point
+// removed. Keyed by the JSON name of the talent.
+func withTalentPointsRemoved(player *proto.Player) (map[string]*proto.Player, error) {
+	talentsField, specOptions, err := playerTalentsField(googleProto.Clone(player).(*proto.Player))
+	if err != nil {
+		return nil, err
+	}
+
+	players := make(map[string]*proto.Player)
+	specOptions.Get(talentsField).Message().Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		newPlayer := googleProto.Clone(player).(*proto.Player)
+		newTalentsField, newSpecOptions, _ := playerTalentsField(newPlayer)
+		talents := newSpecOptions.Mutable(newTalentsField).Message()
+		if field.Kind() == protoreflect.BoolKind {
+			talents.Set(field, protoreflect.ValueOfBool(false))
+		} else {
+			talents.Set(field, protoreflect.ValueOfInt32(int32(value.Int())-1))
+		}
+		newPlayer.TalentsString = ""
+		players[field.JSONName()] = newPlayer
+		return true
+	})
+	return players, nil
+}
+
+// Max number of sims in one talent comparison, including the baseline.
+const maxTalentComparisonSims = 200
+
+type talentComparisonSim struct {
+	player  *proto.Player
+	variant *proto.TalentComparisonVariant
+	dps     []float64
+}
+
+// Number of sims a talent comparison request runs, or 0 if it is invalid.
+func TalentComparisonSims(request *proto.TalentComparisonRequest) int {
+	sims, _, err := newTalentComparisonSims(request)
+	if err != nil {
+		return 0
+	}
+	return len(sims)
+}
+
+// Returns the baseline sim followed by the requested variants, and then one sim
+// per talent point if the request asks for their values.
+func newTalentComparisonSims(request *proto.TalentComparisonRequest) ([]*talentComparisonSim, int, error) {
+	player := googleProto.Clone(request.Player).(*proto.Player)
+	if player.BonusStats == nil {
+		player.BonusStats = make([]float64, stats.Len)
+	}
+
+	baseline := &talentComparisonSim{
+		player:  player,
+		variant: &proto.TalentComparisonVariant{Glyphs: player.Glyphs},
+	}
+	sims := []*talentComparisonSim{baseline}
+
+	talentsStrings := request.TalentsStrings
+	if len(talentsStrings) == 0 {
+		talentsStrings = []string{""}
+	}
+	glyphSets := request.Glyphs
+	if len(glyphSets) == 0 {
+		glyphSets = []*proto.Glyphs{player.Glyphs}
+	}
+	if len(request.TalentsStrings) > 0 || len(request.Glyphs) > 0 {
+		for _, talentsString := range talentsStrings {
+			for _, glyphs := range glyphSets {
+				variantPlayer := googleProto.Clone(player).(*proto.Player)
+				if talentsString != "" {
+					if err := SetTalentsFromString(variantPlayer, talentsString); err != nil {
+						return nil, 0, err
+					}
+				}
+				variantPlayer.Glyphs = glyphs
+				sims = append(sims, &talentComparisonSim{
+					player: variantPlayer,
+					variant: &proto.TalentComparisonVariant{
+						TalentsString: talentsString,
+						Glyphs:        glyphs,
+					},
+				})
+			}
+		}
+	}
+	numVariants := len(sims) - 1
+
+	if request.TalentPointValues {
+		players, err := withTalentPointsRemoved(player)
+		if err != nil {
+			return nil, 0, err
+		}
+		for talent, talentPlayer := range players {
+			sims = append(sims, &talentComparisonSim{
+				player: talentPlayer,
+				variant: &proto.TalentComparisonVariant{
+					Glyphs:        player.Glyphs,
+					RemovedTalent: talent,
+				},
+			})
+		}
+	}
+
+	if len(sims) > maxTalentComparisonSims {
+		return nil, 0, fmt.Errorf("Too many talent comparison sims: %d, at most %d are allowed", len(sims), maxTalentComparisonSims)
+	}
+	return sims, numVariants, nil
+}
+
+// Sims the player's talents and glyphs along with each requested variant, and
+// compares their DPS using paired iterations.
+func CompareTalents(ctx context.Context, request *proto.TalentComparisonRequest, progress chan *proto.ProgressMetrics) *proto.TalentComparisonResult {
+	sims, numVariants, err := newTalentComparisonSims(request)
+	if err != nil {
+		return &proto.TalentComparisonResult{ErrorResult: err.Error()}
+	}
+	baseline := sims[0]
+	player := baseline.player
+
+	baseSimRequest := &proto.RaidSimRequest{
+		Raid:       SinglePlayerRaidProto(player, request.PartyBuffs, request.RaidBuffs, request.Debuffs),
+		Encounter:  request.Encounter,
+		SimOptions: request.SimOptions,
+	}
+	baseSimRequest.Raid.Tanks = request.Tanks
+	baseSimRequest = googleProto.Clone(baseSimRequest).(*proto.RaidSimRequest)
+	simOptions := baseSimRequest.SimOptions
+	if simOptions.RandomSeed == 0 {
+		simOptions.RandomSeed = time.Now().UnixNano()
+	}
+	simOptions.SaveAllValues = true
+
+	var mutex sync.Mutex
+	var errorResult string
+	simsCompleted := 0
+
+	runSim := func(sim *talentComparisonSim) {
+		simRequest := googleProto.Clone(baseSimRequest).(*proto.RaidSimRequest)
+		simRequest.Raid.Parties[0].Players[0] = sim.player
+
+		result := RunSimWithContext(ctx, *simRequest, nil)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if result.ErrorResult != "" {
+			if errorResult == "" {
+				errorResult = result.ErrorResult
+			}
+			return
+		}
+		dps := result.RaidMetrics.Parties[0].Players[0].Dps
+		sim.variant.Dps = dps.Avg
+		sim.variant.DpsStdev = dps.Stdev
+		sim.dps = dps.AllValues
+
+		simsCompleted++
+		if progress != nil {
+			progress <- &proto.ProgressMetrics{
+				TotalIterations:     int32(len(sims)) * simOptions.Iterations,
+				CompletedIterations: int32(simsCompleted) * simOptions.Iterations,
+				CompletedSims:       int32(simsCompleted),
+				TotalSims:           int32(len(sims)),
+			}
+		}
+	}
+
+	simsToRun := make(chan *talentComparisonSim, len(sims))
+	for _, sim := range sims {
+		simsToRun <- sim
+	}
+	close(simsToRun)
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < MinInt(runtime.NumCPU(), len(sims)); i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for sim := range simsToRun {
+				if ctx.Err() != nil {
+					return
+				}
+				runSim(sim)
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	if ctx.Err() != nil {
+		return &proto.TalentComparisonResult{ErrorResult: "Talent comparison cancelled: " + ctx.Err().Error()}
+	}
+	if errorResult != "" {
+		return &proto.TalentComparisonResult{ErrorResult: errorResult}
+	}
+
+	result := &proto.TalentComparisonResult{Baseline: baseline.variant}
+	for i, sim := range sims[1:] {
+		sim.setDelta(baseline)
+		if i < numVariants {
+			result.Variants = append(result.Variants, sim.variant)
+		} else {
+			result.TalentPoints = append(result.TalentPoints, sim.variant)
+		}
+	}
+
+	sort.SliceStable(result.Variants, func(i, j int) bool {
+		return result.Variants[i].Dps > result.Variants[j].Dps
+	})
+	// Removing the most valuable points loses the most dps.
+	sort.SliceStable(result.TalentPoints, func(i, j int) bool {
+		if result.TalentPoints[i].DpsDelta != result.TalentPoints[j].DpsDelta {
+			return result.TalentPoints[i].DpsDelta < result.TalentPoints[j].DpsDelta
+		}
+		return result.TalentPoints[i].RemovedTalent < result.TalentPoints[j].RemovedTalent
+	})
+	return result
+}
+
+// Sets the difference in dps to the baseline. Iterations of both sims use the
+// same seeds, so the error comes from the differences of each iteration.
+func (sim *talentComparisonSim) setDelta(baseline *talentComparisonSim) {
+	var pw pairedWeights
+	for i := range sim.dps {
+		pw.add(sim.dps[i] - baseline.dps[i])
+	}
+
+	sim.variant.DpsDelta = pw.mean()
+	sim.variant.DpsDeltaError = pw.stdErr()
+	sim.variant.Significant = math.Abs(pw.mean()) > 1.96*pw.stdErr()
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestTalentTreesMatchProtos(t *testing.T) {
+	talentProtos := map[proto.Class]protoreflect.ProtoMessage{
+		proto.Class_ClassDeathknight: &proto.DeathknightTalents{},
+		proto.Class_ClassDruid:       &proto.DruidTalents{},
+		proto.Class_ClassHunter:      &proto.HunterTalents{},
+		proto.Class_ClassMage:        &proto.MageTalents{},
+		proto.Class_ClassPaladin:     &proto.PaladinTalents{},
+		proto.Class_ClassPriest:      &proto.PriestTalents{},
+		proto.Class_ClassRogue:       &proto.RogueTalents{},
+		proto.Class_ClassShaman:      &proto.ShamanTalents{},
+		proto.Class_ClassWarlock:     &proto.WarlockTalents{},
+		proto.Class_ClassWarrior:     &proto.WarriorTalents{},
+	}
+	if len(classTalentTrees) != len(talentProtos) {
+		t.Fatalf("Expected talent trees for %d classes, got %d", len(talentProtos), len(classTalentTrees))
+	}
+
+	for class, talentsProto := range talentProtos {
+		trees := classTalentTrees[class]
+		fields := talentsProto.ProtoReflect().Descriptor().Fields()
+
+		// Every field of the talents proto has exactly one place in the trees.
+		numTalents := 0
+		seen := make(map[string]bool)
+		for _, tree := range trees {
+			numTalents += len(tree)
+			for _, talent := range tree {
+				field := fields.ByJSONName(talent.fieldName)
+				if field == nil {
+					t.Errorf("%s: no field %s in %s", class, talent.fieldName, fields.Get(0).Parent().Name())
+					continue
+				}
+				if seen[talent.fieldName] {
+					t.Errorf("%s: talent %s is in the trees more than once", class, talent.fieldName)
+				}
+				seen[talent.fieldName] = true
+				if field.Kind() == protoreflect.BoolKind && talent.maxPoints != 1 {
+					t.Errorf("%s: talent %s is a bool field, but has %d max points", class, talent.fieldName, talent.maxPoints)
+				}
+			}
+		}
+		if numTalents != fields.Len() {
+			t.Errorf("%s: %d talents in the trees, but %d fields in %s", class, numTalents, fields.Len(), fields.Get(0).Parent().Name())
+		}
+
+		// Maxing out every talent sets every field, and reads back as the same string.
+		var treeStrings []string
+		for _, tree := range trees {
+			treeString := ""
+			for _, talent := range tree {
+				treeString += string(rune('0' + talent.maxPoints))
+			}
+			treeStrings = append(treeStrings, treeString)
+		}
+		talentsString := strings.Join(treeStrings, "-")
+
+		talents := talentsProto.ProtoReflect().Type().New()
+		if err := parseTalentsString(trees, talents, talentsString); err != nil {
+			t.Errorf("%s: %s", class, err)
+			continue
+		}
+
+		treeStrings = nil
+		for _, tree := range trees {
+			treeString := ""
+			for _, talent := range tree {
+				field := fields.ByJSONName(talent.fieldName)
+				points := int32(0)
+				if field.Kind() == protoreflect.BoolKind {
+					if talents.Get(field).Bool() {
+						points = 1
+					}
+				} else {
+					points = int32(talents.Get(field).Int())
+				}
+				treeString += string(rune('0' + points))
+			}
+			treeStrings = append(treeStrings, treeString)
+		}
+		if roundTrip := strings.Join(treeStrings, "-"); roundTrip != talentsString {
+			t.Errorf("%s: expected %s after parsing, got %s", class, talentsString, roundTrip)
+		}
+	}
+}
@@ -0,0 +1,955 @@
+package core
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+type talentConfig struct {
+	// JSON name of the field in the class's talents proto.
+	fieldName string
+	maxPoints int32
+}
+
+// Talents of each class's trees, in the order used by talent strings. Mirrors
+// the talent configs in ui/core/talents.
+var classTalentTrees = map[proto.Class][][]talentConfig{
+	proto.Class_ClassDruid: {
+		// Balance
+		{
+			{"starlightWrath", 5},
+			{"genesis", 5},
+			{"moonglow", 3},
+			{"naturesMajesty", 2},
+			{"improvedMoonfire", 2},
+			{"brambles", 3},
+			{"naturesGrace", 3},
+			{"naturesSplendor", 1},
+			{"naturesReach", 2},
+			{"vengeance", 5},
+			{"celestialFocus", 3},
+			{"lunarGuidance", 3},
+			{"insectSwarm", 1},
+			{"improvedInsectSwarm", 3},
+			{"dreamstate", 3},
+			{"moonfury", 3},
+			{"balanceOfPower", 2},
+			{"moonkinForm", 1},
+			{"improvedMoonkinForm", 3},
+			{"improvedFaerieFire", 3},
+			{"owlkinFrenzy", 3},
+			{"wrathOfCenarius", 5},
+			{"eclipse", 3},
+			{"typhoon", 1},
+			{"forceOfNature", 1},
+			{"galeWinds", 2},
+			{"earthAndMoon", 3},
+			{"starfall", 1},
+		},
+		// Feral Combat
+		{
+			{"ferocity", 5},
+			{"feralAggression", 5},
+			{"feralInstinct", 3},
+			{"savageFury", 2},
+			{"thickHide", 3},
+			{"feralSwiftness", 2},
+			{"survivalInstincts", 1},
+			{"sharpenedClaws", 3},
+			{"shreddingAttacks", 2},
+			{"predatoryStrikes", 3},
+			{"primalFury", 2},
+			{"primalPrecision", 2},
+			{"brutalImpact", 2},
+			{"feralCharge", 1},
+			{"nurturingInstinct", 2},
+			{"naturalReaction", 3},
+			{"heartOfTheWild", 5},
+			{"survivalOfTheFittest", 3},
+			{"leaderOfThePack", 1},
+			{"improvedLeaderOfThePack", 2},
+			{"primalTenacity", 3},
+			{"protectorOfThePack", 3},
+			{"predatoryInstincts", 3},
+			{"infectedWounds", 3},
+			{"kingOfTheJungle", 3},
+			{"mangle", 1},
+			{"improvedMangle", 3},
+			{"rendAndTear", 5},
+			{"primalGore", 1},
+			{"berserk", 1},
+		},
+		// Restoration
+		{
+			{"improvedMarkOfTheWild", 2},
+			{"naturesFocus", 3},
+			{"furor", 5},
+			{"naturalist", 5},
+			{"subtlety", 3},
+			{"naturalShapeshifter", 3},
+			{"intensity", 3},
+			{"omenOfClarity", 1},
+			{"masterShapeshifter", 2},
+			{"tranquilSpirit", 5},
+			{"improvedRejuvenation", 3},
+			{"naturesSwiftness", 1},
+			{"giftOfNature", 5},
+			{"improvedTranquility", 2},
+			{"empoweredTouch", 2},
+			{"naturesBounty", 5},
+			{"livingSpirit", 3},
+			{"swiftmend", 1},
+			{"naturalPerfection", 3},
+			{"empoweredRejuvenation", 5},
+			{"livingSeed", 3},
+			{"revitalize", 3},
+			{"treeOfLife", 1},
+			{"improvedTreeOfLife", 3},
+			{"improvedBarkskin", 2},
+			{"giftOfTheEarthmother", 5},
+			{"wildGrowth", 1},
+		},
+	},
+	proto.Class_ClassHunter: {
+		// Beast Mastery
+		{
+			{"improvedAspectOfTheHawk", 5},
+			{"enduranceTraining", 5},
+			{"focusedFire", 2},
+			{"improvedAspectOfTheMonkey", 3},
+			{"thickHide", 3},
+			{"improvedRevivePet", 2},
+			{"pathfinding", 2},
+			{"aspectMastery", 1},
+			{"unleashedFury", 5},
+			{"improvedMendPet", 2},
+			{"ferocity", 5},
+			{"spiritBond", 2},
+			{"intimidation", 1},
+			{"bestialDiscipline", 2},
+			{"animalHandler", 2},
+			{"frenzy", 5},
+			{"ferociousInspiration", 3},
+			{"bestialWrath", 1},
+			{"catlikeReflexes", 3},
+			{"invigoration", 2},
+			{"serpentsSwiftness", 5},
+			{"longevity", 3},
+			{"theBeastWithin", 1},
+			{"cobraStrikes", 3},
+			{"kindredSpirits", 5},
+			{"beastMastery", 1},
+		},
+		// Marksmanship
+		{
+			{"improvedConcussiveShot", 2},
+			{"focusedAim", 3},
+			{"lethalShots", 5},
+			{"carefulAim", 3},
+			{"improvedHuntersMark", 3},
+			{"mortalShots", 5},
+			{"goForTheThroat", 2},
+			{"improvedArcaneShot", 3},
+			{"aimedShot", 1},
+			{"rapidKilling", 2},
+			{"improvedStings", 3},
+			{"efficiency", 5},
+			{"concussiveBarrage", 2},
+			{"readiness", 1},
+			{"barrage", 3},
+			{"combatExperience", 2},
+			{"rangedWeaponSpecialization", 3},
+			{"piercingShots", 3},
+			{"trueshotAura", 1},
+			{"improvedBarrage", 3},
+			{"masterMarksman", 5},
+			{"rapidRecuperation", 2},
+			{"wildQuiver", 3},
+			{"silencingShot", 1},
+			{"improvedSteadyShot", 3},
+			{"markedForDeath", 5},
+			{"chimeraShot", 1},
+		},
+		// Survival
+		{
+			{"improvedTracking", 5},
+			{"hawkEye", 3},
+			{"savageStrikes", 2},
+			{"surefooted", 3},
+			{"entrapment", 3},
+			{"trapMastery", 3},
+			{"survivalInstincts", 2},
+			{"survivalist", 5},
+			{"scatterShot", 1},
+			{"deflection", 3},
+			{"survivalTactics", 2},
+			{"tNT", 3},
+			{"lockAndLoad", 3},
+			{"hunterVsWild", 3},
+			{"killerInstinct", 3},
+			{"counterattack", 1},
+			{"lightningReflexes", 5},
+			{"resourcefulness", 3},
+			{"exposeWeakness", 3},
+			{"wyvernSting", 1},
+			{"thrillOfTheHunt", 3},
+			{"masterTactician", 5},
+			{"noxiousStings", 3},
+			{"pointOfNoEscape", 2},
+			{"blackArrow", 1},
+			{"sniperTraining", 3},
+			{"huntingParty", 3},
+			{"explosiveShot", 1},
+		},
+	},
+	proto.Class_ClassMage: {
+		// Arcane
+		{
+			{"arcaneSubtlety", 2},
+			{"arcaneFocus", 3},
+			{"arcaneStability", 5},
+			{"arcaneFortitude", 3},
+			{"magicAbsorption", 2},
+			{"arcaneConcentration", 5},
+			{"magicAttunement", 2},
+			{"spellImpact", 3},
+			{"studentOfTheMind", 3},
+			{"focusMagic", 1},
+			{"arcaneShielding", 2},
+			{"improvedCounterspell", 2},
+			{"arcaneMeditation", 3},
+			{"tormentTheWeak", 3},
+			{"improvedBlink", 2},
+			{"presenceOfMind", 1},
+			{"arcaneMind", 5},
+			{"prismaticCloak", 3},
+			{"arcaneInstability", 3},
+			{"arcanePotency", 2},
+			{"arcaneEmpowerment", 3},
+			{"arcanePower", 1},
+			{"incantersAbsorption", 3},
+			{"arcaneFlows", 2},
+			{"mindMastery", 5},
+			{"slow", 1},
+			{"missileBarrage", 5},
+			{"netherwindPresence", 3},
+			{"spellPower", 2},
+			{"arcaneBarrage", 1},
+		},
+		// Fire
+		{
+			{"improvedFireBlast", 2},
+			{"incineration", 3},
+			{"improvedFireball", 5},
+			{"ignite", 5},
+			{"burningDetermination", 2},
+			{"worldInFlames", 3},
+			{"flameThrowing", 2},
+			{"impact", 3},
+			{"pyroblast", 1},
+			{"burningSoul", 2},
+			{"improvedScorch", 3},
+			{"moltenShields", 2},
+			{"masterOfElements", 3},
+			{"playingWithFire", 3},
+			{"criticalMass", 3},
+			{"blastWave", 1},
+			{"blazingSpeed", 2},
+			{"firePower", 5},
+			{"pyromaniac", 3},
+			{"combustion", 1},
+			{"moltenFury", 2},
+			{"fieryPayback", 2},
+			{"empoweredFire", 3},
+			{"firestarter", 2},
+			{"dragonsBreath", 1},
+			{"hotStreak", 3},
+			{"burnout", 5},
+			{"livingBomb", 1},
+		},
+		// Frost
+		{
+			{"frostbite", 3},
+			{"improvedFrostbolt", 5},
+			{"iceFloes", 3},
+			{"iceShards", 3},
+			{"frostWarding", 2},
+			{"precision", 3},
+			{"permafrost", 3},
+			{"piercingIce", 3},
+			{"icyVeins", 1},
+			{"improvedBlizzard", 3},
+			{"arcticReach", 2},
+			{"frostChanneling", 3},
+			{"shatter", 3},
+			{"coldSnap", 1},
+			{"improvedConeOfCold", 3},
+			{"frozenCore", 3},
+			{"coldAsIce", 2},
+			{"wintersChill", 3},
+			{"shatteredBarrier", 2},
+			{"iceBarrier", 1},
+			{"arcticWinds", 5},
+			{"empoweredFrostbolt", 2},
+			{"fingersOfFrost", 2},
+			{"brainFreeze", 3},
+			{"summonWaterElemental", 1},
+			{"enduringWinter", 3},
+			{"chilledToTheBone", 5},
+			{"deepFreeze", 1},
+		},
+	},
+	proto.Class_ClassPaladin: {
+		// Holy
+		{
+			{"spiritualFocus", 5},
+			{"sealsOfThePure", 5},
+			{"healingLight", 3},
+			{"divineIntellect", 5},
+			{"unyieldingFaith", 2},
+			{"auraMastery", 1},
+			{"illumination", 5},
+			{"improvedLayOnHands", 2},
+			{"improvedConcentrationAura", 3},
+			{"improvedBlessingOfWisdom", 2},
+			{"blessedHands", 2},
+			{"pureOfHeart", 2},
+			{"divineFavor", 1},
+			{"sanctifiedLight", 3},
+			{"purifyingPower", 2},
+			{"holyPower", 5},
+			{"lightsGrace", 3},
+			{"holyShock", 1},
+			{"blessedLife", 3},
+			{"sacredCleansing", 3},
+			{"holyGuidance", 5},
+			{"divineIllumination", 1},
+			{"judgementsOfThePure", 5},
+			{"infusionOfLight", 2},
+			{"enlightenedJudgements", 2},
+			{"beaconOfLight", 1},
+		},
+		// Protection
+		{
+			{"divinity", 5},
+			{"divineStrength", 5},
+			{"stoicism", 3},
+			{"guardiansFavor", 2},
+			{"anticipation", 5},
+			{"divineSacrifice", 1},
+			{"improvedRighteousFury", 3},
+			{"toughness", 5},
+			{"divineGuardian", 2},
+			{"improvedHammerOfJustice", 2},
+			{"improvedDevotionAura", 3},
+			{"blessingOfSanctuary", 1},
+			{"reckoning", 5},
+			{"sacredDuty", 2},
+			{"oneHandedWeaponSpecialization", 3},
+			{"spiritualAttunement", 2},
+			{"holyShield", 1},
+			{"ardentDefender", 3},
+			{"redoubt", 3},
+			{"combatExpertise", 3},
+			{"touchedByTheLight", 3},
+			{"avengersShield", 1},
+			{"guardedByTheLight", 2},
+			{"shieldOfTheTemplar", 3},
+			{"judgementsOfTheJust", 2},
+			{"hammerOfTheRighteous", 1},
+		},
+		// Retribution
+		{
+			{"deflection", 5},
+			{"benediction", 5},
+			{"improvedJudgements", 2},
+			{"heartOfTheCrusader", 3},
+			{"improvedBlessingOfMight", 2},
+			{"vindication", 2},
+			{"conviction", 5},
+			{"sealOfCommand", 1},
+			{"pursuitOfJustice", 2},
+			{"eyeForAnEye", 2},
+			{"sanctityOfBattle", 3},
+			{"crusade", 3},
+			{"twoHandedWeaponSpecialization", 3},
+			{"sanctifiedRetribution", 1},
+			{"vengeance", 3},
+			{"divinePurpose", 2},
+			{"theArtOfWar", 2},
+			{"repentance", 1},
+			{"judgementsOfTheWise", 3},
+			{"fanaticism", 3},
+			{"sanctifiedWrath", 2},
+			{"swiftRetribution", 3},
+			{"crusaderStrike", 1},
+			{"sheathOfLight", 3},
+			{"righteousVengeance", 3},
+			{"divineStorm", 1},
+		},
+	},
+	proto.Class_ClassPriest: {
+		// Discipline
+		{
+			{"unbreakableWill", 5},
+			{"twinDisciplines", 5},
+			{"silentResolve", 3},
+			{"improvedInnerFire", 3},
+			{"improvedPowerWordFortitude", 2},
+			{"martyrdom", 2},
+			{"meditation", 3},
+			{"innerFocus", 1},
+			{"improvedPowerWordShield", 3},
+			{"absolution", 3},
+			{"mentalAgility", 3},
+			{"improvedManaBurn", 2},
+			{"reflectiveShield", 2},
+			{"mentalStrength", 5},
+			{"soulWarding", 1},
+			{"focusedPower", 2},
+			{"enlightenment", 3},
+			{"focusedWill", 3},
+			{"powerInfusion", 1},
+			{"improvedFlashHeal", 3},
+			{"renewedHope", 2},
+			{"rapture", 3},
+			{"aspiration", 2},
+			{"divineAegis", 3},
+			{"painSuppression", 1},
+			{"grace", 2},
+			{"borrowedTime", 5},
+			{"penance", 1},
+		},
+		// Holy
+		{
+			{"healingFocus", 2},
+			{"improvedRenew", 3},
+			{"holySpecialization", 5},
+			{"spellWarding", 5},
+			{"divineFury", 5},
+			{"desperatePrayer", 1},
+			{"blessedRecovery", 3},
+			{"inspiration", 3},
+			{"holyReach", 2},
+			{"improvedHealing", 3},
+			{"searingLight", 2},
+			{"healingPrayers", 2},
+			{"spiritOfRedemption", 1},
+			{"spiritualGuidance", 5},
+			{"surgeOfLight", 2},
+			{"spiritualHealing", 5},
+			{"holyConcentration", 3},
+			{"lightwell", 1},
+			{"blessedResilience", 3},
+			{"bodyAndSoul", 2},
+			{"empoweredHealing", 5},
+			{"serendipity", 3},
+			{"empoweredRenew", 3},
+			{"circleOfHealing", 1},
+			{"testOfFaith", 3},
+			{"divineProvidence", 5},
+			{"guardianSpirit", 1},
+		},
+		// Shadow
+		{
+			{"spiritTap", 3},
+			{"improvedSpiritTap", 2},
+			{"darkness", 5},
+			{"shadowAffinity", 3},
+			{"improvedShadowWordPain", 2},
+			{"shadowFocus", 3},
+			{"improvedPsychicScream", 2},
+			{"improvedMindBlast", 5},
+			{"mindFlay", 1},
+			{"veiledShadows", 2},
+			{"shadowReach", 2},
+			{"shadowWeaving", 3},
+			{"silence", 1},
+			{"vampiricEmbrace", 1},
+			{"improvedVampiricEmbrace", 2},
+			{"focusedMind", 3},
+			{"mindMelt", 2},
+			{"improvedDevouringPlague", 3},
+			{"shadowform", 1},
+			{"shadowPower", 5},
+			{"improvedShadowform", 2},
+			{"misery", 3},
+			{"psychicHorror", 1},
+			{"vampiricTouch", 1},
+			{"painAndSuffering", 3},
+			{"twistedFaith", 5},
+			{"dispersion", 1},
+		},
+	},
+	proto.Class_ClassRogue: {
+		// Assassination
+		{
+			{"improvedEviscerate", 3},
+			{"remorselessAttacks", 2},
+			{"malice", 5},
+			{"ruthlessness", 3},
+			{"bloodSpatter", 2},
+			{"puncturingWounds", 3},
+			{"vigor", 1},
+			{"improvedExposeArmor", 2},
+			{"lethality", 5},
+			{"vilePoisons", 3},
+			{"improvedPoisons", 5},
+			{"fleetFooted", 2},
+			{"coldBlood", 1},
+			{"improvedKidneyShot", 3},
+			{"quickRecovery", 2},
+			{"sealFate", 5},
+			{"murder", 2},
+			{"deadlyBrew", 2},
+			{"overkill", 1},
+			{"deadenedNerves", 3},
+			{"focusedAttacks", 3},
+			{"findWeakness", 3},
+			{"masterPoisoner", 3},
+			{"mutilate", 1},
+			{"turnTheTables", 3},
+			{"cutToTheChase", 5},
+			{"hungerForBlood", 1},
+		},
+		// Combat
+		{
+			{"improvedGouge", 3},
+			{"improvedSinisterStrike", 2},
+			{"dualWieldSpecialization", 5},
+			{"improvedSliceAndDice", 2},
+			{"deflection", 3},
+			{"precision", 5},
+			{"endurance", 2},
+			{"riposte", 1},
+			{"closeQuartersCombat", 5},
+			{"improvedKick", 2},
+			{"improvedSprint", 2},
+			{"lightningReflexes", 3},
+			{"aggression", 5},
+			{"maceSpecialization", 5},
+			{"bladeFlurry", 1},
+			{"hackAndSlash", 5},
+			{"weaponExpertise", 2},
+			{"bladeTwisting", 2},
+			{"vitality", 3},
+			{"adrenalineRush", 1},
+			{"nervesOfSteel", 2},
+			{"throwingSpecialization", 2},
+			{"combatPotency", 5},
+			{"unfairAdvantage", 2},
+			{"surpriseAttacks", 1},
+			{"savageCombat", 2},
+			{"preyOnTheWeak", 5},
+			{"killingSpree", 1},
+		},
+		// Subtlety
+		{
+			{"relentlessStrikes", 5},
+			{"masterOfDeception", 3},
+			{"opportunity", 2},
+			{"sleightOfHand", 2},
+			{"dirtyTricks", 2},
+			{"camouflage", 3},
+			{"elusiveness", 2},
+			{"ghostlyStrike", 1},
+			{"serratedBlades", 3},
+			{"setup", 3},
+			{"initiative", 3},
+			{"improvedAmbush", 2},
+			{"heightenedSenses", 2},
+			{"preparation", 1},
+			{"dirtyDeeds", 2},
+			{"hemorrhage", 1},
+			{"masterOfSubtlety", 3},
+			{"deadliness", 5},
+			{"envelopingShadows", 3},
+			{"premeditation", 1},
+			{"cheatDeath", 3},
+			{"sinisterCalling", 5},
+			{"waylay", 2},
+			{"honorAmongThieves", 3},
+			{"shadowstep", 1},
+			{"filthyTricks", 2},
+			{"slaughterFromTheShadows", 5},
+			{"shadowDance", 1},
+		},
+	},
+	proto.Class_ClassShaman: {
+		// Elemental
+		{
+			{"convection", 5},
+			{"concussion", 5},
+			{"callOfFlame", 3},
+			{"elementalWarding", 3},
+			{"elementalDevastation", 3},
+			{"reverberation", 5},
+			{"elementalFocus", 1},
+			{"elementalFury", 5},
+			{"improvedFireNova", 2},
+			{"eyeOfTheStorm", 3},
+			{"elementalReach", 2},
+			{"callOfThunder", 1},
+			{"unrelentingStorm", 3},
+			{"elementalPrecision", 3},
+			{"lightningMastery", 5},
+			{"elementalMastery", 1},
+			{"stormEarthAndFire", 3},
+			{"boomingEchoes", 2},
+			{"elementalOath", 2},
+			{"lightningOverload", 3},
+			{"astralShift", 3},
+			{"totemOfWrath", 1},
+			{"lavaFlows", 3},
+			{"shamanism", 5},
+			{"thunderstorm", 1},
+		},
+		// Enhancement
+		{
+			{"enhancingTotems", 3},
+			{"earthsGrasp", 2},
+			{"ancestralKnowledge", 5},
+			{"guardianTotems", 2},
+			{"thunderingStrikes", 5},
+			{"improvedGhostWolf", 2},
+			{"improvedShields", 3},
+			{"elementalWeapons", 3},
+			{"shamanisticFocus", 1},
+			{"anticipation", 3},
+			{"flurry", 5},
+			{"toughness", 5},
+			{"improvedWindfuryTotem", 2},
+			{"spiritWeapons", 1},
+			{"mentalDexterity", 3},
+			{"unleashedRage", 3},
+			{"weaponMastery", 3},
+			{"frozenPower", 2},
+			{"dualWieldSpecialization", 3},
+			{"dualWield", 1},
+			{"stormstrike", 1},
+			{"staticShock", 3},
+			{"lavaLash", 1},
+			{"improvedStormstrike", 2},
+			{"mentalQuickness", 3},
+			{"shamanisticRage", 1},
+			{"earthenPower", 2},
+			{"maelstromWeapon", 5},
+			{"feralSpirit", 1},
+		},
+		// Restoration
+		{
+			{"improvedHealingWave", 5},
+			{"totemicFocus", 5},
+			{"improvedReincarnation", 2},
+			{"healingGrace", 3},
+			{"tidalFocus", 5},
+			{"improvedWaterShield", 3},
+			{"healingFocus", 3},
+			{"tidalForce", 1},
+			{"ancestralHealing", 3},
+			{"restorativeTotems", 3},
+			{"tidalMastery", 5},
+			{"healingWay", 3},
+			{"naturesSwiftness", 1},
+			{"focusedMind", 3},
+			{"purification", 5},
+			{"naturesGuardian", 5},
+			{"manaTideTotem", 1},
+			{"cleanseSpirit", 1},
+			{"blessingOfTheEternals", 2},
+			{"improvedChainHeal", 2},
+			{"naturesBlessing", 3},
+			{"ancestralAwakening", 3},
+			{"earthShield", 1},
+			{"improvedEarthShield", 2},
+			{"tidalWaves", 5},
+			{"riptide", 1},
+		},
+	},
+	proto.Class_ClassWarlock: {
+		// Affliction
+		{
+			{"improvedCurseOfAgony", 2},
+			{"suppression", 3},
+			{"improvedCorruption", 5},
+			{"improvedCurseOfWeakness", 2},
+			{"improvedDrainSoul", 2},
+			{"improvedLifeTap", 2},
+			{"soulSiphon", 2},
+			{"improvedFear", 2},
+			{"felConcentration", 3},
+			{"amplifyCurse", 1},
+			{"grimReach", 2},
+			{"nightfall", 2},
+			{"empoweredCorruption", 3},
+			{"shadowEmbrace", 5},
+			{"siphonLife", 1},
+			{"curseOfExhaustion", 1},
+			{"improvedFelhunter", 2},
+			{"shadowMastery", 5},
+			{"eradication", 3},
+			{"contagion", 5},
+			{"darkPact", 1},
+			{"improvedHowlOfTerror", 2},
+			{"malediction", 3},
+			{"deathsEmbrace", 3},
+			{"unstableAffliction", 1},
+			{"pandemic", 1},
+			{"everlastingAffliction", 5},
+			{"haunt", 1},
+		},
+		// Demonology
+		{
+			{"improvedHealthstone", 2},
+			{"improvedImp", 3},
+			{"demonicEmbrace", 3},
+			{"felSynergy", 2},
+			{"improvedHealthFunnel", 2},
+			{"demonicBrutality", 3},
+			{"felVitality", 3},
+			{"improvedSayaad", 3},
+			{"soulLink", 1},
+			{"felDomination", 1},
+			{"demonicAegis", 3},
+			{"unholyPower", 5},
+			{"masterSummoner", 2},
+			{"manaFeed", 1},
+			{"masterConjuror", 2},
+			{"masterDemonologist", 5},
+			{"moltenCore", 3},
+			{"demonicResilience", 3},
+			{"demonicEmpowerment", 1},
+			{"demonicKnowledge", 3},
+			{"demonicTactics", 5},
+			{"decimation", 2},
+			{"improvedDemonicTactics", 3},
+			{"summonFelguard", 1},
+			{"nemesis", 3},
+			{"demonicPact", 5},
+			{"metamorphosis", 1},
+		},
+		// Destruction
+		{
+			{"improvedShadowBolt", 5},
+			{"bane", 5},
+			{"aftermath", 2},
+			{"moltenSkin", 3},
+			{"cataclysm", 3},
+			{"demonicPower", 2},
+			{"shadowburn", 1},
+			{"ruin", 5},
+			{"intensity", 2},
+			{"destructiveReach", 2},
+			{"improvedSearingPain", 3},
+			{"backlash", 3},
+			{"improvedImmolate", 3},
+			{"devastation", 1},
+			{"netherProtection", 3},
+			{"emberstorm", 5},
+			{"conflagrate", 1},
+			{"soulLeech", 3},
+			{"pyroclasm", 3},
+			{"shadowAndFlame", 5},
+			{"improvedSoulLeech", 2},
+			{"backdraft", 3},
+			{"shadowfury", 1},
+			{"empoweredImp", 3},
+			{"fireAndBrimstone", 5},
+			{"chaosBolt", 1},
+		},
+	},
+	proto.Class_ClassWarrior: {
+		// Arms
+		{
+			{"improvedHeroicStrike", 3},
+			{"deflection", 5},
+			{"improvedRend", 2},
+			{"improvedCharge", 2},
+			{"ironWill", 3},
+			{"tacticalMastery", 3},
+			{"improvedOverpower", 2},
+			{"angerManagement", 1},
+			{"impale", 2},
+			{"deepWounds", 3},
+			{"twoHandedWeaponSpecialization", 3},
+			{"tasteForBlood", 3},
+			{"poleaxeSpecialization", 5},
+			{"sweepingStrikes", 1},
+			{"maceSpecialization", 5},
+			{"swordSpecialization", 5},
+			{"weaponMastery", 2},
+			{"improvedHamstring", 3},
+			{"trauma", 2},
+			{"secondWind", 2},
+			{"mortalStrike", 1},
+			{"strengthOfArms", 2},
+			{"improvedSlam", 2},
+			{"juggernaut", 1},
+			{"improvedMortalStrike", 3},
+			{"unrelentingAssault", 2},
+			{"suddenDeath", 3},
+			{"endlessRage", 1},
+			{"bloodFrenzy", 2},
+			{"wreckingCrew", 5},
+			{"bladestorm", 1},
+		},
+		// Fury
+		{
+			{"armoredToTheTeeth", 3},
+			{"boomingVoice", 2},
+			{"cruelty", 5},
+			{"improvedDemoralizingShout", 5},
+			{"unbridledWrath", 5},
+			{"improvedCleave", 3},
+			{"piercingHowl", 1},
+			{"bloodCraze", 3},
+			{"commandingPresence", 5},
+			{"dualWieldSpecialization", 5},
+			{"improvedExecute", 2},
+			{"enrage", 5},
+			{"precision", 3},
+			{"deathWish", 1},
+			{"improvedIntercept", 2},
+			{"improvedBerserkerRage", 2},
+			{"flurry", 5},
+			{"intensifyRage", 3},
+			{"bloodthirst", 1},
+			{"improvedWhirlwind", 2},
+			{"furiousAttacks", 2},
+			{"improvedBerserkerStance", 5},
+			{"heroicFury", 1},
+			{"rampage", 1},
+			{"bloodsurge", 3},
+			{"unendingFury", 5},
+			{"titansGrip", 1},
+		},
+		// Protection
+		{
+			{"improvedBloodrage", 2},
+			{"shieldSpecialization", 5},
+			{"improvedThunderClap", 3},
+			{"incite", 3},
+			{"anticipation", 5},
+			{"lastStand", 1},
+			{"improvedRevenge", 2},
+			{"shieldMastery", 2},
+			{"toughness", 5},
+			{"improvedSpellReflection", 2},
+			{"improvedDisarm", 2},
+			{"puncture", 3},
+			{"improvedDisciplines", 2},
+			{"concussionBlow", 1},
+			{"gagOrder", 2},
+			{"oneHandedWeaponSpecialization", 5},
+			{"improvedDefensiveStance", 2},
+			{"vigilance", 1},
+			{"focusedRage", 3},
+			{"vitality", 3},
+			{"safeguard", 2},
+			{"warbringer", 1},
+			{"devastate", 1},
+			{"criticalBlock", 3},
+			{"swordAndBoard", 3},
+			{"damageShield", 2},
+			{"shockwave", 1},
+		},
+	},
+	proto.Class_ClassDeathknight: {
+		// Blood
+		{
+			{"butchery", 2},
+			{"subversion", 3},
+			{"bladeBarrier", 5},
+			{"bladedArmor", 5},
+			{"scentOfBlood", 3},
+			{"twoHandedWeaponSpecialization", 2},
+			{"runeTap", 1},
+			{"darkConviction", 5},
+			{"deathRuneMastery", 3},
+			{"improvedRuneTap", 3},
+			{"spellDeflection", 3},
+			{"vendetta", 3},
+			{"bloodyStrikes", 3},
+			{"veteranOfTheThirdWar", 3},
+			{"markOfBlood", 1},
+			{"bloodyVengeance", 3},
+			{"abominationsMight", 2},
+			{"bloodworms", 3},
+			{"hysteria", 1},
+			{"improvedBloodPresence", 2},
+			{"improvedDeathStrike", 2},
+			{"suddenDoom", 3},
+			{"vampiricBlood", 1},
+			{"willOfTheNecropolis", 3},
+			{"heartStrike", 1},
+			{"mightOfMograine", 3},
+			{"bloodGorged", 5},
+			{"dancingRuneWeapon", 1},
+		},
+		// Frost
+		{
+			{"improvedIcyTouch", 3},
+			{"runicPowerMastery", 2},
+			{"toughness", 5},
+			{"icyReach", 2},
+			{"blackIce", 5},
+			{"nervesOfColdSteel", 3},
+			{"icyTalons", 5},
+			{"lichborne", 1},
+			{"annihilation", 3},
+			{"killingMachine", 5},
+			{"chillOfTheGrave", 2},
+			{"endlessWinter", 2},
+			{"frigidDreadplate", 3},
+			{"glacierRot", 3},
+			{"deathchill", 1},
+			{"improvedIcyTalons", 1},
+			{"mercilessCombat", 2},
+			{"rime", 3},
+			{"chilblains", 3},
+			{"hungeringCold", 1},
+			{"improvedFrostPresence", 2},
+			{"threatOfThassarian", 3},
+			{"bloodOfTheNorth", 3},
+			{"unbreakableArmor", 1},
+			{"acclimation", 3},
+			{"frostStrike", 1},
+			{"guileOfGorefiend", 3},
+			{"tundraStalker", 5},
+			{"howlingBlast", 1},
+		},
+		// Unholy
+		{
+			{"viciousStrikes", 2},
+			{"virulence", 3},
+			{"anticipation", 5},
+			{"epidemic", 2},
+			{"morbidity", 3},
+			{"unholyCommand", 2},
+			{"ravenousDead", 3},
+			{"outbreak", 3},
+			{"necrosis", 5},
+			{"corpseExplosion", 1},
+			{"onAPaleHorse", 2},
+			{"bloodCakedBlade", 3},
+			{"nightOfTheDead", 2},
+			{"unholyBlight", 1},
+			{"impurity", 5},
+			{"dirge", 2},
+			{"desecration", 2},
+			{"magicSuppression", 3},
+			{"reaping", 3},
+			{"masterOfGhouls", 1},
+			{"desolation", 5},
+			{"antiMagicZone", 1},
+			{"improvedUnholyPresence", 2},
+			{"ghoulFrenzy", 1},
+			{"cryptFever", 3},
+			{"boneShield", 1},
+			{"wanderingPlague", 3},
+			{"ebonPlaguebringer", 3},
+			{"scourgeStrike", 1},
+			{"rageOfRivendare", 5},
+			{"summonGargoyle", 1},
+		},
+	},
+}
@@ -17,10 +17,41 @@ type Encounter struct {
 	Targets              []*Target
 	TargetUnits          []*Unit
 
+	// Targets which can currently be attacked, in index order. Without phases, this is all targets.
+	ActiveTargets []*Target
+
+	// Scripted timeline for this encounter, may be empty.
+	Phases             []*EncounterPhase
+	nextPhase          int // Index of the next phase to begin.
+	phaseTriggerAction *PendingAction
+	primaryTarget      *Target
+
+	damageTakenMultiplier float64
+	damageDealtMultiplier float64
+
+	// Periods where players have to move.
+	MovementEvents []*MovementEvent
+
+	// Whether the encounter damages the whole raid, see raid_damage.go.
+	hasRaidDamage bool
+
+	// Damage done to targets by each unit this iteration, indexed by UnitIndex.
+	// Only tracked for encounters with phases.
+	unitDamage []float64
+
 	EndFightAtHealth float64
 	// DamgeTaken is used to track health fights instead of duration fights.
-	//  Once primary target has taken its health worth of damage, fight ends.
+	// Only damage to required targets counts, up to their health. Once all
+	// required targets are dead, the fight ends.
 	DamageTaken float64
+
+	numRequiredTargets int
+	numRequiredAlive   int
+
+	retargetPolicy proto.RetargetPolicy
+	targetPriority []*Target
+
+	onTargetDeath []func(sim *Simulation, target *Target)
 	// In health fight: set to true until we get something to base on
 	DurationIsEstimate bool
 
@@ -49,16 +80,6 @@ func NewEncounter(options proto.Encounter) Encounter {
 		executePhase35Begins: DurationFromSeconds(options.Duration * (1 - options.ExecuteProportion_35)),
 		Targets:              []*Target{},
 	}
-	// If UseHealth is set, we use the sum of targets health.
-	if options.UseHealth {
-		for _, t := range options.Targets {
-			encounter.EndFightAtHealth += t.Stats[stats.Health]
-		}
-		if encounter.EndFightAtHealth == 0 {
-			encounter.EndFightAtHealth = 1 // default to something so we don't instantly end without anything.
-		}
-	}
-
 	for targetIndex, targetOptions := range options.Targets {
 		target := NewTarget(*targetOptions, int32(targetIndex))
 		encounter.Targets = append(encounter.Targets, target)
@@ -72,6 +93,17 @@ func NewEncounter(options proto.Encounter) Encounter {
 		encounter.TargetUnits = append(encounter.TargetUnits, &target.Unit)
 	}
 
+	// If UseHealth is set, we use the sum of the required targets' health.
+	encounter.setupTargetHealth(&options)
+	encounter.Phases = newEncounterPhases(options.Phases, encounter.Targets)
+	encounter.MovementEvents = newMovementEvents(options.Movement)
+	encounter.hasRaidDamage = len(options.RaidDamage) > 0
+	for _, target := range encounter.Targets {
+		target.active = true
+	}
+	encounter.ActiveTargets = append([]*Target{}, encounter.Targets...)
+	encounter.primaryTarget = encounter.Targets[0]
+
 	if encounter.EndFightAtHealth > 0 {
 		// Until we pre-sim set duration to 10m
 		encounter.Duration = time.Minute * 10
@@ -87,16 +119,26 @@ func (encounter *Encounter) AOECapMultiplier() float64 {
 	return encounter.aoeCapMultiplier
 }
 func (encounter *Encounter) updateAOECapMultiplier() {
-	encounter.aoeCapMultiplier = MinFloat(10/float64(len(encounter.Targets)), 1)
+	encounter.aoeCapMultiplier = MinFloat(10/float64(MaxInt(1, len(encounter.ActiveTargets))), 1)
 }
 
 func (encounter *Encounter) doneIteration(sim *Simulation) {
+	encounter.doneIterationPhases(sim)
 	for i, _ := range encounter.Targets {
 		target := encounter.Targets[i]
 		target.doneIteration(sim)
 	}
 }
 
+func (encounter *Encounter) mergeMetrics(other *Encounter) {
+	for i, target := range encounter.Targets {
+		otherTarget := other.Targets[i]
+		target.Metrics.merge(&otherTarget.Metrics)
+		target.auraTracker.mergeMetrics(&otherTarget.auraTracker)
+	}
+	encounter.mergePhaseMetrics(other)
+}
+
 func (encounter *Encounter) GetMetricsProto(numIterations int32) *proto.EncounterMetrics {
 	metrics := &proto.EncounterMetrics{
 		Targets: make([]*proto.UnitMetrics, len(encounter.Targets)),
@@ -107,6 +149,9 @@ func (encounter *Encounter) GetMetricsProto(numIterations int32) *proto.Encounte
 		metrics.Targets[i] = target.GetMetricsProto(numIterations)
 		i++
 	}
+	for _, phase := range encounter.Phases {
+		metrics.Phases = append(metrics.Phases, phase.GetMetricsProto(numIterations))
+	}
 
 	return metrics
 }
@@ -115,7 +160,18 @@ func (encounter *Encounter) GetMetricsProto(numIterations int32) *proto.Encounte
 type Target struct {
 	Unit
 
+	ID int32 // NPC ID from the target's config, used to match presets.
+
 	AI TargetAI
+
+	// Whether this target can currently be attacked, see EncounterPhase.
+	active bool
+
+	// Whether this target has died, see target_health.go.
+	dead     bool
+	required bool // Whether this target must die to end a health fight.
+
+	attackTableOverride *proto.TargetAttackTable
 }
 
 func NewTarget(options proto.Target, targetIndex int32) *Target {
@@ -138,13 +194,18 @@ func NewTarget(options proto.Target, targetIndex int32) *Target {
 
 			StatDependencyManager: stats.NewStatDependencyManager(),
 		},
+		ID:                  options.Id,
+		attackTableOverride: options.AttackTable,
+	}
+	if target.stats[stats.Health] > 0 {
+		target.EnableHealthBar()
 	}
 	defaultRaidBossLevel := int32(CharacterLevel + 3)
 	target.GCD = target.NewTimer()
 	if target.Level == 0 {
 		target.Level = defaultRaidBossLevel
 	}
-	if target.stats[stats.MeleeCrit] == 0 {
+	if target.stats[stats.MeleeCrit] == 0 && options.AttackTable == nil {
 		target.stats[stats.MeleeCrit] = UnitLevelFloat64(target.Level, 0.05, 0.052, 0.054, 0.056) * CritRatingPerCritChance
 	}
 
@@ -158,9 +219,11 @@ func NewTarget(options proto.Target, targetIndex int32) *Target {
 	target.PseudoStats.ParryHaste = options.ParryHaste
 	target.PseudoStats.InFrontOfTarget = true
 
-	preset := GetPresetTargetWithID(options.Id)
+	preset := getPresetTargetForConfig(&options)
 	if preset != nil && preset.AI != nil {
 		target.AI = preset.AI()
+	} else if len(options.Abilities) > 0 && abilityAIFactory != nil {
+		target.AI = abilityAIFactory(&options)
 	}
 
 	return target
@@ -176,7 +239,11 @@ func (target *Target) init(sim *Simulation) {
 
 func (target *Target) Reset(sim *Simulation) {
 	target.Unit.reset(sim, nil)
+	target.dead = false
 	//target.SetGCDTimer(sim, 0)
+	if target.AI != nil {
+		target.AI.Reset(sim)
+	}
 }
 
 func (target *Target) Advance(sim *Simulation, elapsedTime time.Duration) {
@@ -187,12 +254,20 @@ func (target *Target) doneIteration(sim *Simulation) {
 	target.Unit.doneIteration(sim)
 }
 
+// Returns the next active target after this one, wrapping around. Returns this
+// target if there are no others.
 func (target *Target) NextTarget() *Target {
-	nextIndex := target.Index + 1
-	if nextIndex >= target.Env.GetNumTargets() {
-		nextIndex = 0
+	next := target
+	for {
+		nextIndex := next.Index + 1
+		if nextIndex >= target.Env.GetNumTargets() {
+			nextIndex = 0
+		}
+		next = target.Env.GetTarget(nextIndex)
+		if next == target || next.active {
+			return next
+		}
 	}
-	return target.Env.GetTarget(nextIndex)
 }
 
 func (target *Target) GetMetricsProto(numIterations int32) *proto.UnitMetrics {
@@ -216,6 +291,8 @@ type AttackTable struct {
 	BaseDodgeChance     float64
 	BaseParryChance     float64
 	BaseGlanceChance    float64
+	BaseCritChance      float64 // Only for enemy attacks, in addition to MeleeCrit.
+	BaseCrushChance     float64
 
 	GlanceMultiplier float64
 	CritSuppression  float64
@@ -268,3 +345,23 @@ func NewAttackTable(attacker *Unit, defender *Unit) *AttackTable {
 
 	return table
 }
+
+// Replaces the level-based chances in this target's attack tables against
+// players, if its config has an attack table.
+func (target *Target) applyAttackTableOverride() {
+	override := target.attackTableOverride
+	if override == nil {
+		return
+	}
+	for _, table := range target.AttackTables {
+		if table.Defender.Type == EnemyUnit {
+			continue
+		}
+		table.BaseMissChance = override.MissChance
+		table.BaseDodgeChance = override.DodgeChance
+		table.BaseParryChance = override.ParryChance
+		table.BaseBlockChance = override.BlockChance
+		table.BaseCritChance = override.CritChance
+		table.BaseCrushChance = override.CrushChance
+	}
+}
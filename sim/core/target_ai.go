@@ -11,21 +11,30 @@ import (
 type TargetAI interface {
 	Initialize(*Target)
 
+	// Called at the start of each iteration.
+	Reset(*Simulation)
+
 	DoAction(*Simulation)
 }
 
+// Crit multiplier of a target's melee attacks.
+const targetMeleeCritMultiplier = 2
+
 func (target *Target) initialize(config *proto.Target) {
-	if config == nil || target.CurrentTarget == nil {
+	if config == nil {
 		return
 	}
 
-	if config.SwingSpeed > 0 {
+	// Auto attacks need someone to tank the target, but AIs can still have
+	// mechanics which affect the rest of the raid.
+	if config.SwingSpeed > 0 && target.CurrentTarget != nil {
 		aaOptions := AutoAttackOptions{
 			MainHand: Weapon{
 				BaseDamageMin:  config.MinBaseDamage,
+				BaseDamageMax:  config.MaxBaseDamage,
 				SwingSpeed:     config.SwingSpeed,
 				SwingDuration:  time.Duration(float64(time.Second) * config.SwingSpeed),
-				CritMultiplier: 2,
+				CritMultiplier: targetMeleeCritMultiplier,
 				SpellSchool:    SpellSchoolFromProto(config.SpellSchool),
 			},
 			AutoSwingMelee: true,
@@ -59,6 +68,44 @@ func (target *Target) initialize(config *proto.Target) {
 	}
 }
 
+// Registers an extra melee attack which this target makes on a timer, see
+// proto.SecondarySwing. If tank is nil, the target's current target is hit.
+func (target *Target) registerSecondarySwing(config *proto.SecondarySwing, tank *Unit) {
+	if config.Interval <= 0 {
+		return
+	}
+
+	spell := target.RegisterSpell(SpellConfig{
+		ActionID:    ActionID{SpellID: config.SpellId},
+		SpellSchool: SpellSchoolPhysical,
+		ProcMask:    ProcMaskMeleeMHSpecial,
+		Flags:       SpellFlagMeleeMetrics,
+
+		DamageMultiplier: 1,
+		CritMultiplier:   targetMeleeCritMultiplier,
+
+		ApplyEffects: func(sim *Simulation, unit *Unit, spell *Spell) {
+			baseDamage := sim.Roll(config.MinDamage, config.MaxDamage)
+			spell.CalcAndDealDamageEnemyMeleeWhite(sim, unit, baseDamage)
+		},
+	})
+
+	target.RegisterResetEffect(func(sim *Simulation) {
+		StartPeriodicAction(sim, PeriodicActionOptions{
+			Period: DurationFromSeconds(config.Interval),
+			OnAction: func(sim *Simulation) {
+				victim := tank
+				if victim == nil {
+					victim = target.CurrentTarget
+				}
+				if victim != nil && target.active && !target.IsCasting(sim) {
+					spell.Cast(sim, victim)
+				}
+			},
+		})
+	})
+}
+
 // Empty Agent interface functions.
 func (target *Target) AddRaidBuffs(raidBuffs *proto.RaidBuffs)    {}
 func (target *Target) AddPartyBuffs(partyBuffs *proto.PartyBuffs) {}
@@ -72,13 +119,18 @@ func (target *Target) DoNothing() {
 }
 
 func (target *Target) OnAutoAttack(sim *Simulation, spell *Spell) {
-	if target.GCD.IsReady(sim) {
+	if target.GCD.IsReady(sim) && target.active {
 		if target.AI != nil {
 			target.AI.DoAction(sim)
 		}
 	}
 }
 func (target *Target) OnGCDReady(sim *Simulation) {
+	if !target.active {
+		// Wait to be activated by an encounter phase.
+		target.DoNothing()
+		return
+	}
 	if target.AI != nil {
 		target.AI.DoAction(sim)
 	}
@@ -86,6 +138,14 @@ func (target *Target) OnGCDReady(sim *Simulation) {
 
 type AIFactory func() TargetAI
 
+// Creates an AI from the abilities in a target's config, see proto.TargetAbility.
+// Registered by the encounters package, which can't be imported from here.
+var abilityAIFactory func(config *proto.Target) TargetAI
+
+func RegisterAbilityAIFactory(factory func(config *proto.Target) TargetAI) {
+	abilityAIFactory = factory
+}
+
 type PresetTarget struct {
 	// String in folder-structure format identifying a category for this unit, e.g. "Black Temple/Bosses".
 	PathPrefix string
@@ -140,7 +200,24 @@ func GetPresetTargetWithID(id int32) *PresetTarget {
 	return nil
 }
 
+// Returns the preset matching a target config. The 10 and 25 man versions of a
+// boss share an NPC ID, so this matches on name as well, and only falls back to
+// the ID for customized targets.
+func getPresetTargetForConfig(config *proto.Target) *PresetTarget {
+	for i, _ := range presetTargets {
+		preset := &presetTargets[i]
+		if preset.Config.Id == config.Id && preset.Config.Name == config.Name {
+			return preset
+		}
+	}
+	return GetPresetTargetWithID(config.Id)
+}
+
 func AddPresetEncounter(name string, targetPaths []string) {
+	AddPresetEncounterWithPhases(name, targetPaths, nil)
+}
+
+func AddPresetEncounterWithPhases(name string, targetPaths []string, phases []*proto.EncounterPhase) {
 	if len(targetPaths) == 0 {
 		log.Fatalf("Encounter must have targets!")
 	}
@@ -169,5 +246,6 @@ func AddPresetEncounter(name string, targetPaths []string) {
 	presetEncounters = append(presetEncounters, &proto.PresetEncounter{
 		Path:    path,
 		Targets: targetProtos,
+		Phases:  phases,
 	})
 }
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Sets up per-target health tracking. Targets with health always track it, so
+// it can be used by phase triggers, but they can only die in health fights.
+func (encounter *Encounter) setupTargetHealth(options *proto.Encounter) {
+	getTarget := func(field string, index int32) *Target {
+		if index < 0 || int(index) >= len(encounter.Targets) {
+			panic(fmt.Sprintf("Encounter %s refers to invalid target index %d", field, index))
+		}
+		return encounter.Targets[index]
+	}
+
+	for _, target := range encounter.Targets {
+		if options.UseHealth && !target.HasHealthBar() {
+			target.EnableHealthBar()
+		}
+	}
+
+	encounter.retargetPolicy = options.RetargetPolicy
+	for _, index := range options.TargetPriority {
+		encounter.targetPriority = append(encounter.targetPriority, getTarget("target priority", index))
+	}
+
+	if !options.UseHealth {
+		return
+	}
+	if len(options.RequiredTargets) == 0 {
+		for _, target := range encounter.Targets {
+			target.required = true
+		}
+	}
+	for _, index := range options.RequiredTargets {
+		getTarget("required targets", index).required = true
+	}
+	for _, target := range encounter.Targets {
+		if target.required {
+			encounter.numRequiredTargets++
+			encounter.EndFightAtHealth += target.MaxHealth()
+		}
+	}
+	if encounter.EndFightAtHealth == 0 {
+		encounter.EndFightAtHealth = 1 // default to something so we don't instantly end without anything.
+	}
+}
+
+// Registers a callback for whenever a target dies. Must be called during
+// initialization.
+func (encounter *Encounter) RegisterTargetDeathCallback(callback func(sim *Simulation, target *Target)) {
+	encounter.onTargetDeath = append(encounter.onTargetDeath, callback)
+}
+
+func (encounter *Encounter) resetTargetHealth() {
+	encounter.DamageTaken = 0
+	encounter.numRequiredAlive = encounter.numRequiredTargets
+}
+
+// Whether a health fight is over. This is when all required targets are dead,
+// or nothing is left to attack.
+func (encounter *Encounter) healthFightOver() bool {
+	return encounter.numRequiredAlive == 0 ||
+		(len(encounter.ActiveTargets) == 0 && encounter.nextPhase >= len(encounter.Phases))
+}
+
+// Called whenever a target takes damage, to track its health.
+func (encounter *Encounter) onTargetDamaged(sim *Simulation, attacker *Unit, unit *Unit, damage float64) {
+	target := encounter.Targets[unit.Index]
+	if int(attacker.UnitIndex) < len(encounter.unitDamage) {
+		encounter.unitDamage[attacker.UnitIndex] += damage
+	}
+
+	if target.HasHealthBar() && !target.dead {
+		if target.required {
+			encounter.DamageTaken += MinFloat(damage, target.CurrentHealth())
+		}
+		target.RemoveHealth(sim, damage)
+
+		if encounter.EndFightAtHealth > 0 && damage > 0 && target.CurrentHealth() <= 0 {
+			encounter.killTarget(sim, target)
+		}
+	}
+
+	if encounter.nextPhase < len(encounter.Phases) && encounter.Phases[encounter.nextPhase].TriggerTarget == target {
+		encounter.checkPhaseTriggers(sim)
+	}
+}
+
+func (encounter *Encounter) killTarget(sim *Simulation, target *Target) {
+	if sim.Log != nil {
+		target.Log(sim, "Died.")
+	}
+
+	target.dead = true
+	if target.required {
+		encounter.numRequiredAlive--
+	}
+	encounter.setTargetActive(sim, target, false)
+	encounter.updateActiveTargets()
+
+	newTarget := encounter.RetargetFrom(target)
+	for _, unit := range sim.Raid.AllUnits {
+		if unit.CurrentTarget == &target.Unit && newTarget != nil {
+			unit.CurrentTarget = &newTarget.Unit
+		}
+	}
+
+	// This can happen in the middle of a spell or DoT tick, so remove the
+	// target's auras and notify listeners afterwards.
+	sim.AddPendingAction(&PendingAction{
+		NextActionAt: sim.CurrentTime,
+		Priority:     ActionPriorityPhase,
+		OnAction: func(sim *Simulation) {
+			target.auraTracker.expireAll(sim)
+			for _, callback := range encounter.onTargetDeath {
+				callback(sim, target)
+			}
+		},
+	})
+}
+
+// Returns the target players should switch to when target is no longer
+// attackable, based on the encounter's retarget policy. Returns nil if there is
+// nothing left to attack.
+func (encounter *Encounter) RetargetFrom(target *Target) *Target {
+	if len(encounter.ActiveTargets) == 0 {
+		return nil
+	}
+
+	switch encounter.retargetPolicy {
+	case proto.RetargetPolicy_RetargetLowestHealth:
+		var lowest *Target
+		for _, other := range encounter.ActiveTargets {
+			if !other.HasHealthBar() {
+				continue
+			}
+			if lowest == nil || other.CurrentHealth() < lowest.CurrentHealth() {
+				lowest = other
+			}
+		}
+		if lowest != nil {
+			return lowest
+		}
+	case proto.RetargetPolicy_RetargetPriority:
+		for _, other := range encounter.targetPriority {
+			if other.active {
+				return other
+			}
+		}
+	}
+
+	if next := target.NextTarget(); next.active {
+		return next
+	}
+	return encounter.ActiveTargets[0]
+}
+
+// Proportion (0-1) of this target's health remaining, or 1 if it has no health.
+func (target *Target) HealthProportion() float64 {
+	if target.dead {
+		return 0
+	}
+	if !target.HasHealthBar() || target.MaxHealth() <= 0 {
+		return 1
+	}
+	return target.CurrentHealthPercent()
+}
+
+// Whether this target has died, in a health fight.
+func (target *Target) IsDead() bool {
+	return target.dead
+}
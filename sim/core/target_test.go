@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+// Runs a sim where the fake player tanks a target with a secondary swing, and
+// returns the swing's metrics against the player.
+func runSecondarySwing(t *testing.T, attackTable *proto.TargetAttackTable) SpellMetrics {
+	request := fakeSimRequest()
+	request.Raid.Tanks = []*proto.RaidTarget{{TargetIndex: 0}}
+	request.Encounter.Targets[0].AttackTable = attackTable
+	request.Encounter.Targets[0].SecondarySwings = []*proto.SecondarySwing{
+		{SpellId: 59192, MinDamage: 10000, MaxDamage: 10000, Interval: 1.2, TankIndex: 0},
+	}
+	sim := NewSim(*request)
+	sim.Reset()
+	// The fake player has no rotation.
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	fa.gcdAction.Cancel(sim)
+	target := sim.GetTargetUnit(0)
+
+	table := target.AttackTables[fa.UnitIndex]
+	if table.BaseMissChance != attackTable.MissChance || table.BaseCritChance != attackTable.CritChance || table.BaseCrushChance != attackTable.CrushChance {
+		t.Fatalf("Expected the target's attack table to be overridden, got %+v", table)
+	}
+
+	runPendingActionsUntil(sim, time.Second*12)
+	metrics := target.GetSpell(ActionID{SpellID: 59192}).SpellMetrics[fa.UnitIndex]
+	if metrics.Casts != 10 {
+		t.Fatalf("Expected the secondary swing every 1.2s, got %d swings in 12s", metrics.Casts)
+	}
+	return metrics
+}
+
+func TestTargetAttackTableOverride(t *testing.T) {
+	allMiss := runSecondarySwing(t, &proto.TargetAttackTable{MissChance: 1})
+	if allMiss.Misses != allMiss.Casts || allMiss.TotalDamage != 0 {
+		t.Fatalf("Expected every swing to miss, got %d misses dealing %0.0f damage", allMiss.Misses, allMiss.TotalDamage)
+	}
+
+	crushing := runSecondarySwing(t, &proto.TargetAttackTable{CrushChance: 1})
+	if crushing.Crushes != crushing.Casts || crushing.TotalDamage != float64(crushing.Casts)*15000 {
+		t.Fatalf("Expected every swing to crush for 15000, got %d crushes dealing %0.0f damage", crushing.Crushes, crushing.TotalDamage)
+	}
+
+	// Secondary swings crit for double damage, like auto attacks.
+	critting := runSecondarySwing(t, &proto.TargetAttackTable{CritChance: 1})
+	if critting.Crits != critting.Casts || critting.TotalDamage != float64(critting.Casts)*20000 {
+		t.Fatalf("Expected every swing to crit for 20000, got %d crits dealing %0.0f damage", critting.Crits, critting.TotalDamage)
+	}
+}
+
+func TestEnemyWeaponDamageRange(t *testing.T) {
+	sim := SetupFakeSim()
+	weapon := Weapon{BaseDamageMin: 1000, BaseDamageMax: 3000}
+
+	maxDamage := 0.0
+	for i := 0; i < 100; i++ {
+		damage := weapon.EnemyWeaponDamage(sim, 0)
+		if damage < 1000 || damage > 3000 {
+			t.Fatalf("Expected damage between 1000 and 3000, got %0.1f", damage)
+		}
+		maxDamage = MaxFloat(maxDamage, damage)
+	}
+	// Without a max, damage is at most 135% of the min.
+	if maxDamage <= 1350 {
+		t.Fatalf("Expected the max damage to widen the range, got at most %0.1f", maxDamage)
+	}
+}
@@ -53,6 +53,13 @@ type Unit struct {
 	// for calculating spell travel time for certain spells.
 	DistanceFromTarget float64
 
+	// Movement events which affect this unit, see movement.go.
+	movementEvents []*MovementEvent
+
+	// Spell used to interrupt enemy casts, see interrupt.go.
+	interruptSpell *Spell
+	canInterrupt   func(*Simulation) bool
+
 	// Environment in which this Unit exists. This will be nil until after the
 	// construction phase.
 	Env *Environment
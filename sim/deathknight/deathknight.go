@@ -153,6 +153,8 @@ type Deathknight struct {
 
 	DeathPact *RuneSpell
 
+	MindFreeze *core.Spell
+
 	// Diseases
 	FrostFeverSpell     *RuneSpell
 	BloodPlagueSpell    *RuneSpell
@@ -271,6 +273,7 @@ func (dk *Deathknight) Initialize() {
 	dk.registerVampiricBloodSpell()
 	dk.registerAntiMagicShellSpell()
 	dk.registerRuneStrikeSpell()
+	dk.registerMindFreezeSpell()
 
 	dk.registerRaiseDeadCD()
 	dk.registerSummonGargoyleCD()
@@ -109,7 +109,7 @@ func (dk *DpsDeathknight) SetupRotations() {
 				dk.Rotation.UseDeathAndDecay = true
 			} else {
 				// 2h
-				if dk.Env.GetNumTargets() > 1 {
+				if dk.Env.GetNumActiveTargets() > 1 {
 					dk.Rotation.BloodRuneFiller = proto.Deathknight_Rotation_BloodBoil
 					dk.Rotation.UseDeathAndDecay = true
 				} else {
@@ -76,5 +76,5 @@ func (dk *DpsDeathknight) shRecastAvailableCheck(expiresAt time.Duration, afterC
 }
 
 func (dk *DpsDeathknight) shShouldSpreadDisease(sim *core.Simulation) bool {
-	return dk.sr.recastedFF && dk.sr.recastedBP && dk.Env.GetNumTargets() > 1
+	return dk.sr.recastedFF && dk.sr.recastedBP && dk.Env.GetNumActiveTargets() > 1
 }
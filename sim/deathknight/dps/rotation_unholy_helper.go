@@ -45,7 +45,7 @@ func (dk *DpsDeathknight) getSecondDiseaseAction() deathknight.RotationAction {
 
 func (dk *DpsDeathknight) getBloodRuneAction(isFirst bool) deathknight.RotationAction {
 	if isFirst {
-		if dk.Env.GetNumTargets() > 1 {
+		if dk.Env.GetNumActiveTargets() > 1 {
 			return dk.RotationActionCallback_Pesti
 		} else {
 			return dk.RotationActionCallback_BS
@@ -48,6 +48,9 @@ func (fb *focusBar) AddFocus(sim *core.Simulation, amount float64, actionID core
 	if sim.Log != nil {
 		fb.ghoulPet.Log(sim, "Gained %0.3f focus from %s (%0.3f --> %0.3f).", amount, actionID, fb.currentFocus, newFocus)
 	}
+	if sim.EventLog != nil {
+		fb.ghoulPet.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceGained, proto.ResourceType_ResourceTypeFocus, actionID, amount, fb.currentFocus, newFocus)
+	}
 
 	fb.currentFocus = newFocus
 
@@ -66,6 +69,9 @@ func (fb *focusBar) SpendFocus(sim *core.Simulation, amount float64, actionID co
 	if sim.Log != nil {
 		fb.ghoulPet.Log(sim, "Spent %0.3f focus from %s (%0.3f --> %0.3f).", amount, actionID, fb.currentFocus, newFocus)
 	}
+	if sim.EventLog != nil {
+		fb.ghoulPet.LogResourceEvent(sim, proto.CombatLogEventType_CombatLogEventTypeResourceSpent, proto.ResourceType_ResourceTypeFocus, actionID, amount, fb.currentFocus, newFocus)
+	}
 
 	fb.currentFocus = newFocus
 }
@@ -81,7 +81,7 @@ func (dk *Deathknight) registerHeartStrikeSpell() {
 	}
 
 	dk.HeartStrike = dk.newHeartStrikeSpell(true, false, func(sim *core.Simulation, spell *core.Spell, spellEffect *core.SpellEffect) {
-		if dk.Env.GetNumTargets() > 1 {
+		if dk.Env.GetNumActiveTargets() > 1 {
 			dk.HeartStrikeOffHit.Cast(sim, dk.Env.NextTargetUnit(dk.CurrentTarget))
 		}
 		dk.LastOutcome = spellEffect.Outcome
@@ -91,7 +91,7 @@ func (dk *Deathknight) registerHeartStrikeSpell() {
 
 func (dk *Deathknight) registerDrwHeartStrikeSpell() {
 	dk.RuneWeapon.HeartStrike = dk.newHeartStrikeSpell(true, true, func(sim *core.Simulation, spell *core.Spell, spellEffect *core.SpellEffect) {
-		if dk.Env.GetNumTargets() > 1 {
+		if dk.Env.GetNumActiveTargets() > 1 {
 			dk.RuneWeapon.HeartStrikeOffHit.Cast(sim, dk.Env.NextTargetUnit(dk.CurrentTarget))
 		}
 	}).Spell
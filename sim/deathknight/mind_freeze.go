@@ -0,0 +1,37 @@
+package deathknight
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func (dk *Deathknight) registerMindFreezeSpell() {
+	config := core.SpellConfig{
+		ActionID: core.ActionID{SpellID: 47528},
+
+		Cast: core.CastConfig{
+			IgnoreHaste: true,
+			CD: core.Cooldown{
+				Timer:    dk.NewTimer(),
+				Duration: time.Second * 10,
+			},
+		},
+	}
+
+	// Endless Winter reduces the cost by 50% per point.
+	if rpCost := uint8(10 - 5*dk.Talents.EndlessWinter); rpCost > 0 {
+		baseCost := float64(core.NewRuneCost(rpCost, 0, 0, 0, 0))
+		config.ResourceType = stats.RunicPower
+		config.BaseCost = baseCost
+		config.Cast.DefaultCast.Cost = baseCost
+
+		// The cast system only checks runic power, so spend it here.
+		config.ApplyEffects = func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			dk.SpendRuneCost(sim, spell, core.RuneCost(spell.CurCast.Cost))
+		}
+	}
+
+	dk.MindFreeze = dk.RegisterInterruptSpell(config, nil)
+}
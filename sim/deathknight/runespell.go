@@ -114,6 +114,8 @@ func (dk *Deathknight) RegisterSpell(rs *RuneSpell, spellConfig core.SpellConfig
 }
 
 // withRuneRefund is a wrapper around spell effects that on a miss provides a rune refund.
+// AOE effects are made for every target, and skip those which aren't active
+// when the spell is cast. The refund uses the outcome on the first active target.
 func (dk *Deathknight) withRuneRefund(rs *RuneSpell, baseEffect core.SpellEffect, isAOE bool) core.ApplySpellEffects {
 	var baseEffects []core.SpellEffect
 	if isAOE && dk.Env.GetNumTargets() > 1 {
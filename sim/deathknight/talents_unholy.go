@@ -94,7 +94,7 @@ func (dk *Deathknight) applyWanderingPlague() {
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			baseDamage := dk.LastDiseaseDamage * damageMulti
 			baseDamage *= sim.Encounter.AOECapMultiplier()
-			for _, aoeTarget := range sim.Encounter.Targets {
+			for _, aoeTarget := range sim.Encounter.ActiveTargets {
 				spell.CalcAndDealDamageAlwaysHit(sim, &aoeTarget.Unit, baseDamage)
 			}
 		},
@@ -16,6 +16,12 @@ func (moonkin *BalanceDruid) tryUseGCD(sim *core.Simulation) {
 
 	spell = moonkin.rotation(sim)
 
+	// Wrath and Starfire can't be cast while moving, so keep the dots up and
+	// otherwise refresh Moonfire.
+	if !spell.FinishesBeforeMovement(sim) {
+		spell = moonkin.instantWhileMoving(sim)
+	}
+
 	if success := spell.Cast(sim, moonkin.CurrentTarget); !success {
 		moonkin.WaitForMana(sim, spell.CurCast.Cost)
 	}
@@ -108,6 +114,15 @@ func (moonkin *BalanceDruid) rotation(sim *core.Simulation) *core.Spell {
 	return moonkin.Starfire
 }
 
+func (moonkin *BalanceDruid) instantWhileMoving(sim *core.Simulation) *core.Spell {
+	if moonkin.Starfall.IsReady(sim) {
+		return moonkin.Starfall
+	} else if moonkin.Rotation.UseIs && !moonkin.InsectSwarmDot.IsActive() {
+		return moonkin.InsectSwarm
+	}
+	return moonkin.Moonfire
+}
+
 func (moonkin *BalanceDruid) castMajorCooldown(mcd *core.MajorCooldown, sim *core.Simulation, target *core.Unit) {
 	if mcd != nil && mcd.Spell.IsReady(sim) {
 		isOffensivePotion := mcd.Spell.SameAction(core.ActionID{ItemID: 40211}) || mcd.Spell.SameAction(core.ActionID{ItemID: 40212})
@@ -57,5 +57,5 @@ func (druid *Druid) registerHurricaneSpell() {
 }
 
 func (druid *Druid) ShouldCastHurricane(sim *core.Simulation, rotation proto.BalanceDruid_Rotation) bool {
-	return len(druid.Env.Encounter.Targets) > 1 && druid.Hurricane.IsReady(sim)
+	return len(druid.Env.Encounter.ActiveTargets) > 1 && druid.Hurricane.IsReady(sim)
 }
@@ -17,8 +17,13 @@ func (druid *Druid) registerStarfallSpell() {
 
 	baseCost := druid.BaseMana * 0.35
 	target := druid.CurrentTarget
-	numberOfTicks := core.TernaryInt(druid.Env.GetNumTargets() > 1, 20, 10)
-	tickLength := core.TernaryDuration(druid.Env.GetNumTargets() > 1, time.Millisecond*500, time.Millisecond*1000)
+	// Stars fall twice as often while there are other targets around.
+	numberOfTicks := func(sim *core.Simulation) int {
+		return core.TernaryInt(sim.GetNumActiveTargets() > 1, 20, 10)
+	}
+	tickLength := func(sim *core.Simulation) time.Duration {
+		return core.TernaryDuration(sim.GetNumActiveTargets() > 1, time.Millisecond*500, time.Millisecond*1000)
+	}
 
 	// Nature's Majesty
 	naturesMajestyCritBonus := druid.TalentsBonuses.naturesMajestyBonusCrit
@@ -50,8 +55,12 @@ func (druid *Druid) registerStarfallSpell() {
 			OutcomeApplier: druid.OutcomeFuncMagicHit(),
 			OnSpellHitDealt: func(sim *core.Simulation, spell *core.Spell, spellEffect *core.SpellEffect) {
 				if spellEffect.Landed() {
-					druid.StarfallDot.Apply(sim)
-					druid.StarfallDotSplash.Apply(sim)
+					for _, dot := range []*core.Dot{druid.StarfallDot, druid.StarfallDotSplash} {
+						dot.NumberOfTicks = numberOfTicks(sim)
+						dot.TickLength = tickLength(sim)
+						dot.RecomputeAuraDuration()
+						dot.Apply(sim)
+					}
 				}
 			},
 		}),
@@ -72,8 +81,8 @@ func (druid *Druid) registerStarfallSpell() {
 			Label:    "Starfall-" + strconv.Itoa(int(druid.Index)),
 			ActionID: core.ActionID{SpellID: 53201},
 		}),
-		NumberOfTicks: numberOfTicks,
-		TickLength:    tickLength,
+		NumberOfTicks: 10,
+		TickLength:    time.Second,
 		TickEffects: core.TickFuncApplyEffects(func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			baseDamage := sim.Roll(563, 653) + 0.3*spell.SpellPower()
 			spell.CalcAndDealDamageMagicHitAndCrit(sim, target, baseDamage)
@@ -86,12 +95,12 @@ func (druid *Druid) registerStarfallSpell() {
 			Label:    "StarfallSplash-" + strconv.Itoa(int(druid.Index)),
 			ActionID: core.ActionID{SpellID: 53190},
 		}),
-		NumberOfTicks: numberOfTicks,
-		TickLength:    tickLength,
+		NumberOfTicks: 10,
+		TickLength:    time.Second,
 		TickEffects: core.TickFuncApplyEffects(func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			baseDamage := 101 + 0.13*spell.SpellPower()
 			baseDamage *= sim.Encounter.AOECapMultiplier()
-			for _, aoeTarget := range sim.Encounter.Targets {
+			for _, aoeTarget := range sim.Encounter.ActiveTargets {
 				spell.CalcAndDealDamageMagicHitAndCrit(sim, &aoeTarget.Unit, baseDamage)
 			}
 		}),
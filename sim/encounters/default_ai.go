@@ -6,6 +6,9 @@ import (
 	"github.com/wowsims/wotlk/sim/core"
 )
 
+// How long to wait before trying a timed ability again, if it wasn't used.
+const timedAbilityRetryDelay = time.Second
+
 // Default implementation of TargetAI which takes a list of abilities as input
 // in order of priority.
 type DefaultAI struct {
@@ -21,6 +24,11 @@ type TargetAbility struct {
 	// Probability (0-1) that this ability will be used when available.
 	ChanceToUse float64
 
+	// If true, this ability is used as soon as it's ready instead of during the
+	// target's auto attacks. Use this for raid-wide mechanics, which happen even
+	// when nobody is tanking the target.
+	Timed bool
+
 	// Factory function for creating the spell. Can use this or supply Spell
 	// directly.
 	MakeSpell func(*core.Target) *core.Spell
@@ -47,19 +55,57 @@ func (ai *DefaultAI) Initialize(target *core.Target) {
 	}
 }
 
+func (ai *DefaultAI) Reset(sim *core.Simulation) {
+	for i, _ := range ai.Abilities {
+		ability := &ai.Abilities[i]
+		if !ability.Timed {
+			continue
+		}
+
+		pa := &core.PendingAction{
+			NextActionAt: ability.InitialCD,
+		}
+		pa.OnAction = func(sim *core.Simulation) {
+			if ai.Target.IsActive() && !ai.Target.IsCasting(sim) && ability.Spell.IsReady(sim) && ai.shouldUse(sim, ability) {
+				ability.Spell.Cast(sim, ai.spellTarget())
+			}
+			pa.NextActionAt = core.MaxDuration(ability.Spell.ReadyAt(), sim.CurrentTime+timedAbilityRetryDelay)
+			sim.AddPendingAction(pa)
+		}
+		sim.AddPendingAction(pa)
+	}
+}
+
 func (ai *DefaultAI) DoAction(sim *core.Simulation) {
 	for _, ability := range ai.Abilities {
+		if ability.Timed {
+			continue
+		}
+
 		if sim.CurrentTime < ability.InitialCD {
 			continue
 		}
 
-		if !ability.Spell.IsReady(sim) {
+		if !ability.Spell.IsReady(sim) || ai.Target.IsCasting(sim) {
 			continue
 		}
 
-		if ability.ChanceToUse == 1 || sim.RandomFloat("TargetAbility") < ability.ChanceToUse {
+		if ai.shouldUse(sim, &ability) {
 			ability.Spell.Cast(sim, ai.Target.CurrentTarget)
 			return
 		}
 	}
 }
+
+func (ai *DefaultAI) shouldUse(sim *core.Simulation, ability *TargetAbility) bool {
+	return ability.ChanceToUse == 1 || sim.RandomFloat("TargetAbility") < ability.ChanceToUse
+}
+
+// Timed abilities may be used while nobody is tanking, in which case they're
+// cast on the target itself. Their effects should pick their own targets.
+func (ai *DefaultAI) spellTarget() *core.Unit {
+	if ai.Target.CurrentTarget != nil {
+		return ai.Target.CurrentTarget
+	}
+	return &ai.Target.Unit
+}
@@ -0,0 +1,337 @@
+package encounters
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Encounters defined as data instead of code. Each file holds a
+// proto.EncounterDefinitions in protojson format.
+//
+//go:embed data/*.json
+var definitionFiles embed.FS
+
+func init() {
+	core.RegisterAbilityAIFactory(NewAbilityAI)
+}
+
+// Registers the encounters in the data folder as presets.
+func RegisterDefinitions() {
+	entries, err := definitionFiles.ReadDir("data")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := definitionFiles.ReadFile("data/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		if err := LoadDefinitions(data); err != nil {
+			panic(fmt.Sprintf("Invalid encounter definitions in %s: %s", entry.Name(), err))
+		}
+	}
+}
+
+// Registers the encounters from all .json files in dir as presets, for adding
+// encounters without rebuilding the sim.
+func LoadDefinitionsFromDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := LoadDefinitions(data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Registers the encounters from a protojson proto.EncounterDefinitions as presets.
+// Target stats may be given as an object keyed by stat name, e.g.
+// {"Armor": 10643, "Health": 9761500}, instead of an array of every stat.
+func LoadDefinitions(data []byte) error {
+	data, err := convertNamedStats(data)
+	if err != nil {
+		return err
+	}
+	definitions := &proto.EncounterDefinitions{}
+	if err := protojson.Unmarshal(data, definitions); err != nil {
+		return err
+	}
+
+	// Paths of the targets in this batch, which aren't registered until they are all valid.
+	targetPaths := make(map[string]bool)
+	for _, definition := range definitions.Encounters {
+		if err := validateDefinition(definition, targetPaths); err != nil {
+			return fmt.Errorf("encounter %s: %w", definition.Name, err)
+		}
+	}
+	for _, definition := range definitions.Encounters {
+		registerDefinition(definition)
+	}
+	return nil
+}
+
+// Replaces target stats which are keyed by name with the array protojson
+// expects. Anything else is left for protojson to parse, or reject.
+func convertNamedStats(data []byte) ([]byte, error) {
+	var definitions map[string]json.RawMessage
+	var encounters []map[string]json.RawMessage
+	if json.Unmarshal(data, &definitions) != nil || json.Unmarshal(definitions["encounters"], &encounters) != nil {
+		return data, nil
+	}
+
+	for _, encounter := range encounters {
+		var targets []map[string]json.RawMessage
+		if json.Unmarshal(encounter["targets"], &targets) != nil {
+			continue
+		}
+		for _, target := range targets {
+			var namedStats map[string]float64
+			if json.Unmarshal(target["stats"], &namedStats) != nil {
+				continue
+			}
+			statsArray, err := statsFromNames(namedStats)
+			if err != nil {
+				return nil, err
+			}
+			target["stats"], _ = json.Marshal(statsArray)
+		}
+		encounter["targets"], _ = json.Marshal(targets)
+	}
+	definitions["encounters"], _ = json.Marshal(encounters)
+	return json.Marshal(definitions)
+}
+
+func statsFromNames(namedStats map[string]float64) ([]float64, error) {
+	statsArray := make([]float64, stats.Len)
+	for name, value := range namedStats {
+		found := false
+		for stat := stats.Stat(0); stat < stats.Len; stat++ {
+			if stat.StatName() == name {
+				statsArray[stat] = value
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown stat %s", name)
+		}
+	}
+	return statsArray, nil
+}
+
+func validateDefinition(definition *proto.EncounterDefinition, targetPaths map[string]bool) error {
+	if definition.PathPrefix == "" || definition.Name == "" {
+		return fmt.Errorf("missing path prefix or name")
+	}
+	if len(definition.Targets) == 0 {
+		return fmt.Errorf("no targets")
+	}
+	for _, target := range definition.Targets {
+		if target.Name == "" || target.Id == 0 {
+			return fmt.Errorf("target without a name or NPC ID")
+		}
+		path := definition.PathPrefix + "/" + target.Name
+		if targetPaths[path] || core.GetPresetTargetWithPath(path) != nil {
+			return fmt.Errorf("target %s already exists", path)
+		}
+		targetPaths[path] = true
+		for _, ability := range target.Abilities {
+			if ability.MaxDamage < ability.MinDamage {
+				return fmt.Errorf("ability %d of %s has max damage below min damage", ability.SpellId, target.Name)
+			}
+			if ability.CastTime < 0 {
+				return fmt.Errorf("ability %d of %s has a negative cast time", ability.SpellId, target.Name)
+			}
+		}
+		if target.MaxBaseDamage != 0 && target.MaxBaseDamage < target.MinBaseDamage {
+			return fmt.Errorf("%s has max base damage below min base damage", target.Name)
+		}
+		for _, swing := range target.SecondarySwings {
+			if swing.MaxDamage < swing.MinDamage {
+				return fmt.Errorf("secondary swing %d of %s has max damage below min damage", swing.SpellId, target.Name)
+			}
+			if swing.Interval <= 0 {
+				return fmt.Errorf("secondary swing %d of %s has no interval", swing.SpellId, target.Name)
+			}
+		}
+	}
+	for _, phase := range definition.Phases {
+		for _, index := range phaseTargetIndices(phase) {
+			if index < 0 || int(index) >= len(definition.Targets) {
+				return fmt.Errorf("phase %s refers to invalid target index %d", phase.Name, index)
+			}
+		}
+	}
+	return nil
+}
+
+func phaseTargetIndices(phase *proto.EncounterPhase) []int32 {
+	indices := append([]int32{phase.TriggerTargetIndex, phase.PrimaryTargetIndex}, phase.ActivateTargets...)
+	return append(indices, phase.DeactivateTargets...)
+}
+
+func registerDefinition(definition *proto.EncounterDefinition) {
+	var targetPaths []string
+	for i, target := range definition.Targets {
+		preset := core.PresetTarget{
+			PathPrefix: definition.PathPrefix,
+			Config:     *target,
+		}
+		if i == 0 && len(definition.Phases) > 0 {
+			preset.AI = newDefinitionAI(definition)
+		} else if len(target.Abilities) > 0 {
+			config := target
+			preset.AI = func() core.TargetAI {
+				return NewAbilityAI(config)
+			}
+		}
+		core.AddPresetTarget(preset)
+		targetPaths = append(targetPaths, preset.Path())
+	}
+	core.AddPresetEncounterWithPhases(definition.Name, targetPaths, definition.Phases)
+}
+
+// AI for the boss of a data-driven encounter. Also sets up the encounter's
+// phases, when simming the whole encounter.
+type definitionAI struct {
+	DefaultAI
+
+	definition *proto.EncounterDefinition
+}
+
+func newDefinitionAI(definition *proto.EncounterDefinition) core.AIFactory {
+	return func() core.TargetAI {
+		return &definitionAI{
+			DefaultAI:  DefaultAI{Abilities: abilitiesFromConfig(definition.Targets[0])},
+			definition: definition,
+		}
+	}
+}
+
+func (ai *definitionAI) Initialize(target *core.Target) {
+	ai.DefaultAI.Initialize(target)
+
+	encounterTargets := target.Env.Encounter.Targets
+	if len(encounterTargets) != len(ai.definition.Targets) {
+		return
+	}
+	for i, config := range ai.definition.Targets {
+		if encounterTargets[i].ID != config.Id {
+			return
+		}
+	}
+	target.Env.Encounter.SetDefaultPhases(ai.definition.Phases)
+}
+
+// Creates an AI which uses the abilities from a target's config.
+func NewAbilityAI(config *proto.Target) core.TargetAI {
+	return &DefaultAI{
+		Abilities: abilitiesFromConfig(config),
+	}
+}
+
+func abilitiesFromConfig(config *proto.Target) []TargetAbility {
+	var abilities []TargetAbility
+	for _, abilityConfig := range config.Abilities {
+		abilityConfig := abilityConfig
+		chanceToUse := abilityConfig.ChanceToUse
+		if chanceToUse == 0 {
+			chanceToUse = 1
+		}
+		abilities = append(abilities, TargetAbility{
+			InitialCD:   core.DurationFromSeconds(abilityConfig.InitialCooldown),
+			ChanceToUse: chanceToUse,
+			Timed:       abilityConfig.Targeting != proto.TargetAbilityTargeting_TargetAbilityTargetingTank,
+			MakeSpell: func(target *core.Target) *core.Spell {
+				return makeAbilitySpell(target, abilityConfig)
+			},
+		})
+	}
+	return abilities
+}
+
+func makeAbilitySpell(target *core.Target, config *proto.TargetAbility) *core.Spell {
+	school := core.SpellSchoolFromProto(config.School)
+	procMask := core.ProcMaskSpellDamage
+	if school == core.SpellSchoolPhysical {
+		procMask = core.ProcMaskMeleeMHSpecial
+	}
+
+	var raidMembers []*core.Unit
+	for _, unit := range target.Env.Raid.AllUnits {
+		if unit.Type == core.PlayerUnit {
+			raidMembers = append(raidMembers, unit)
+		}
+	}
+
+	spellConfig := core.SpellConfig{
+		ActionID:    core.ActionID{SpellID: config.SpellId},
+		SpellSchool: school,
+		ProcMask:    procMask,
+
+		DamageMultiplier: 1,
+		CritMultiplier:   1,
+	}
+	if config.CastTime > 0 {
+		numHit := 1
+		if config.Targeting == proto.TargetAbilityTargeting_TargetAbilityTargetingRaid {
+			numHit = len(raidMembers)
+		}
+		spellConfig.Flags |= core.SpellFlagInterruptible
+		spellConfig.Cast.DefaultCast.CastTime = core.DurationFromSeconds(config.CastTime)
+		spellConfig.ExpectedDamage = (config.MinDamage + config.MaxDamage) / 2 * float64(numHit)
+	}
+	if config.Cooldown > 0 {
+		spellConfig.Cast.CD = core.Cooldown{
+			Timer:    target.NewTimer(),
+			Duration: core.DurationFromSeconds(config.Cooldown),
+		}
+	}
+
+	dealDamage := func(sim *core.Simulation, unit *core.Unit, spell *core.Spell) {
+		baseDamage := sim.Roll(config.MinDamage, config.MaxDamage)
+		if school == core.SpellSchoolPhysical {
+			spell.CalcAndDealDamageEnemyMeleeWhite(sim, unit, baseDamage)
+		} else {
+			spell.CalcAndDealDamageMagicHit(sim, unit, baseDamage)
+		}
+	}
+
+	switch config.Targeting {
+	case proto.TargetAbilityTargeting_TargetAbilityTargetingRandom:
+		spellConfig.ApplyEffects = func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			if len(raidMembers) == 0 {
+				return
+			}
+			unit := raidMembers[int(sim.RandomFloat("Target Ability Target")*float64(len(raidMembers)))]
+			dealDamage(sim, unit, spell)
+		}
+	case proto.TargetAbilityTargeting_TargetAbilityTargetingRaid:
+		spellConfig.ApplyEffects = func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			for _, unit := range raidMembers {
+				dealDamage(sim, unit, spell)
+			}
+		}
+	default:
+		spellConfig.ApplyEffects = func(sim *core.Simulation, unit *core.Unit, spell *core.Spell) {
+			dealDamage(sim, unit, spell)
+		}
+	}
+
+	return target.RegisterSpell(spellConfig)
+}
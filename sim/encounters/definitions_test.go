@@ -0,0 +1,68 @@
+package encounters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func TestLoadDefinitionsNamedStats(t *testing.T) {
+	err := LoadDefinitions([]byte(`{"encounters": [{
+		"pathPrefix": "Test Named Stats",
+		"name": "Boss",
+		"targets": [{"id": 1, "name": "Boss", "stats": {"Armor": 10643, "Health": 1000000}}]
+	}]}`))
+	if err != nil {
+		t.Fatalf("Failed to load definitions: %s", err)
+	}
+
+	preset := core.GetPresetTargetWithPath("Test Named Stats/Boss")
+	if preset == nil {
+		t.Fatalf("Expected the target to be registered")
+	}
+	if len(preset.Config.Stats) != int(stats.Len) || preset.Config.Stats[stats.Armor] != 10643 || preset.Config.Stats[stats.Health] != 1000000 {
+		t.Fatalf("Unexpected target stats: %v", preset.Config.Stats)
+	}
+}
+
+func TestLoadDefinitionsErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		definitions string
+		err         string
+	}{
+		{
+			name: "UnknownStat",
+			definitions: `{"encounters": [{"pathPrefix": "Test Errors", "name": "Boss",
+				"targets": [{"id": 1, "name": "Boss", "stats": {"Armour": 10643}}]}]}`,
+			err: "unknown stat Armour",
+		},
+		{
+			name: "DuplicateTargetInEncounter",
+			definitions: `{"encounters": [{"pathPrefix": "Test Errors", "name": "Boss",
+				"targets": [{"id": 1, "name": "Boss"}, {"id": 2, "name": "Boss"}]}]}`,
+			err: "target Test Errors/Boss already exists",
+		},
+		{
+			name: "DuplicateTargetInBatch",
+			definitions: `{"encounters": [
+				{"pathPrefix": "Test Errors", "name": "Boss", "targets": [{"id": 1, "name": "Boss"}]},
+				{"pathPrefix": "Test Errors", "name": "Boss Again", "targets": [{"id": 2, "name": "Boss"}]}]}`,
+			err: "target Test Errors/Boss already exists",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := LoadDefinitions([]byte(tc.definitions))
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("Expected error %q, got %v", tc.err, err)
+			}
+			if core.GetPresetTargetWithPath("Test Errors/Boss") != nil {
+				t.Fatalf("Expected no targets to be registered from invalid definitions")
+			}
+		})
+	}
+}
@@ -1,11 +1,29 @@
 package naxxrammas
 
 import (
+	"time"
+
 	"github.com/wowsims/wotlk/sim/core"
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
+	"github.com/wowsims/wotlk/sim/encounters"
 )
 
+// Adds which attack the raid in waves during phase 1.
+var kelThuzad25Adds = []struct {
+	id     int32
+	name   string
+	level  int32
+	health float64
+}{
+	{id: 16427, name: "Soldier of the Frozen Wastes 25", level: 80, health: 17_010},
+	{id: 16428, name: "Unstoppable Abomination 25", level: 81, health: 252_000},
+	{id: 16429, name: "Soul Weaver 25", level: 81, health: 252_000},
+}
+
+// Kel'Thuzad can't be attacked until phase 2 begins.
+const kelThuzad25Phase2Start = time.Minute*3 + time.Second*48
+
 func addKelThuzad25(bossPrefix string) {
 	core.AddPresetTarget(core.PresetTarget{
 		PathPrefix: bossPrefix,
@@ -32,25 +50,118 @@ func addKelThuzad25(bossPrefix string) {
 		},
 		AI: NewKelThuzad25AI(),
 	})
-	core.AddPresetEncounter("Kel'Thuzad 25", []string{
-		bossPrefix + "/Kel'Thuzad 25",
-	})
+
+	targetPaths := []string{bossPrefix + "/Kel'Thuzad 25"}
+	for _, add := range kelThuzad25Adds {
+		core.AddPresetTarget(core.PresetTarget{
+			PathPrefix: bossPrefix,
+			Config: proto.Target{
+				Id:      add.id,
+				Name:    add.name,
+				Level:   add.level,
+				MobType: proto.MobType_MobTypeUndead,
+				Stats: stats.Stats{
+					stats.Health: add.health,
+					stats.Armor:  9729,
+				}.ToFloatArray(),
+			},
+		})
+		targetPaths = append(targetPaths, bossPrefix+"/"+add.name)
+	}
+	core.AddPresetEncounter("Kel'Thuzad 25", targetPaths)
 }
 
 type KelThuzad25AI struct {
-	Target *core.Target
+	encounters.DefaultAI
 }
 
 func NewKelThuzad25AI() core.AIFactory {
 	return func() core.TargetAI {
-		return &KelThuzad25AI{}
+		return &KelThuzad25AI{
+			DefaultAI: encounters.DefaultAI{
+				Abilities: []encounters.TargetAbility{
+					{
+						ChanceToUse: 1,
+						Timed:       true,
+						MakeSpell:   makeFrostboltVolleySpell,
+					},
+				},
+			},
+		}
 	}
 }
 
 func (ai *KelThuzad25AI) Initialize(target *core.Target) {
-	ai.Target = target
+	ai.DefaultAI.Initialize(target)
+	ai.setupAddWaves(target)
+}
+
+// When the encounter includes the phase 1 adds, Kel'Thuzad stays inactive while
+// the raid fights them, then the adds despawn once he joins the fight.
+func (ai *KelThuzad25AI) setupAddWaves(target *core.Target) {
+	var addIndices []int32
+	for _, encounterTarget := range target.Env.Encounter.Targets {
+		if encounterTarget == target {
+			continue
+		}
+		if !isKelThuzad25Add(encounterTarget) {
+			// Unknown targets, leave the encounter alone.
+			return
+		}
+		addIndices = append(addIndices, encounterTarget.Index)
+	}
+	if len(addIndices) == 0 {
+		return
+	}
+
+	target.Env.Encounter.SetDefaultPhases([]*proto.EncounterPhase{
+		{
+			Name:                "Phase 1",
+			DeactivateTargets:   []int32{target.Index},
+			ChangePrimaryTarget: true,
+			PrimaryTargetIndex:  addIndices[0],
+		},
+		{
+			Name:                "Phase 2",
+			StartTime:           kelThuzad25Phase2Start.Seconds(),
+			ActivateTargets:     []int32{target.Index},
+			DeactivateTargets:   addIndices,
+			ChangePrimaryTarget: true,
+			PrimaryTargetIndex:  target.Index,
+		},
+	})
+}
+
+func isKelThuzad25Add(target *core.Target) bool {
+	for _, add := range kelThuzad25Adds {
+		if add.id == target.ID {
+			return true
+		}
+	}
+	return false
 }
 
-func (ai *KelThuzad25AI) DoAction(sim *core.Simulation) {
-	ai.Target.DoNothing()
+// Frost damage to the whole raid.
+func makeFrostboltVolleySpell(target *core.Target) *core.Spell {
+	return target.RegisterSpell(core.SpellConfig{
+		ActionID:    core.ActionID{SpellID: 55807},
+		SpellSchool: core.SpellSchoolFrost,
+		ProcMask:    core.ProcMaskSpellDamage,
+
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    target.NewTimer(),
+				Duration: time.Second * 15,
+			},
+		},
+
+		DamageMultiplier: 1,
+		CritMultiplier:   1,
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			for _, unit := range sim.Raid.AllUnits {
+				spell.CalcAndDealDamageMagicHit(sim, unit, sim.Roll(4500, 5500))
+			}
+		},
+	})
 }
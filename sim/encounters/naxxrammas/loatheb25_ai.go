@@ -1,9 +1,12 @@
 package naxxrammas
 
 import (
+	"time"
+
 	"github.com/wowsims/wotlk/sim/core"
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
+	"github.com/wowsims/wotlk/sim/encounters"
 )
 
 func addLoatheb25(bossPrefix string) {
@@ -37,20 +40,138 @@ func addLoatheb25(bossPrefix string) {
 	})
 }
 
+// Spores are killed by one raid group at a time, in rotation.
+const loathebSporeGroups = 5
+
 type Loatheb25AI struct {
-	Target *core.Target
+	encounters.DefaultAI
+
+	numSpores int
 }
 
 func NewLoatheb25AI() core.AIFactory {
 	return func() core.TargetAI {
-		return &Loatheb25AI{}
+		ai := &Loatheb25AI{}
+		ai.Abilities = []encounters.TargetAbility{
+			{
+				ChanceToUse: 1,
+				Timed:       true,
+				MakeSpell:   makeNecroticAuraSpell,
+			},
+			{
+				InitialCD:   time.Second * 18,
+				ChanceToUse: 1,
+				Timed:       true,
+				MakeSpell:   ai.makeSummonSporeSpell,
+			},
+		}
+		return ai
 	}
 }
 
-func (ai *Loatheb25AI) Initialize(target *core.Target) {
-	ai.Target = target
+func (ai *Loatheb25AI) Reset(sim *core.Simulation) {
+	ai.DefaultAI.Reset(sim)
+	ai.numSpores = 0
+}
+
+// Prevents all healing on the raid for 17s out of every 20s. This also applies
+// to the healing model.
+func makeNecroticAuraSpell(target *core.Target) *core.Spell {
+	var auras []*core.Aura
+	for _, unit := range target.Env.Raid.AllUnits {
+		// Saved when the aura is gained, since the multiplier can't be divided back out of 0.
+		var healingTakenMultiplier float64
+		auras = append(auras, unit.RegisterAura(core.Aura{
+			Label:    "Necrotic Aura",
+			ActionID: core.ActionID{SpellID: 55593},
+			Duration: time.Second * 17,
+			OnGain: func(aura *core.Aura, sim *core.Simulation) {
+				healingTakenMultiplier = aura.Unit.PseudoStats.HealingTakenMultiplier
+				aura.Unit.PseudoStats.HealingTakenMultiplier = 0
+			},
+			OnExpire: func(aura *core.Aura, sim *core.Simulation) {
+				aura.Unit.PseudoStats.HealingTakenMultiplier = healingTakenMultiplier
+			},
+		}))
+	}
+
+	return target.RegisterSpell(core.SpellConfig{
+		ActionID: core.ActionID{SpellID: 55593},
+		Flags:    core.SpellFlagNoOnCastComplete,
+
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    target.NewTimer(),
+				Duration: time.Second * 20,
+			},
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			for _, aura := range auras {
+				aura.Activate(sim)
+			}
+		},
+	})
 }
 
-func (ai *Loatheb25AI) DoAction(sim *core.Simulation) {
-	ai.Target.DoNothing()
+// Fungal Creep from each spore a player's group kills stacks until it wears off.
+func fungalCreepAura(character *core.Character) *core.Aura {
+	critPerStack := 50 * core.CritRatingPerCritChance
+	return character.RegisterAura(core.Aura{
+		Label:     "Fungal Creep",
+		ActionID:  core.ActionID{SpellID: 29232},
+		Duration:  time.Second * 90,
+		MaxStacks: loathebSporeGroups,
+		OnStacksChange: func(aura *core.Aura, sim *core.Simulation, oldStacks int32, newStacks int32) {
+			critBonus := float64(newStacks-oldStacks) * critPerStack
+			aura.Unit.AddStatsDynamic(sim, stats.Stats{
+				stats.MeleeCrit: critBonus,
+				stats.SpellCrit: critBonus,
+			})
+		},
+	})
+}
+
+// Each spore bursts into a Spore Cloud on Loatheb when it dies, and grants
+// Fungal Creep to the raid group which killed it.
+func (ai *Loatheb25AI) makeSummonSporeSpell(target *core.Target) *core.Spell {
+	var groupAuras [][]*core.Aura
+	for _, party := range target.Env.Raid.Parties {
+		if len(groupAuras) == loathebSporeGroups {
+			break
+		}
+		if len(party.Players) == 0 {
+			continue
+		}
+		var auras []*core.Aura
+		for _, player := range party.Players {
+			auras = append(auras, fungalCreepAura(player.GetCharacter()))
+		}
+		groupAuras = append(groupAuras, auras)
+	}
+	sporeCloudAura := core.SporeCloudAura(&target.Unit)
+
+	return target.RegisterSpell(core.SpellConfig{
+		ActionID: core.ActionID{SpellID: 29234},
+		Flags:    core.SpellFlagNoOnCastComplete,
+
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    target.NewTimer(),
+				Duration: time.Second * 18,
+			},
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			sporeCloudAura.Activate(sim)
+			if len(groupAuras) == 0 {
+				return
+			}
+			for _, aura := range groupAuras[ai.numSpores%len(groupAuras)] {
+				aura.Activate(sim)
+				aura.AddStack(sim)
+			}
+			ai.numSpores++
+		},
+	})
 }
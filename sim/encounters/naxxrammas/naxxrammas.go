@@ -2,10 +2,8 @@ package naxxrammas
 
 func Register() {
 	addPatchwerk25("Naxxrammas")
+	addPatchwerk10("Naxxrammas")
 	addKelThuzad25("Naxxrammas")
 	addThaddius25("Naxxrammas")
 	addLoatheb25("Naxxrammas")
-
-	// TODO: Figure out why this isn't pickable
-	//addPatchwerk10("Naxxrammas")
 }
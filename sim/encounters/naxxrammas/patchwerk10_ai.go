@@ -119,6 +119,9 @@ func (ai *Patchwerk10AI) registerFrenzySpell(target *core.Target) {
 	})
 }
 
+func (ai *Patchwerk10AI) Reset(sim *core.Simulation) {
+}
+
 func (ai *Patchwerk10AI) DoAction(sim *core.Simulation) {
 	if ai.Frenzy.IsReady(sim) && sim.GetRemainingDurationPercent() < 0.05 {
 		ai.Frenzy.Cast(sim, ai.Target.CurrentTarget)
@@ -119,6 +119,9 @@ func (ai *Patchwerk25AI) registerFrenzySpell(target *core.Target) {
 	})
 }
 
+func (ai *Patchwerk25AI) Reset(sim *core.Simulation) {
+}
+
 func (ai *Patchwerk25AI) DoAction(sim *core.Simulation) {
 	if ai.Frenzy.IsReady(sim) && sim.GetRemainingDurationPercent() < 0.05 {
 		ai.Frenzy.Cast(sim, ai.Target.CurrentTarget)
@@ -1,16 +1,19 @@
 package naxxrammas
 
 import (
+	"time"
+
 	"github.com/wowsims/wotlk/sim/core"
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
+	"github.com/wowsims/wotlk/sim/encounters"
 )
 
 func addThaddius25(bossPrefix string) {
 	core.AddPresetTarget(core.PresetTarget{
 		PathPrefix: bossPrefix,
 		Config: proto.Target{
-			Id:        15990,
+			Id:        15928,
 			Name:      "Thaddius 25",
 			Level:     83,
 			MobType:   proto.MobType_MobTypeUndead,
@@ -37,20 +40,123 @@ func addThaddius25(bossPrefix string) {
 	})
 }
 
+// Each player gets a stack of their charge for every ally with the same charge
+// within 13 yards. Assume a typical spread-out raid gives about 5.
+const thaddiusChargeStacks = 5
+const thaddiusChargeDamageBonusPerStack = 0.1
+
+// Time taken to run to the other side after a player's charge flips.
+const thaddiusRepositionTime = time.Second * 3
+
 type Thaddius25AI struct {
-	Target *core.Target
+	encounters.DefaultAI
+
+	charges []*thaddiusCharge
+}
+
+// Polarity state for a single player.
+type thaddiusCharge struct {
+	positiveAura *core.Aura
+	negativeAura *core.Aura
+
+	// The aura for the player's current charge, which may not be active yet if
+	// they're still running to their side. Nil before the first Polarity Shift.
+	current *core.Aura
+	arrival *core.PendingAction
 }
 
 func NewThaddius25AI() core.AIFactory {
 	return func() core.TargetAI {
-		return &Thaddius25AI{}
+		ai := &Thaddius25AI{}
+		ai.Abilities = []encounters.TargetAbility{
+			{
+				InitialCD:   time.Second * 15,
+				ChanceToUse: 1,
+				Timed:       true,
+				MakeSpell:   ai.makePolarityShiftSpell,
+			},
+		}
+		return ai
 	}
 }
 
-func (ai *Thaddius25AI) Initialize(target *core.Target) {
-	ai.Target = target
+func (ai *Thaddius25AI) Reset(sim *core.Simulation) {
+	ai.DefaultAI.Reset(sim)
+	for _, charge := range ai.charges {
+		charge.current = nil
+		charge.arrival = nil
+	}
 }
 
-func (ai *Thaddius25AI) DoAction(sim *core.Simulation) {
-	ai.Target.DoNothing()
+func (ai *Thaddius25AI) makePolarityShiftSpell(target *core.Target) *core.Spell {
+	ai.charges = ai.charges[:0]
+	for _, unit := range target.Env.Raid.AllUnits {
+		if unit.Type != core.PlayerUnit {
+			continue
+		}
+		ai.charges = append(ai.charges, &thaddiusCharge{
+			positiveAura: makeThaddiusChargeAura(unit, "Positive Charge", 29659),
+			negativeAura: makeThaddiusChargeAura(unit, "Negative Charge", 29660),
+		})
+	}
+
+	return target.RegisterSpell(core.SpellConfig{
+		ActionID: core.ActionID{SpellID: 28089},
+		Flags:    core.SpellFlagNoOnCastComplete,
+
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    target.NewTimer(),
+				Duration: time.Second * 30,
+			},
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
+			for _, charge := range ai.charges {
+				if sim.RandomFloat("Polarity Shift") < 0.5 {
+					charge.set(sim, charge.positiveAura)
+				} else {
+					charge.set(sim, charge.negativeAura)
+				}
+			}
+		},
+	})
+}
+
+func makeThaddiusChargeAura(unit *core.Unit, label string, spellID int32) *core.Aura {
+	return unit.RegisterAura(core.Aura{
+		Label:     label,
+		ActionID:  core.ActionID{SpellID: spellID},
+		Duration:  core.NeverExpires,
+		MaxStacks: thaddiusChargeStacks,
+		OnStacksChange: func(aura *core.Aura, sim *core.Simulation, oldStacks int32, newStacks int32) {
+			oldMultiplier := 1 + float64(oldStacks)*thaddiusChargeDamageBonusPerStack
+			newMultiplier := 1 + float64(newStacks)*thaddiusChargeDamageBonusPerStack
+			aura.Unit.PseudoStats.DamageDealtMultiplier *= newMultiplier / oldMultiplier
+		},
+	})
+}
+
+// Gives the player a new charge. If it differs from their old one, they lose
+// their damage bonus while running over to the other side.
+func (charge *thaddiusCharge) set(sim *core.Simulation, newAura *core.Aura) {
+	if charge.current == newAura {
+		return
+	}
+	if charge.current != nil {
+		charge.current.Deactivate(sim)
+	}
+	if charge.arrival != nil {
+		charge.arrival.Cancel(sim)
+	}
+
+	charge.current = newAura
+	charge.arrival = core.StartDelayedAction(sim, core.DelayedActionOptions{
+		DoAt: sim.CurrentTime + thaddiusRepositionTime,
+		OnAction: func(sim *core.Simulation) {
+			charge.arrival = nil
+			newAura.Activate(sim)
+			newAura.SetStacks(sim, thaddiusChargeStacks)
+		},
+	})
 }
@@ -0,0 +1,104 @@
+package encounters
+
+import (
+	"fmt"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+// A raid instance whose encounters are registered together. Each encounter is
+// registered in a 10 and a 25 man version, e.g. "Hodir 10" and "Hodir 25".
+type RaidTier struct {
+	// Used as the path prefix of all targets and encounters, e.g. "Ulduar".
+	Name string
+
+	Encounters []RaidEncounter
+}
+
+type RaidEncounter struct {
+	Name    string
+	Targets []RaidTarget
+
+	// Phases which are set up when simming the whole encounter. Target indices
+	// refer to Targets.
+	Phases []*proto.EncounterPhase
+}
+
+type RaidTarget struct {
+	Name    string
+	Id      int32
+	Level   int32 // Defaults to a raid boss.
+	MobType proto.MobType
+
+	// Health in each raid size.
+	Health10 float64
+	Health25 float64
+	Armor    float64
+
+	TankIndex       int32
+	SwingSpeed      float64
+	MinBaseDamage10 float64
+	MinBaseDamage25 float64
+	DualWield       bool
+	ParryHaste      bool
+	SuppressDodge   bool
+}
+
+// Registers the 10 and 25 man versions of all encounters in a raid tier as
+// presets.
+func RegisterRaidTier(tier RaidTier) {
+	for _, encounter := range tier.Encounters {
+		for _, raidSize := range []int{10, 25} {
+			definition := encounter.definition(tier.Name, raidSize)
+			if err := validateDefinition(definition, make(map[string]bool)); err != nil {
+				panic(fmt.Sprintf("Invalid encounter %s/%s: %s", tier.Name, definition.Name, err))
+			}
+			registerDefinition(definition)
+		}
+	}
+}
+
+func (encounter RaidEncounter) definition(pathPrefix string, raidSize int) *proto.EncounterDefinition {
+	definition := &proto.EncounterDefinition{
+		PathPrefix: pathPrefix,
+		Name:       fmt.Sprintf("%s %d", encounter.Name, raidSize),
+		Phases:     encounter.Phases,
+	}
+	for _, target := range encounter.Targets {
+		definition.Targets = append(definition.Targets, target.config(raidSize))
+	}
+	return definition
+}
+
+func (target RaidTarget) config(raidSize int) *proto.Target {
+	health, minBaseDamage := target.Health25, target.MinBaseDamage25
+	if raidSize == 10 {
+		health, minBaseDamage = target.Health10, target.MinBaseDamage10
+	}
+	level := target.Level
+	if level == 0 {
+		level = 83
+	}
+
+	return &proto.Target{
+		Id:        target.Id,
+		Name:      fmt.Sprintf("%s %d", target.Name, raidSize),
+		Level:     level,
+		MobType:   target.MobType,
+		TankIndex: target.TankIndex,
+
+		Stats: stats.Stats{
+			stats.Health:      health,
+			stats.Armor:       target.Armor,
+			stats.AttackPower: 640,
+		}.ToFloatArray(),
+
+		SpellSchool:   proto.SpellSchool_SpellSchoolPhysical,
+		SwingSpeed:    target.SwingSpeed,
+		MinBaseDamage: minBaseDamage,
+		DualWield:     target.DualWield,
+		ParryHaste:    target.ParryHaste,
+		SuppressDodge: target.SuppressDodge,
+	}
+}
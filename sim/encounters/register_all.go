@@ -2,13 +2,8 @@ package encounters
 
 import (
 	"github.com/wowsims/wotlk/sim/core"
-	"github.com/wowsims/wotlk/sim/encounters/naxxrammas"
 )
 
-func init() {
-	naxxrammas.Register()
-}
-
 func AddSingleTargetBossEncounter(presetTarget core.PresetTarget) {
 	core.AddPresetTarget(presetTarget)
 	core.AddPresetEncounter(presetTarget.Config.Name, []string{
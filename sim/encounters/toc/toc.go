@@ -0,0 +1,154 @@
+package toc
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/encounters"
+)
+
+const bossArmor = 10643
+
+func Register() {
+	encounters.RegisterRaidTier(encounters.RaidTier{
+		Name: "Trial of the Crusader",
+		Encounters: []encounters.RaidEncounter{
+			{
+				// The beasts arrive one after another: Gormok, then both
+				// Jormungars together, then Icehowl.
+				Name: "Northrend Beasts",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Gormok the Impaler",
+						Id:              34796,
+						MobType:         proto.MobType_MobTypeGiant,
+						Health10:        4_182_375,
+						Health25:        13_944_000,
+						Armor:           bossArmor,
+						SwingSpeed:      1.5,
+						MinBaseDamage10: 12_000,
+						MinBaseDamage25: 20_000,
+					},
+					{
+						Name:            "Acidmaw",
+						Id:              35144,
+						MobType:         proto.MobType_MobTypeBeast,
+						Health10:        2_789_000,
+						Health25:        9_761_500,
+						Armor:           bossArmor,
+						SwingSpeed:      1.5,
+						MinBaseDamage10: 8_000,
+						MinBaseDamage25: 14_000,
+					},
+					{
+						Name:            "Dreadscale",
+						Id:              34799,
+						MobType:         proto.MobType_MobTypeBeast,
+						Health10:        2_789_000,
+						Health25:        9_761_500,
+						Armor:           bossArmor,
+						TankIndex:       1,
+						SwingSpeed:      1.5,
+						MinBaseDamage10: 8_000,
+						MinBaseDamage25: 14_000,
+					},
+					{
+						Name:            "Icehowl",
+						Id:              34797,
+						MobType:         proto.MobType_MobTypeBeast,
+						Health10:        4_183_500,
+						Health25:        19_523_000,
+						Armor:           bossArmor,
+						SwingSpeed:      2,
+						MinBaseDamage10: 15_000,
+						MinBaseDamage25: 25_000,
+					},
+				},
+				Phases: []*proto.EncounterPhase{
+					{
+						Name:              "Gormok",
+						DeactivateTargets: []int32{1, 2, 3},
+					},
+					{
+						Name:                    "Jormungars",
+						TriggerTargetIndex:      0,
+						TriggerHealthProportion: 0.001,
+						ActivateTargets:         []int32{1, 2},
+						DeactivateTargets:       []int32{0},
+						ChangePrimaryTarget:     true,
+						PrimaryTargetIndex:      1,
+					},
+					{
+						Name:                    "Icehowl",
+						TriggerTargetIndex:      1,
+						TriggerHealthProportion: 0.001,
+						ActivateTargets:         []int32{3},
+						DeactivateTargets:       []int32{1, 2},
+						ChangePrimaryTarget:     true,
+						PrimaryTargetIndex:      3,
+					},
+				},
+			},
+			{
+				Name: "Lord Jaraxxus",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Lord Jaraxxus",
+						Id:              34780,
+						MobType:         proto.MobType_MobTypeDemon,
+						Health10:        4_183_500,
+						Health25:        19_523_000,
+						Armor:           bossArmor,
+						SwingSpeed:      2,
+						MinBaseDamage10: 12_000,
+						MinBaseDamage25: 20_000,
+					},
+				},
+			},
+			{
+				// The twins share a health pool, which is split evenly between
+				// them here.
+				Name: "Twin Val'kyr",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Fjola Lightbane",
+						Id:              34497,
+						MobType:         proto.MobType_MobTypeUndead,
+						Health10:        6_972_500 / 2,
+						Health25:        27_890_000 / 2,
+						Armor:           bossArmor,
+						SwingSpeed:      1,
+						MinBaseDamage10: 6_000,
+						MinBaseDamage25: 10_000,
+					},
+					{
+						Name:            "Eydis Darkbane",
+						Id:              34496,
+						MobType:         proto.MobType_MobTypeUndead,
+						Health10:        6_972_500 / 2,
+						Health25:        27_890_000 / 2,
+						Armor:           bossArmor,
+						TankIndex:       1,
+						SwingSpeed:      1,
+						MinBaseDamage10: 6_000,
+						MinBaseDamage25: 10_000,
+					},
+				},
+			},
+			{
+				Name: "Anub'arak",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Anub'arak",
+						Id:              34564,
+						MobType:         proto.MobType_MobTypeUndead,
+						Health10:        4_183_500,
+						Health25:        20_916_000,
+						Armor:           bossArmor,
+						SwingSpeed:      2,
+						MinBaseDamage10: 15_000,
+						MinBaseDamage25: 25_000,
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,166 @@
+package ulduar
+
+import (
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/encounters"
+)
+
+const bossArmor = 10643
+
+func Register() {
+	encounters.RegisterRaidTier(encounters.RaidTier{
+		Name: "Ulduar",
+		Encounters: []encounters.RaidEncounter{
+			{
+				Name: "Ignis the Furnace Master",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Ignis the Furnace Master",
+						Id:              33118,
+						Health10:        5_578_000,
+						Health25:        20_919_000,
+						Armor:           bossArmor,
+						SwingSpeed:      2,
+						MinBaseDamage10: 15_000,
+						MinBaseDamage25: 25_000,
+					},
+				},
+			},
+			{
+				Name: "XT-002 Deconstructor",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "XT-002 Deconstructor",
+						Id:              33293,
+						MobType:         proto.MobType_MobTypeMechanical,
+						Health10:        5_000_000,
+						Health25:        22_500_000,
+						Armor:           bossArmor,
+						SwingSpeed:      2,
+						MinBaseDamage10: 12_000,
+						MinBaseDamage25: 20_000,
+					},
+				},
+			},
+			{
+				// All three members are fought at once, each on their own tank.
+				Name: "Assembly of Iron",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Steelbreaker",
+						Id:              32867,
+						Health10:        2_998_175,
+						Health25:        9_761_500,
+						Armor:           bossArmor,
+						TankIndex:       0,
+						SwingSpeed:      1.5,
+						MinBaseDamage10: 10_000,
+						MinBaseDamage25: 17_000,
+					},
+					{
+						Name:            "Runemaster Molgeim",
+						Id:              32927,
+						Health10:        2_998_175,
+						Health25:        9_761_500,
+						Armor:           bossArmor,
+						TankIndex:       1,
+						SwingSpeed:      2,
+						MinBaseDamage10: 8_000,
+						MinBaseDamage25: 14_000,
+					},
+					{
+						Name:            "Stormcaller Brundir",
+						Id:              32857,
+						Health10:        2_998_175,
+						Health25:        9_761_500,
+						Armor:           bossArmor,
+						TankIndex:       2,
+						SwingSpeed:      2,
+						MinBaseDamage10: 8_000,
+						MinBaseDamage25: 14_000,
+					},
+				},
+			},
+			{
+				// The arms are optional targets, which respawn after dying.
+				Name: "Kologarn",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Kologarn",
+						Id:              32930,
+						MobType:         proto.MobType_MobTypeGiant,
+						Health10:        8_785_000,
+						Health25:        24_446_200,
+						Armor:           bossArmor,
+						SwingSpeed:      2,
+						MinBaseDamage10: 15_000,
+						MinBaseDamage25: 25_000,
+					},
+					{
+						Name:     "Left Arm",
+						Id:       32933,
+						MobType:  proto.MobType_MobTypeGiant,
+						Health10: 2_789_000,
+						Health25: 8_366_000,
+						Armor:    bossArmor,
+					},
+					{
+						Name:     "Right Arm",
+						Id:       32934,
+						MobType:  proto.MobType_MobTypeGiant,
+						Health10: 2_789_000,
+						Health25: 8_366_000,
+						Armor:    bossArmor,
+					},
+				},
+			},
+			{
+				Name: "Auriaya",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Auriaya",
+						Id:              33515,
+						MobType:         proto.MobType_MobTypeBeast,
+						Health10:        3_137_625,
+						Health25:        16_734_000,
+						Armor:           bossArmor,
+						SwingSpeed:      1.5,
+						MinBaseDamage10: 10_000,
+						MinBaseDamage25: 18_000,
+					},
+				},
+			},
+			{
+				Name: "Hodir",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "Hodir",
+						Id:              32845,
+						MobType:         proto.MobType_MobTypeGiant,
+						Health10:        8_115_990,
+						Health25:        32_477_904,
+						Armor:           bossArmor,
+						SwingSpeed:      2,
+						MinBaseDamage10: 12_000,
+						MinBaseDamage25: 20_000,
+					},
+				},
+			},
+			{
+				Name: "General Vezax",
+				Targets: []encounters.RaidTarget{
+					{
+						Name:            "General Vezax",
+						Id:              33271,
+						Health10:        8_366_000,
+						Health25:        27_890_000,
+						Armor:           bossArmor,
+						SwingSpeed:      2,
+						MinBaseDamage10: 15_000,
+						MinBaseDamage25: 25_000,
+					},
+				},
+			},
+		},
+	})
+}
@@ -54,6 +54,7 @@ func (hunter *Hunter) registerMultiShotSpell(timer *core.Timer) {
 				408
 
 			curTarget := target
+			numHits := core.MinInt32(numHits, sim.GetNumActiveTargets())
 			for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 				baseDamage := sharedDmg + 0.2*spell.RangedAttackPower(curTarget)
 				spell.CalcAndDealDamageRangedHitAndCrit(sim, curTarget, baseDamage)
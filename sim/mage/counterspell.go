@@ -0,0 +1,28 @@
+package mage
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func (mage *Mage) registerCounterspellSpell() {
+	baseCost := 0.09 * mage.BaseMana
+
+	mage.Counterspell = mage.RegisterInterruptSpell(core.SpellConfig{
+		ActionID:     core.ActionID{SpellID: 2139},
+		ResourceType: stats.Mana,
+		BaseCost:     baseCost,
+
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				Cost: baseCost,
+			},
+			CD: core.Cooldown{
+				Timer:    mage.NewTimer(),
+				Duration: time.Second * 24,
+			},
+		},
+	}, nil)
+}
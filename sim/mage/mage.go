@@ -76,6 +76,7 @@ type Mage struct {
 	Ignite          *core.Spell
 	LivingBomb      *core.Spell
 	Fireball        *core.Spell
+	Counterspell    *core.Spell
 	FireBlast       *core.Spell
 	Flamestrike     *core.Spell
 	Frostbolt       *core.Spell
@@ -163,6 +164,7 @@ func (mage *Mage) Initialize() {
 	mage.registerBlizzardSpell()
 	mage.registerDeepFreezeSpell()
 	mage.registerFireballSpell()
+	mage.registerCounterspellSpell()
 	mage.registerFireBlastSpell()
 	mage.registerFlamestrikeSpell()
 	mage.registerFrostboltSpell()
@@ -27,11 +27,37 @@ func (mage *Mage) tryUseGCD(sim *core.Simulation) {
 		spell = mage.doAoeRotation(sim)
 	}
 
+	if !spell.FinishesBeforeMovement(sim) {
+		if instant := mage.instantWhileMoving(sim); instant != nil {
+			spell = instant
+		} else if mage.IsMoving(sim) {
+			mage.WaitUntil(sim, core.MinDuration(mage.MovementEndsAt(sim), mage.FireBlast.ReadyAt()))
+			return
+		}
+	}
+
 	if success := spell.Cast(sim, mage.CurrentTarget); !success {
 		mage.WaitForMana(sim, spell.CurCast.Cost)
 	}
 }
 
+// Returns an instant to use instead of a hard cast which would be interrupted by
+// movement, or nil if none are ready.
+func (mage *Mage) instantWhileMoving(sim *core.Simulation) *core.Spell {
+	if mage.HotStreakAura.IsActive() {
+		return mage.Pyroblast
+	} else if mage.BrainFreezeAura.IsActive() {
+		return mage.FrostfireBolt
+	} else if mage.FingersOfFrostAura.IsActive() && mage.DeepFreeze != nil && mage.DeepFreeze.IsReady(sim) {
+		return mage.DeepFreeze
+	} else if mage.Talents.LivingBomb && !mage.LivingBombNotActive.Empty() {
+		return mage.LivingBomb
+	} else if mage.FireBlast.IsReady(sim) {
+		return mage.FireBlast
+	}
+	return nil
+}
+
 // 4 ABs used < x always fish for AM
 // 4 ABs used > y always cast AM as soon as barrage procs
 func (mage *Mage) doArcaneRotation(sim *core.Simulation) *core.Spell {
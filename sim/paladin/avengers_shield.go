@@ -27,13 +27,7 @@ func (paladin *Paladin) registerAvengersShieldSpell() {
 	}
 
 	// Glyph to single target, OR apply to up to 3 targets
-	numHits := core.TernaryInt32(glyphedSingleTargetAS, 1, core.MinInt32(3, paladin.Env.GetNumTargets()))
-	effects := make([]core.SpellEffect, 0, numHits)
-	for i := int32(0); i < numHits; i++ {
-		mhEffect := baseEffectMH
-		mhEffect.Target = paladin.Env.GetTargetUnit(i)
-		effects = append(effects, mhEffect)
-	}
+	numHits := core.TernaryInt32(glyphedSingleTargetAS, 1, 3)
 
 	paladin.AvengersShield = paladin.RegisterSpell(core.SpellConfig{
 		ActionID:     core.ActionID{SpellID: 48827},
@@ -61,6 +55,6 @@ func (paladin *Paladin) registerAvengersShieldSpell() {
 		BonusCritRating:  1,
 		ThreatMultiplier: 1,
 
-		ApplyEffects: core.ApplyEffectFuncDamageMultiple(effects),
+		ApplyEffects: core.ApplyEffectFuncAOEDamageMaxHits(paladin.Env, baseEffectMH, numHits),
 	})
 }
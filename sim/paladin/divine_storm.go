@@ -23,14 +23,6 @@ func (paladin *Paladin) registerDivineStormSpell() {
 		OutcomeApplier: paladin.OutcomeFuncMeleeSpecialHitAndCrit(),
 	}
 
-	numHits := core.MinInt32(4, paladin.Env.GetNumTargets())
-	effects := make([]core.SpellEffect, 0, numHits)
-	for i := int32(0); i < numHits; i++ {
-		mhEffect := baseEffectMH
-		mhEffect.Target = paladin.Env.GetTargetUnit(i)
-		effects = append(effects, mhEffect)
-	}
-
 	paladin.DivineStorm = paladin.RegisterSpell(core.SpellConfig{
 		ActionID:     core.ActionID{SpellID: 53385},
 		SpellSchool:  core.SpellSchoolPhysical,
@@ -60,6 +52,6 @@ func (paladin *Paladin) registerDivineStormSpell() {
 		CritMultiplier:   paladin.MeleeCritMultiplier(),
 		ThreatMultiplier: 1,
 
-		ApplyEffects: core.ApplyEffectFuncDamageMultiple(effects),
+		ApplyEffects: core.ApplyEffectFuncAOEDamageMaxHits(paladin.Env, baseEffectMH, 4),
 	})
 }
@@ -22,13 +22,7 @@ func (paladin *Paladin) registerHammerOfTheRighteousSpell() {
 		OutcomeApplier: paladin.OutcomeFuncMeleeSpecialHitAndCrit(),
 	}
 
-	numHits := core.MinInt32(core.TernaryInt32(paladin.HasMajorGlyph(proto.PaladinMajorGlyph_GlyphOfHammerOfTheRighteous), 4, 3), paladin.Env.GetNumTargets())
-	effects := make([]core.SpellEffect, 0, numHits)
-	for i := int32(0); i < numHits; i++ {
-		mhEffect := baseEffectMH
-		mhEffect.Target = paladin.Env.GetTargetUnit(i)
-		effects = append(effects, mhEffect)
-	}
+	numHits := core.TernaryInt32(paladin.HasMajorGlyph(proto.PaladinMajorGlyph_GlyphOfHammerOfTheRighteous), 4, 3)
 
 	paladin.HammerOfTheRighteous = paladin.RegisterSpell(core.SpellConfig{
 		ActionID:     core.ActionID{SpellID: 53595},
@@ -54,6 +48,6 @@ func (paladin *Paladin) registerHammerOfTheRighteousSpell() {
 		CritMultiplier:           paladin.MeleeCritMultiplier(),
 		ThreatMultiplier:         1,
 
-		ApplyEffects: core.ApplyEffectFuncDamageMultiple(effects),
+		ApplyEffects: core.ApplyEffectFuncAOEDamageMaxHits(paladin.Env, baseEffectMH, numHits),
 	})
 }
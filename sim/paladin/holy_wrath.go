@@ -49,15 +49,6 @@ func (paladin *Paladin) registerHolyWrathSpell() {
 		},
 	}
 
-	numTargets := paladin.Env.GetNumTargets()
-	effects := make([]core.SpellEffect, 0, paladin.Env.GetNumTargets())
-
-	for i := int32(0); i < numTargets; i++ {
-		effect := baseEffect
-		effect.Target = paladin.Env.GetTargetUnit(i)
-		effects = append(effects, effect)
-	}
-
 	paladin.HolyWrath = paladin.RegisterSpell(core.SpellConfig{
 		ActionID:     core.ActionID{SpellID: 48817},
 		SpellSchool:  core.SpellSchoolHoly,
@@ -79,6 +70,6 @@ func (paladin *Paladin) registerHolyWrathSpell() {
 		DamageMultiplier: 1,
 		ThreatMultiplier: 1,
 
-		ApplyEffects: core.ApplyEffectFuncDamageMultiple(effects),
+		ApplyEffects: core.ApplyEffectFuncAOEDamage(paladin.Env, baseEffect),
 	})
 }
@@ -65,8 +65,6 @@ type Paladin struct {
 	SpiritualAttunementMetrics *core.ResourceMetrics
 
 	HasTuralyonsOrLiadrinsBattlegear2Pc bool
-
-	DemonAndUndeadTargetCount int32
 }
 
 // Implemented by each Paladin spec.
@@ -140,6 +138,7 @@ func (paladin *Paladin) Initialize() {
 	paladin.registerDivinePleaSpell()
 	paladin.registerRighteousVengeanceSpell()
 
+	// Dots are indexed by target, so register them for inactive targets too.
 	targets := paladin.Env.GetNumTargets()
 
 	if paladin.Talents.RighteousVengeance > 0 {
@@ -161,13 +160,17 @@ func (paladin *Paladin) Initialize() {
 	for i := int32(0); i < targets; i++ {
 		paladin.SealOfVengeanceDots = append(paladin.SealOfVengeanceDots, paladin.createSealOfVengeanceDot(paladin.Env.GetTargetUnit(i)))
 	}
+}
 
-	for i := int32(0); i < paladin.Env.GetNumTargets(); i++ {
-		unit := paladin.Env.GetTargetUnit(i)
-		if unit.MobType == proto.MobType_MobTypeDemon || unit.MobType == proto.MobType_MobTypeUndead {
-			paladin.DemonAndUndeadTargetCount += 1
+// Returns the number of active demon and undead targets, which Holy Wrath stuns.
+func (paladin *Paladin) NumActiveDemonAndUndeadTargets(sim *core.Simulation) int32 {
+	count := int32(0)
+	for _, target := range sim.Encounter.ActiveTargets {
+		if target.MobType == proto.MobType_MobTypeDemon || target.MobType == proto.MobType_MobTypeUndead {
+			count++
 		}
 	}
+	return count
 }
 
 func (paladin *Paladin) Reset(sim *core.Simulation) {
@@ -9,33 +9,35 @@ import (
 )
 
 func (ret *RetributionPaladin) OnAutoAttack(sim *core.Simulation, spell *core.Spell) {
-	if ret.SealOfVengeanceAura.IsActive() && core.MinInt32(ret.MaxSoVTargets, ret.Env.GetNumTargets()) > 1 {
+	numSoVTargets := core.MinInt32(ret.MaxSoVTargets, sim.GetNumActiveTargets())
+	if ret.SealOfVengeanceAura.IsActive() && numSoVTargets > 1 {
+		primaryTarget := sim.Encounter.PrimaryTarget()
 		minVengeanceDotDuration := time.Second * 15
-		minVengeanceDotDurationTargetIndex := int32(0)
+		minVengeanceDotDurationTarget := primaryTarget
 		minVengeanceDotStacks := int32(5)
-		minVengeanceDotStacksTargetIndex := int32(0)
-		for i := int32(0); i < core.MinInt32(ret.MaxSoVTargets, ret.Env.GetNumTargets()); i++ {
-			dot := ret.SealOfVengeanceDots[i]
+		minVengeanceDotStacksTarget := primaryTarget
+		for _, target := range sim.Encounter.ActiveTargets[:numSoVTargets] {
+			dot := ret.SealOfVengeanceDots[target.Index]
 			remainingDuration := dot.RemainingDuration(sim)
 			stackCount := dot.GetStacks()
 
 			if remainingDuration < minVengeanceDotDuration && remainingDuration > 0 {
 				minVengeanceDotDuration = remainingDuration
-				minVengeanceDotDurationTargetIndex = i
+				minVengeanceDotDurationTarget = target
 			}
 
 			if stackCount < minVengeanceDotStacks {
 				minVengeanceDotStacks = stackCount
-				minVengeanceDotStacksTargetIndex = i
+				minVengeanceDotStacksTarget = target
 			}
 		}
 
 		if minVengeanceDotDuration < ret.WeaponFromMainHand(0).SwingDuration*2 {
-			ret.CurrentTarget = &ret.Env.Encounter.Targets[minVengeanceDotDurationTargetIndex].Unit
+			ret.CurrentTarget = &minVengeanceDotDurationTarget.Unit
 		} else if ret.SealOfVengeanceDots[ret.CurrentTarget.Index].GetStacks() == 5 && minVengeanceDotStacks < 5 {
-			ret.CurrentTarget = &ret.Env.Encounter.Targets[minVengeanceDotStacksTargetIndex].Unit
+			ret.CurrentTarget = &minVengeanceDotStacksTarget.Unit
 		} else {
-			ret.CurrentTarget = &ret.Env.Encounter.Targets[0].Unit
+			ret.CurrentTarget = &primaryTarget.Unit
 		}
 	}
 }
@@ -50,7 +52,7 @@ func (ret *RetributionPaladin) OnGCDReady(sim *core.Simulation) {
 
 func (ret *RetributionPaladin) customRotation(sim *core.Simulation) {
 	// Setup
-	target := &ret.Env.Encounter.Targets[0].Unit
+	target := &sim.Encounter.PrimaryTarget().Unit
 
 	nextSwingAt := ret.AutoAttacks.NextAttackAt()
 	isExecutePhase := sim.IsExecutePhase20()
@@ -104,7 +106,7 @@ func (ret *RetributionPaladin) castSequenceRotation(sim *core.Simulation) {
 	}
 
 	// Setup
-	target := &ret.Env.Encounter.Targets[0].Unit
+	target := &sim.Encounter.PrimaryTarget().Unit
 	isExecutePhase := sim.IsExecutePhase20()
 
 	nextReadyAt := sim.CurrentTime
@@ -142,7 +144,7 @@ func (ret *RetributionPaladin) castSequenceRotation(sim *core.Simulation) {
 func (ret *RetributionPaladin) mainRotation(sim *core.Simulation) {
 
 	// Setup
-	target := &ret.Env.Encounter.Targets[0].Unit
+	target := &sim.Encounter.PrimaryTarget().Unit
 
 	nextSwingAt := ret.AutoAttacks.NextAttackAt()
 	isExecutePhase := sim.IsExecutePhase20()
@@ -163,17 +165,17 @@ func (ret *RetributionPaladin) mainRotation(sim *core.Simulation) {
 			if !success {
 				ret.WaitForMana(sim, ret.DivineStorm.CurCast.Cost)
 			}
-		case ret.Env.GetNumTargets() == 1 && isExecutePhase && ret.HammerOfWrath.IsReady(sim):
+		case sim.GetNumActiveTargets() == 1 && isExecutePhase && ret.HammerOfWrath.IsReady(sim):
 			success := ret.HammerOfWrath.Cast(sim, target)
 			if !success {
 				ret.WaitForMana(sim, ret.HammerOfWrath.CurCast.Cost)
 			}
-		case ret.Env.GetNumTargets() > 1 && ret.Consecration.IsReady(sim):
+		case sim.GetNumActiveTargets() > 1 && ret.Consecration.IsReady(sim):
 			success := ret.Consecration.Cast(sim, target)
 			if !success {
 				ret.WaitForMana(sim, ret.Consecration.CurCast.Cost)
 			}
-		case ret.DemonAndUndeadTargetCount >= ret.HolyWrathThreshold && ret.HolyWrath.IsReady(sim):
+		case ret.NumActiveDemonAndUndeadTargets(sim) >= ret.HolyWrathThreshold && ret.HolyWrath.IsReady(sim):
 			success := ret.HolyWrath.Cast(sim, target)
 			if !success {
 				ret.WaitForMana(sim, ret.HolyWrath.CurCast.Cost)
@@ -206,7 +208,7 @@ func (ret *RetributionPaladin) mainRotation(sim *core.Simulation) {
 			if !success {
 				ret.WaitForMana(sim, ret.Exorcism.CurCast.Cost)
 			}
-		case ret.DemonAndUndeadTargetCount >= 1 && ret.HolyWrath.IsReady(sim):
+		case ret.NumActiveDemonAndUndeadTargets(sim) >= 1 && ret.HolyWrath.IsReady(sim):
 			success := ret.HolyWrath.Cast(sim, target)
 			if !success {
 				ret.WaitForMana(sim, ret.HolyWrath.CurCast.Cost)
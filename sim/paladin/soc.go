@@ -30,14 +30,6 @@ func (paladin *Paladin) registerSealOfCommandSpellAndAura() {
 		OutcomeApplier: paladin.OutcomeFuncMeleeSpecialHitAndCrit(),
 	}
 
-	numHits := core.MinInt32(3, paladin.Env.GetNumTargets()) // primary target + 2 others
-	effects := make([]core.SpellEffect, 0, numHits)
-	for i := int32(0); i < numHits; i++ {
-		mhEffect := baseEffect
-		mhEffect.Target = paladin.Env.GetTargetUnit(i)
-		effects = append(effects, mhEffect)
-	}
-
 	baseMultiplierAdditive := 1 +
 		paladin.getItemSetLightswornBattlegearBonus4() +
 		paladin.getTalentTwoHandedWeaponSpecializationBonus()
@@ -54,7 +46,7 @@ func (paladin *Paladin) registerSealOfCommandSpellAndAura() {
 		CritMultiplier:           paladin.MeleeCritMultiplier(),
 		ThreatMultiplier:         1,
 
-		ApplyEffects: core.ApplyEffectFuncDamageMultiple(effects),
+		ApplyEffects: core.ApplyEffectFuncAOEDamageMaxHits(paladin.Env, baseEffect, 3), // primary target + 2 others
 	})
 
 	onSpecialOrSwingProc := paladin.RegisterSpell(core.SpellConfig{
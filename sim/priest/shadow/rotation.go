@@ -118,6 +118,19 @@ func (spriest *ShadowPriest) tryUseGCD(sim *core.Simulation) {
 		}
 	}
 
+	// Mind Blast, Mind Flay and Vampiric Touch can't be cast while moving, so
+	// keep the other dots up and use Shadow Word: Death instead.
+	if !spriest.MindBlast.FinishesBeforeMovement(sim) {
+		if instant := spriest.instantWhileMoving(sim); instant != nil {
+			spriest.castAndSnapshot(sim, instant)
+			return
+		} else if spriest.IsMoving(sim) {
+			dotsExpireAt := sim.CurrentTime + core.MinDuration(spriest.DevouringPlagueDot.RemainingDuration(sim), spriest.ShadowWordPainDot.RemainingDuration(sim))
+			spriest.WaitUntil(sim, core.MinDuration(spriest.MovementEndsAt(sim), core.MinDuration(dotsExpireAt, spriest.ShadowWordDeath.ReadyAt())))
+			return
+		}
+	}
+
 	// grab all of the shadow priest spell CDs remaining durations to use in the dps calculation
 	allCDs := []time.Duration{
 		core.MaxDuration(0, spriest.MindBlast.TimeToReady(sim)),
@@ -730,6 +743,11 @@ func (spriest *ShadowPriest) tryUseGCD(sim *core.Simulation) {
 		spriest.WaitUntil(sim, sim.CurrentTime+wait)
 		return
 	}
+	spriest.castAndSnapshot(sim, spell)
+}
+
+// Casts the spell, remembering the stats the dots were applied with.
+func (spriest *ShadowPriest) castAndSnapshot(sim *core.Simulation, spell *core.Spell) {
 	if success := spell.Cast(sim, spriest.CurrentTarget); !success {
 		spriest.WaitForMana(sim, spell.CurCast.Cost)
 	} else if spell == spriest.VampiricTouch {
@@ -1193,3 +1211,14 @@ func (spriest *ShadowPriest) ClippingMindflayRotation(sim *core.Simulation, allC
 		return 3
 	}
 }
+
+func (spriest *ShadowPriest) instantWhileMoving(sim *core.Simulation) *core.Spell {
+	if !spriest.DevouringPlagueDot.IsActive() {
+		return spriest.DevouringPlague
+	} else if !spriest.ShadowWordPainDot.IsActive() {
+		return spriest.ShadowWordPain
+	} else if spriest.ShadowWordDeath.IsReady(sim) {
+		return spriest.ShadowWordDeath
+	}
+	return nil
+}
@@ -276,6 +276,54 @@ func BenchmarkSimulate(b *testing.B) {
 	core.RaidBenchmark(b, rsr)
 }
 
+// Full 25-man raid against multiple targets, which stresses the pending action
+// queue with many concurrent DoTs, pets, and auto attacks.
+func BenchmarkSimulateFullRaid(b *testing.B) {
+	parties := make([]*proto.Party, 5)
+	for i := range parties {
+		parties[i] = &proto.Party{
+			Players: []*proto.Player{
+				P1BalanceDruid,
+				P1ElementalShaman,
+				P1EnhancementShaman,
+				P1ShadowPriest,
+				P1BMHunter,
+			},
+		}
+	}
+
+	target := &proto.Target{
+		Stats:   stats.Stats{stats.Armor: 7684}.ToFloatArray(),
+		MobType: proto.MobType_MobTypeDemon,
+	}
+	rsr := &proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: parties,
+			Buffs: &proto.RaidBuffs{
+				GiftOfTheWild:    proto.TristateEffect_TristateEffectImproved,
+				ArcaneBrilliance: true,
+				Bloodlust:        true,
+				WrathOfAirTotem:  true,
+				ManaSpringTotem:  proto.TristateEffect_TristateEffectImproved,
+			},
+			Debuffs: &proto.Debuffs{
+				JudgementOfWisdom: true,
+				CurseOfElements:   true,
+			},
+		},
+		Encounter: &proto.Encounter{
+			Duration:             180,
+			ExecuteProportion_20: 0.1,
+			Targets:              []*proto.Target{target, target, target},
+		},
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 101,
+		},
+	}
+
+	core.RaidBenchmark(b, rsr)
+}
+
 // P3 gear for each class
 
 // Shadow Priest Equipment
@@ -1,18 +1,25 @@
 package sim
 
 import (
+	"context"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/items"
 	"github.com/wowsims/wotlk/sim/core/proto"
 	"github.com/wowsims/wotlk/sim/core/stats"
 	"google.golang.org/protobuf/encoding/protojson"
+	googleProto "google.golang.org/protobuf/proto"
 
 	balanceDruid "github.com/wowsims/wotlk/sim/druid/balance"
 	hunter "github.com/wowsims/wotlk/sim/hunter"
 	shadowPriest "github.com/wowsims/wotlk/sim/priest/shadow"
 	elementalShaman "github.com/wowsims/wotlk/sim/shaman/elemental"
 	enhancementShaman "github.com/wowsims/wotlk/sim/shaman/enhancement"
+
+	"github.com/wowsims/wotlk/sim/encounters/naxxrammas"
 )
 
 func init() {
@@ -104,6 +111,16 @@ var BasicRaid = &proto.Raid{
 	},
 }
 
+// Runs a raid sim, failing the test if the sim returns an error.
+func runRaidSim(t *testing.T, rsr *proto.RaidSimRequest) *proto.RaidSimResult {
+	t.Helper()
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("Sim failed with error: %s", result.ErrorResult)
+	}
+	return result
+}
+
 // Tests that we don't crash with various combinations of empty parties / blank players.
 func TestSparseRaid(t *testing.T) {
 	sparseRaid := &proto.Raid{
@@ -163,3 +180,670 @@ func testRaidString(t *testing.T, raidString string) {
 // 	testRaidString(t, `
 // 	`)
 // }
+
+// Parallel sims with the same seed and worker count must give identical results.
+func TestParallelSimDeterminism(t *testing.T) {
+	rsr := &proto.RaidSimRequest{
+		Raid:      BasicRaid,
+		Encounter: STEncounter,
+		SimOptions: &proto.SimOptions{
+			Iterations: 50,
+			RandomSeed: 101,
+			NumWorkers: 4,
+		},
+	}
+
+	result1 := runRaidSim(t, rsr)
+	result2 := core.RunRaidSim(rsr)
+	if !googleProto.Equal(result1, result2) {
+		t.Fatalf("Parallel sims with identical options produced different results")
+	}
+
+	numIterations := int32(0)
+	for _, count := range result1.RaidMetrics.Dps.Hist {
+		numIterations += count
+	}
+	if numIterations != rsr.SimOptions.Iterations {
+		t.Fatalf("Expected %d iterations in merged results, got %d", rsr.SimOptions.Iterations, numIterations)
+	}
+}
+
+func TestParallelSimMatchesSerial(t *testing.T) {
+	healthTarget := core.NewDefaultTarget()
+	healthTarget.Stats = stats.Stats{stats.Armor: 10643, stats.Health: 5_000_000}.ToFloatArray()
+	healthEncounter := &proto.Encounter{
+		UseHealth: true,
+		Targets:   []*proto.Target{healthTarget},
+	}
+
+	for _, encounter := range []*proto.Encounter{STEncounter, healthEncounter} {
+		var results [2]*proto.RaidSimResult
+		for i, numWorkers := range []int32{1, 4} {
+			results[i] = runRaidSim(t, &proto.RaidSimRequest{
+				Raid:       BasicRaid,
+				Encounter:  encounter,
+				SimOptions: &proto.SimOptions{Iterations: 200, RandomSeed: 101, NumWorkers: numWorkers},
+			})
+		}
+
+		serial, parallel := results[0].RaidMetrics.Dps, results[1].RaidMetrics.Dps
+		maxDiff := 4 * math.Sqrt((serial.Stdev*serial.Stdev+parallel.Stdev*parallel.Stdev)/200)
+		if math.Abs(serial.Avg-parallel.Avg) > maxDiff {
+			t.Fatalf("Expected parallel DPS within %0.1f of serial DPS, got %0.1f vs %0.1f", maxDiff, parallel.Avg, serial.Avg)
+		}
+		if encounter.UseHealth && math.Abs(results[0].AvgIterationDuration-results[1].AvgIterationDuration) > 0.05*results[0].AvgIterationDuration {
+			t.Fatalf("Expected parallel fight length close to serial, got %0.1fs vs %0.1fs", results[1].AvgIterationDuration, results[0].AvgIterationDuration)
+		}
+	}
+}
+
+func TestStructuredCombatLog(t *testing.T) {
+	rsr := &proto.RaidSimRequest{
+		Raid:      BasicRaid,
+		Encounter: STEncounter,
+		SimOptions: &proto.SimOptions{
+			Iterations:    4,
+			RandomSeed:    101,
+			StructuredLog: true,
+		},
+	}
+
+	result := runRaidSim(t, rsr)
+	if len(result.CombatLog) == 0 {
+		t.Fatalf("Expected combat log events")
+	}
+
+	numDamageEvents := 0
+	for _, event := range result.CombatLog {
+		if event.Iteration != 0 {
+			t.Fatalf("Expected only first iteration events without debug, got iteration %d", event.Iteration)
+		}
+		if event.Type == proto.CombatLogEventType_CombatLogEventTypeDamage {
+			numDamageEvents++
+		}
+	}
+	if numDamageEvents == 0 {
+		t.Fatalf("Expected damage events in combat log")
+	}
+
+	rsr.SimOptions.NumWorkers = 2
+	parallelResult := core.RunRaidSim(rsr)
+	if len(parallelResult.CombatLog) != len(result.CombatLog) {
+		t.Fatalf("Expected parallel sim to log the same first iteration, got %d events vs %d", len(parallelResult.CombatLog), len(result.CombatLog))
+	}
+}
+
+func TestSimCancellation(t *testing.T) {
+	rsr := &proto.RaidSimRequest{
+		Raid:      BasicRaid,
+		Encounter: STEncounter,
+		SimOptions: &proto.SimOptions{
+			Iterations: 1000,
+			RandomSeed: 101,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := core.RunSimWithContext(ctx, *rsr, nil)
+	if !strings.Contains(result.ErrorResult, "cancelled") {
+		t.Fatalf("Expected cancelled error result, got: %s", result.ErrorResult)
+	}
+
+	rsr.SimOptions.NumWorkers = 2
+	result = core.RunSimWithContext(ctx, *rsr, nil)
+	if !strings.Contains(result.ErrorResult, "cancelled") {
+		t.Fatalf("Expected cancelled error result from parallel sim, got: %s", result.ErrorResult)
+	}
+}
+
+func TestNaxxBossMechanics(t *testing.T) {
+	runBoss := func(paths ...string) *proto.RaidSimResult {
+		encounter := &proto.Encounter{Duration: 360}
+		for _, path := range paths {
+			config := googleProto.Clone(&core.GetPresetTargetWithPath("Naxxrammas/" + path).Config).(*proto.Target)
+			encounter.Targets = append(encounter.Targets, config)
+		}
+		return runRaidSim(t, &proto.RaidSimRequest{
+			Raid:       BasicRaid,
+			Encounter:  encounter,
+			SimOptions: &proto.SimOptions{Iterations: 10, RandomSeed: 101},
+		})
+	}
+
+	patchwerkDps := runBoss("Patchwerk 25").RaidMetrics.Dps.Avg
+	if dps := runBoss("Thaddius 25").RaidMetrics.Dps.Avg; dps <= patchwerkDps {
+		t.Fatalf("Expected charges to increase DPS on Thaddius, got %0.1f vs %0.1f", dps, patchwerkDps)
+	}
+	if dps := runBoss("Loatheb 25").RaidMetrics.Dps.Avg; dps <= patchwerkDps {
+		t.Fatalf("Expected spores to increase DPS on Loatheb, got %0.1f vs %0.1f", dps, patchwerkDps)
+	}
+
+	result := runBoss("Kel'Thuzad 25", "Soldier of the Frozen Wastes 25", "Unstoppable Abomination 25", "Soul Weaver 25")
+	phases := result.EncounterMetrics.Phases
+	if len(phases) != 2 || phases[1].AvgStartTime != 228 {
+		t.Fatalf("Expected Kel'Thuzad phase 2 to start at 228s, got %v", phases)
+	}
+}
+
+func TestLoathebSporesAndNecroticAura(t *testing.T) {
+	config := googleProto.Clone(&core.GetPresetTargetWithPath("Naxxrammas/Loatheb 25").Config).(*proto.Target)
+	sim := core.NewSim(proto.RaidSimRequest{
+		Raid:       BasicRaid,
+		Encounter:  &proto.Encounter{Duration: 360, Targets: []*proto.Target{config}},
+		SimOptions: &proto.SimOptions{Iterations: 1, RandomSeed: 101},
+	})
+	sim.Reset()
+
+	loatheb := &sim.Encounter.Targets[0].Unit
+	character := sim.Raid.Parties[0].Players[0].GetCharacter()
+	baseMeleeCrit := character.GetStat(stats.MeleeCrit)
+	baseSpellCrit := character.GetStat(stats.SpellCrit)
+
+	// BasicRaid has 2 groups with players, so the 3rd spore goes back to the first group.
+	summonSpore := loatheb.GetSpell(core.ActionID{SpellID: 29234})
+	for i := 0; i < 3; i++ {
+		summonSpore.CD.Reset()
+		summonSpore.Cast(sim, loatheb)
+	}
+
+	if stacks := character.GetAura("Fungal Creep").GetStacks(); stacks != 2 {
+		t.Fatalf("Expected 2 stacks of Fungal Creep, got %d", stacks)
+	}
+	expectedCrit := 2 * 50 * core.CritRatingPerCritChance
+	if crit := character.GetStat(stats.MeleeCrit) - baseMeleeCrit; math.Abs(crit-expectedCrit) > 0.001 {
+		t.Fatalf("Expected Fungal Creep to add %0.1f melee crit, got %0.1f", expectedCrit, crit)
+	}
+	if crit := character.GetStat(stats.SpellCrit) - baseSpellCrit; math.Abs(crit-expectedCrit) > 0.001 {
+		t.Fatalf("Expected Fungal Creep to add %0.1f spell crit, got %0.1f", expectedCrit, crit)
+	}
+	if !loatheb.GetAura("Spore Cloud").IsActive() {
+		t.Fatalf("Expected spores to apply Spore Cloud to Loatheb")
+	}
+
+	healingTakenMultiplier := character.PseudoStats.HealingTakenMultiplier
+	necroticAura := loatheb.GetSpell(core.ActionID{SpellID: 55593})
+	necroticAura.Cast(sim, loatheb)
+	if multiplier := character.PseudoStats.HealingTakenMultiplier; multiplier != 0 {
+		t.Fatalf("Expected Necrotic Aura to prevent healing, got healing taken multiplier %0.2f", multiplier)
+	}
+	character.GetAura("Necrotic Aura").Deactivate(sim)
+	if multiplier := character.PseudoStats.HealingTakenMultiplier; multiplier != healingTakenMultiplier {
+		t.Fatalf("Expected healing taken multiplier to be restored to %0.2f, got %0.2f", healingTakenMultiplier, multiplier)
+	}
+}
+
+func TestTargetDeath(t *testing.T) {
+	runHealthFight := func(requiredTargets []int32) float64 {
+		encounter := &proto.Encounter{
+			UseHealth:       true,
+			RequiredTargets: requiredTargets,
+		}
+		for _, health := range []float64{1_000_000, 2_000_000} {
+			target := core.NewDefaultTarget()
+			target.Stats = stats.Stats{stats.Armor: 10643, stats.Health: health}.ToFloatArray()
+			encounter.Targets = append(encounter.Targets, target)
+		}
+		result := runRaidSim(t, &proto.RaidSimRequest{
+			Raid:       BasicRaid,
+			Encounter:  encounter,
+			SimOptions: &proto.SimOptions{Iterations: 10, RandomSeed: 101},
+		})
+		return result.AvgIterationDuration
+	}
+
+	firstOnly := runHealthFight([]int32{0})
+	allTargets := runHealthFight(nil)
+	if firstOnly <= 0 || allTargets <= firstOnly*2 {
+		t.Fatalf("Expected players to retarget and kill both targets, got %0.1fs vs %0.1fs for the first target", allTargets, firstOnly)
+	}
+}
+
+func TestEncounterDefinitions(t *testing.T) {
+	grobbulus := core.GetPresetTargetWithPath("Naxxrammas/Grobbulus 25")
+	if grobbulus == nil {
+		t.Fatalf("Expected Grobbulus 25 to be loaded from the encounter definitions")
+	}
+	if health := grobbulus.Config.Stats[stats.Health]; health != 9761500 {
+		t.Fatalf("Expected Grobbulus 25 to have 9761500 health, got %0.0f", health)
+	}
+
+	target := core.NewDefaultTarget()
+	target.Abilities = []*proto.TargetAbility{
+		{
+			SpellId:   55807,
+			School:    proto.SpellSchool_SpellSchoolFrost,
+			MinDamage: 4500,
+			MaxDamage: 5500,
+			Cooldown:  15,
+			Targeting: proto.TargetAbilityTargeting_TargetAbilityTargetingRaid,
+		},
+	}
+	result := runRaidSim(t, &proto.RaidSimRequest{
+		Raid:       BasicRaid,
+		Encounter:  &proto.Encounter{Duration: 60, Targets: []*proto.Target{target}},
+		SimOptions: &proto.SimOptions{Iterations: 1, RandomSeed: 101},
+	})
+	// Nobody tanks the target, so all damage taken is from the raid-wide ability.
+	if dtps := result.RaidMetrics.Parties[0].Players[0].Dtps.Avg; dtps < 4500*4/60.0 {
+		t.Fatalf("Expected the raid-wide ability to hit every 15s, got %0.1f DTPS", dtps)
+	}
+}
+
+func TestRaidDamage(t *testing.T) {
+	runWithRaidDamage := func(raidDamage []*proto.RaidDamageEvent) *proto.RaidSimResult {
+		encounter := core.MakeSingleTargetEncounter(0)
+		encounter.RaidDamage = raidDamage
+		return runRaidSim(t, &proto.RaidSimRequest{
+			Raid:       BasicRaid,
+			Encounter:  encounter,
+			SimOptions: &proto.SimOptions{Iterations: 10, RandomSeed: 101},
+		})
+	}
+	totalDtps := func(result *proto.RaidSimResult) float64 {
+		total := 0.0
+		for _, party := range result.RaidMetrics.Parties {
+			for _, player := range party.Players {
+				total += player.Dtps.Avg
+			}
+		}
+		return total
+	}
+
+	pulse := &proto.RaidDamageEvent{
+		SpellId:   55807,
+		School:    proto.SpellSchool_SpellSchoolShadow,
+		MinDamage: 3000,
+		MaxDamage: 3000,
+		Interval:  5,
+	}
+	result := runWithRaidDamage([]*proto.RaidDamageEvent{pulse})
+	for _, party := range result.RaidMetrics.Parties {
+		for _, player := range party.Players {
+			if player.Dtps.Avg < 400 {
+				t.Fatalf("Expected every player to take damage from the pulse, %s got %0.1f DTPS", player.Name, player.Dtps.Avg)
+			}
+		}
+	}
+
+	dot := &proto.RaidDamageEvent{
+		SpellId:      28169,
+		School:       proto.SpellSchool_SpellSchoolNature,
+		MinDamage:    1000,
+		MaxDamage:    1000,
+		Interval:     10,
+		NumTargets:   2,
+		DotDuration:  5,
+		TickInterval: 1,
+	}
+	withDot := runWithRaidDamage([]*proto.RaidDamageEvent{pulse, dot})
+	if totalDtps(withDot) <= totalDtps(result)+400 {
+		t.Fatalf("Expected the DoT to add raid damage taken, got %0.1f vs %0.1f DTPS", totalDtps(withDot), totalDtps(result))
+	}
+}
+
+func TestCasterMovement(t *testing.T) {
+	runWithMovement := func(movement []*proto.MovementEvent) *proto.UnitMetrics {
+		encounter := core.MakeSingleTargetEncounter(0)
+		encounter.Movement = movement
+		result := runRaidSim(t, &proto.RaidSimRequest{
+			Raid:       core.SinglePlayerRaidProto(P1ElementalShaman, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+			Encounter:  encounter,
+			SimOptions: &proto.SimOptions{Iterations: 10, RandomSeed: 101},
+		})
+		return result.RaidMetrics.Parties[0].Players[0]
+	}
+	earthShockCasts := func(metrics *proto.UnitMetrics) int32 {
+		casts := int32(0)
+		for _, action := range metrics.Actions {
+			if action.Id.GetSpellId() == 49231 {
+				for _, target := range action.Targets {
+					casts += target.Casts
+				}
+			}
+		}
+		return casts
+	}
+
+	standing := runWithMovement(nil)
+	moving := runWithMovement([]*proto.MovementEvent{{StartTime: 5, Duration: 4, Interval: 10}})
+	if earthShockCasts(standing) != 0 || earthShockCasts(moving) == 0 {
+		t.Fatalf("Expected Earth Shock to be used only while moving, got %d casts standing and %d moving",
+			earthShockCasts(standing), earthShockCasts(moving))
+	}
+	if moving.Dps.Avg >= standing.Dps.Avg {
+		t.Fatalf("Expected movement to lower DPS, got %0.1f moving vs %0.1f standing", moving.Dps.Avg, standing.Dps.Avg)
+	}
+}
+
+func TestRaidTierPresets(t *testing.T) {
+	presets := map[string]*proto.PresetEncounter{}
+	for _, preset := range core.GetGearList(&proto.GearListRequest{}).Encounters {
+		presets[preset.Path] = preset
+	}
+	for path, numTargets := range map[string]int{
+		"Naxxrammas/Patchwerk 10":                   1,
+		"Ulduar/Hodir 10":                           1,
+		"Ulduar/Assembly of Iron 25":                3,
+		"Trial of the Crusader/Twin Val'kyr 10":     2,
+		"Trial of the Crusader/Northrend Beasts 25": 4,
+	} {
+		if preset := presets[path]; preset == nil || len(preset.Targets) != numTargets {
+			t.Fatalf("Expected preset encounter %s with %d targets", path, numTargets)
+		}
+	}
+
+	// Both Patchwerks share an NPC ID, so make sure each gets its own AI.
+	patchwerk10 := core.GetPresetTargetWithPath("Naxxrammas/Patchwerk 10").Config
+	if _, ok := core.NewTarget(patchwerk10, 0).AI.(*naxxrammas.Patchwerk10AI); !ok {
+		t.Fatalf("Expected Patchwerk 10 to use the 10 man AI")
+	}
+
+	// Scale down the beasts' health so the whole fight fits in the duration.
+	encounter := &proto.Encounter{Duration: 180}
+	for _, target := range presets["Trial of the Crusader/Northrend Beasts 10"].Targets {
+		config := googleProto.Clone(target.Target).(*proto.Target)
+		config.Stats[stats.Health] = 1_000_000
+		encounter.Targets = append(encounter.Targets, config)
+	}
+	result := runRaidSim(t, &proto.RaidSimRequest{
+		Raid:       BasicRaid,
+		Encounter:  encounter,
+		SimOptions: &proto.SimOptions{Iterations: 5, RandomSeed: 101},
+	})
+	phases := result.EncounterMetrics.Phases
+	if len(phases) != 3 || phases[2].AvgStartTime <= phases[1].AvgStartTime || phases[1].AvgStartTime <= 0 {
+		t.Fatalf("Expected the beasts to arrive one after another, got %v", phases)
+	}
+}
+
+func TestPairedStatWeights(t *testing.T) {
+	runStatWeights := func(paired bool, maxWeightError float64) *proto.StatWeightsResult {
+		result := core.StatWeights(&proto.StatWeightsRequest{
+			Player:          googleProto.Clone(P1BalanceDruid).(*proto.Player),
+			RaidBuffs:       core.FullRaidBuffs,
+			PartyBuffs:      core.FullPartyBuffs,
+			Debuffs:         core.FullDebuffs,
+			Encounter:       &proto.Encounter{Duration: 60, Targets: []*proto.Target{core.NewDefaultTarget()}},
+			SimOptions:      &proto.SimOptions{Iterations: 100, RandomSeed: 101},
+			StatsToWeigh:    []proto.Stat{proto.Stat_StatSpellPower, proto.Stat_StatSpellHaste},
+			EpReferenceStat: proto.Stat_StatSpellPower,
+
+			PairedIterations: paired,
+			MaxWeightError:   maxWeightError,
+		})
+		if result.ErrorResult != "" {
+			t.Fatalf("Stat weights failed with error: %s", result.ErrorResult)
+		}
+		return result
+	}
+	sp := int(proto.Stat_StatSpellPower)
+
+	unpaired := runStatWeights(false, 0)
+	paired := runStatWeights(true, 0)
+	if paired.Iterations != 50 {
+		t.Fatalf("Expected paired stat sims to run half the iterations, got %d", paired.Iterations)
+	}
+	if paired.Dps.Weights[sp] <= 0 || paired.Dps.WeightsStdev[sp] >= unpaired.Dps.WeightsStdev[sp]/2 {
+		t.Fatalf("Expected paired iterations to reduce noise, got %0.3f +/- %0.3f vs %0.3f +/- %0.3f",
+			paired.Dps.Weights[sp], paired.Dps.WeightsStdev[sp], unpaired.Dps.Weights[sp], unpaired.Dps.WeightsStdev[sp])
+	}
+	// A balance druid does no healing, so its healing weights must not pick up other metrics.
+	if unpaired.Hps.Weights[sp] != 0 {
+		t.Fatalf("Expected no healing weight, got %0.3f", unpaired.Hps.Weights[sp])
+	}
+
+	const maxWeightError = 0.2
+	converged := runStatWeights(false, maxWeightError)
+	if converged.Iterations <= 50 {
+		t.Fatalf("Expected more iterations to converge, got %d", converged.Iterations)
+	}
+	for _, stat := range []proto.Stat{proto.Stat_StatSpellPower, proto.Stat_StatSpellHaste} {
+		if stdErr := converged.Dps.WeightsStdev[stat] / math.Sqrt(float64(converged.Iterations)); stdErr > maxWeightError {
+			t.Fatalf("Expected %s weight error below %0.2f, got %0.3f", stat, maxWeightError, stdErr)
+		}
+	}
+}
+
+func TestStatCurve(t *testing.T) {
+	result := core.StatCurve(&proto.StatCurveRequest{
+		Player:     P1BalanceDruid,
+		RaidBuffs:  core.FullRaidBuffs,
+		PartyBuffs: core.FullPartyBuffs,
+		Debuffs:    core.FullDebuffs,
+		Encounter:  &proto.Encounter{Duration: 60, Targets: []*proto.Target{core.NewDefaultTarget()}},
+		SimOptions: &proto.SimOptions{Iterations: 20, RandomSeed: 101},
+		Ranges: []*proto.StatRange{
+			{Stat: proto.Stat_StatSpellPower, Min: 1000, Max: 3000, Step: 1000},
+			{Stat: proto.Stat_StatSpellHaste, Min: 0, Max: 400, Step: 400},
+		},
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Stat curve failed with error: %s", result.ErrorResult)
+	}
+	if len(result.Points) != 6 {
+		t.Fatalf("Expected 6 points, got %d", len(result.Points))
+	}
+	if values := result.Points[3].StatValues; values[0] != 2000 || values[1] != 400 {
+		t.Fatalf("Expected points in order of the first stat, got %v", values)
+	}
+
+	for i, point := range result.Points {
+		if point.Dps.Lower >= point.Dps.Avg || point.Dps.Upper <= point.Dps.Avg {
+			t.Fatalf("Expected a confidence interval around %0.1f DPS, got %0.1f - %0.1f", point.Dps.Avg, point.Dps.Lower, point.Dps.Upper)
+		}
+		if i >= 2 && point.Dps.Avg <= result.Points[i-2].Dps.Avg {
+			t.Fatalf("Expected more spell power to increase DPS at %v, got %0.1f vs %0.1f", point.StatValues, point.Dps.Avg, result.Points[i-2].Dps.Avg)
+		}
+	}
+}
+
+func TestStatCurveError(t *testing.T) {
+	result := core.StatCurve(&proto.StatCurveRequest{
+		Player: P1BalanceDruid,
+		Encounter: &proto.Encounter{
+			Duration: 60,
+			Targets:  []*proto.Target{core.NewDefaultTarget()},
+			Phases:   []*proto.EncounterPhase{{Name: "Adds", ActivateTargets: []int32{1}}},
+		},
+		SimOptions: &proto.SimOptions{Iterations: 20, RandomSeed: 101},
+		Ranges: []*proto.StatRange{
+			{Stat: proto.Stat_StatSpellPower, Min: 0, Max: 2000, Step: 1},
+		},
+	})
+	if result.ErrorResult == "" || strings.Contains(result.ErrorResult, "cancelled") {
+		t.Fatalf("Expected the first failed point's error, got %q", result.ErrorResult)
+	}
+}
+
+func TestGearOptimizer(t *testing.T) {
+	player := googleProto.Clone(P1BalanceDruid).(*proto.Player)
+	player.Profession1 = proto.Profession_Jewelcrafting
+	player.Profession2 = proto.Profession_Tailoring
+
+	rings := []*proto.ItemSpec{{Id: 40399}, {Id: 40080}, {Id: 37192}}
+	result := core.GearOptimizer(&proto.GearOptimizerRequest{
+		Player:     player,
+		RaidBuffs:  core.FullRaidBuffs,
+		PartyBuffs: core.FullPartyBuffs,
+		Debuffs:    core.FullDebuffs,
+		Encounter:  &proto.Encounter{Duration: 60, Targets: []*proto.Target{core.NewDefaultTarget()}},
+		SimOptions: &proto.SimOptions{Iterations: 20, RandomSeed: 101},
+		Slots: []*proto.GearOptimizerSlot{
+			{Slot: proto.ItemSlot_ItemSlotHead, Items: []*proto.ItemSpec{{Id: 40467}}},
+			{Slot: proto.ItemSlot_ItemSlotChest, Items: []*proto.ItemSpec{{Id: 40469}}},
+			{Slot: proto.ItemSlot_ItemSlotWaist, Items: []*proto.ItemSpec{{Id: 40561}}},
+			{Slot: proto.ItemSlot_ItemSlotFinger1, Items: rings},
+			{Slot: proto.ItemSlot_ItemSlotFinger2, Items: rings},
+		},
+		// Runed Scarlet Ruby, Purified Twilight Opal, Runed Dragon's Eye and Chaotic Skyflare Diamond.
+		Gems: []int32{39998, 40026, 42144, 41285},
+		StatWeights: stats.Stats{
+			stats.SpellPower: 1,
+			stats.SpellCrit:  0.5,
+			stats.Spirit:     0.1,
+		}.ToFloatArray(),
+		NumSims: 3,
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Gear optimizer failed with error: %s", result.ErrorResult)
+	}
+	if len(result.Candidates) != 3 {
+		t.Fatalf("Expected 3 candidates, got %d", len(result.Candidates))
+	}
+
+	for i, candidate := range result.Candidates {
+		if i > 0 && candidate.Dps > result.Candidates[i-1].Dps {
+			t.Fatalf("Expected candidates sorted by DPS, got %0.1f after %0.1f", candidate.Dps, result.Candidates[i-1].Dps)
+		}
+		if candidate.DpsError <= 0 {
+			t.Fatalf("Expected a DPS error, got %0.2f", candidate.DpsError)
+		}
+
+		equipment := items.ProtoToEquipment(*candidate.Equipment)
+		if !equipment.HasActiveMetaGem() {
+			t.Fatalf("Expected an active meta gem, got %v", candidate.Equipment)
+		}
+		if equipment[items.ItemSlotFinger1].ID == equipment[items.ItemSlotFinger2].ID {
+			t.Fatalf("Expected different unique rings, got %v", candidate.Equipment)
+		}
+		numDragonsEyes := 0
+		for _, item := range equipment {
+			for _, gem := range item.Gems {
+				if gem.ID == 42144 {
+					numDragonsEyes++
+				}
+			}
+		}
+		if numDragonsEyes != 3 {
+			t.Fatalf("Expected 3 Dragon's Eyes, got %d", numDragonsEyes)
+		}
+	}
+}
+
+func TestGemFill(t *testing.T) {
+	player := googleProto.Clone(P1BalanceDruid).(*proto.Player)
+	player.Profession1 = proto.Profession_Jewelcrafting
+	player.Profession2 = proto.Profession_Tailoring
+
+	weights := stats.Stats{
+		stats.SpellPower: 1,
+		stats.SpellHit:   1.2,
+		stats.SpellCrit:  0.5,
+		stats.SpellHaste: 0.6,
+		stats.Spirit:     0.1,
+	}
+	result := core.GemFill(&proto.GemFillRequest{
+		Player:      player,
+		StatWeights: weights.ToFloatArray(),
+		Phase:       1,
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Gem fill failed with error: %s", result.ErrorResult)
+	}
+	if !result.MetaGemActive {
+		t.Fatalf("Expected an active meta gem")
+	}
+
+	equipment := items.ProtoToEquipment(*result.Equipment)
+	originalScore := 0.0
+	for _, value := range items.ProtoToEquipment(*player.Equipment).Stats().DotProduct(weights) {
+		originalScore += value
+	}
+	if result.Score < originalScore {
+		t.Fatalf("Expected the filled gear to score at least as high as the original, got %0.1f vs %0.1f", result.Score, originalScore)
+	}
+
+	gemCounts := make(map[int32]int)
+	for slot, item := range equipment {
+		if result.SocketBonuses[slot] != item.HasSocketBonus() {
+			t.Fatalf("Wrong socket bonus for %s", item.Name)
+		}
+		if item.Enchant.ID != 0 && item.Enchant.RequiredProfession != proto.Profession_ProfessionUnknown &&
+			item.Enchant.RequiredProfession != proto.Profession_Tailoring {
+			t.Fatalf("Expected no enchants from other professions, got %s", item.Enchant.Name)
+		}
+		for _, gem := range item.Gems {
+			gemCounts[gem.ID]++
+			if gem.Phase > 1 {
+				t.Fatalf("Expected only phase 1 gems, got %s", gem.Name)
+			}
+		}
+	}
+	numJewelcrafting := 0
+	for gemID, count := range gemCounts {
+		gem := items.GemsByID[gemID]
+		if gem.Unique && count > 1 {
+			t.Fatalf("Expected unique gems at most once, got %d %s", count, gem.Name)
+		}
+		if gem.RequiredProfession == proto.Profession_Jewelcrafting {
+			numJewelcrafting += count
+		}
+	}
+	if numJewelcrafting != 3 {
+		t.Fatalf("Expected 3 Jewelcrafting gems, got %d", numJewelcrafting)
+	}
+}
+
+func TestTalentComparison(t *testing.T) {
+	player := googleProto.Clone(P1BalanceDruid).(*proto.Player)
+	player.Glyphs = balanceDruid.StandardGlyphs
+
+	parsed := googleProto.Clone(player).(*proto.Player)
+	if err := core.SetTalentsFromString(parsed, "5032003115331303213305311231--205003012"); err != nil {
+		t.Fatalf("Failed to parse talents: %s", err)
+	}
+	talents := parsed.GetBalanceDruid().Talents
+	if talents.StarlightWrath != 5 || talents.Moonfury != 3 || !talents.NaturesSplendor || talents.ImprovedMarkOfTheWild != 2 {
+		t.Fatalf("Talents parsed incorrectly: %v", talents)
+	}
+	if err := core.SetTalentsFromString(parsed, "9"); err == nil {
+		t.Fatalf("Expected an error for too many points in a talent")
+	}
+
+	result := core.TalentComparison(&proto.TalentComparisonRequest{
+		Player:     player,
+		RaidBuffs:  core.FullRaidBuffs,
+		PartyBuffs: core.FullPartyBuffs,
+		Debuffs:    core.FullDebuffs,
+		Encounter:  &proto.Encounter{Duration: 60, Targets: []*proto.Target{core.NewDefaultTarget()}},
+		SimOptions: &proto.SimOptions{Iterations: 20, RandomSeed: 101},
+		TalentsStrings: []string{
+			"5032003115331303213305311231--205003012",
+			"--205003012",
+		},
+		Glyphs: []*proto.Glyphs{
+			balanceDruid.StandardGlyphs,
+			{},
+		},
+		TalentPointValues: true,
+	})
+	if result.ErrorResult != "" {
+		t.Fatalf("Talent comparison failed with error: %s", result.ErrorResult)
+	}
+	if len(result.Variants) != 4 {
+		t.Fatalf("Expected 4 variants, got %d", len(result.Variants))
+	}
+	for i, variant := range result.Variants {
+		if i > 0 && variant.Dps > result.Variants[i-1].Dps {
+			t.Fatalf("Expected variants sorted by DPS")
+		}
+	}
+	worst := result.Variants[len(result.Variants)-1]
+	if worst.TalentsString != "--205003012" || !worst.Significant || worst.DpsDelta >= 0 {
+		t.Fatalf("Expected a significant loss without balance talents, got %s: %0.1f +- %0.1f", worst.TalentsString, worst.DpsDelta, worst.DpsDeltaError)
+	}
+
+	if len(result.TalentPoints) == 0 {
+		t.Fatalf("Expected talent point values")
+	}
+	for i, point := range result.TalentPoints {
+		if point.RemovedTalent == "" {
+			t.Fatalf("Expected the removed talent to be named")
+		}
+		if i > 0 && point.DpsDelta < result.TalentPoints[i-1].DpsDelta {
+			t.Fatalf("Expected talent points sorted by value")
+		}
+	}
+}
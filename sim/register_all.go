@@ -7,7 +7,10 @@ import (
 	"github.com/wowsims/wotlk/sim/druid/balance"
 	"github.com/wowsims/wotlk/sim/druid/feral"
 	feralTank "github.com/wowsims/wotlk/sim/druid/tank"
-	_ "github.com/wowsims/wotlk/sim/encounters"
+	"github.com/wowsims/wotlk/sim/encounters"
+	"github.com/wowsims/wotlk/sim/encounters/naxxrammas"
+	"github.com/wowsims/wotlk/sim/encounters/toc"
+	"github.com/wowsims/wotlk/sim/encounters/ulduar"
 	"github.com/wowsims/wotlk/sim/hunter"
 	"github.com/wowsims/wotlk/sim/mage"
 	protectionPaladin "github.com/wowsims/wotlk/sim/paladin/protection"
@@ -23,6 +26,14 @@ import (
 	protectionWarrior "github.com/wowsims/wotlk/sim/warrior/protection"
 )
 
+func init() {
+	// Registered on import, so presets are available without calling RegisterAll.
+	naxxrammas.Register()
+	ulduar.Register()
+	toc.Register()
+	encounters.RegisterDefinitions()
+}
+
 var registered = false
 
 func RegisterAll() {
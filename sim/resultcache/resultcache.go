@@ -0,0 +1,237 @@
+// Package resultcache stores sim results on disk, keyed by a hash of the request
+// that produced them, so identical seeded requests don't need to be re-simmed.
+package resultcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const fileExt = ".binpb"
+
+// Cache is a least-recently-used store of results, one file per entry. Recency
+// is tracked with file modification times so it survives restarts.
+type Cache struct {
+	dir        string
+	maxEntries int
+
+	// Mixed into every key, so results from a different sim build are never used.
+	version string
+
+	mu      sync.Mutex
+	lru     *list.List // Keys, most recently used first.
+	entries map[string]*list.Element
+}
+
+// Opens (or creates) a cache in dir holding at most maxEntries results.
+func New(dir string, maxEntries int, version string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type entry struct {
+		key     string
+		modTime time.Time
+	}
+	var existing []entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), fileExt) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		existing = append(existing, entry{strings.TrimSuffix(file.Name(), fileExt), info.ModTime()})
+	}
+	sort.Slice(existing, func(i, j int) bool {
+		return existing[i].modTime.After(existing[j].modTime)
+	})
+
+	cache := &Cache{
+		dir:        dir,
+		maxEntries: maxEntries,
+		version:    version,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+	for _, e := range existing {
+		cache.entries[e.key] = cache.lru.PushBack(e.key)
+	}
+	cache.mu.Lock()
+	cache.evict()
+	cache.mu.Unlock()
+	return cache, nil
+}
+
+// Returns whether results for request are reproducible, and so can be cached.
+// Requests with a zero seed get a random one, so their results can't be reused.
+func Cacheable(request interface{ GetSimOptions() *proto.SimOptions }) bool {
+	return request.GetSimOptions().GetRandomSeed() != 0
+}
+
+// Returns the cache key for a request. Equal requests always have the same key,
+// regardless of field or map ordering in their original encoding.
+func (cache *Cache) Key(request googleProto.Message) (string, error) {
+	canonical := googleProto.Clone(request)
+	discardUnknown(canonical.ProtoReflect())
+	data, err := googleProto.MarshalOptions{Deterministic: true}.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	io.WriteString(hash, cache.version)
+	hash.Write([]byte{0})
+	io.WriteString(hash, string(request.ProtoReflect().Descriptor().FullName()))
+	hash.Write([]byte{0})
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Loads the result stored under key into result. Returns false if there is none.
+func (cache *Cache) Get(key string, result googleProto.Message) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	elem, ok := cache.entries[key]
+	if !ok {
+		return false
+	}
+
+	data, err := os.ReadFile(cache.path(key))
+	if err == nil {
+		err = googleProto.Unmarshal(data, result)
+	}
+	if err != nil {
+		// Missing or corrupt file, forget about it.
+		cache.remove(elem)
+		return false
+	}
+
+	cache.lru.MoveToFront(elem)
+	now := time.Now()
+	os.Chtimes(cache.path(key), now, now)
+	return true
+}
+
+// Stores result under key, evicting the least recently used results if the
+// cache is full.
+func (cache *Cache) Put(key string, result googleProto.Message) error {
+	data, err := googleProto.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see a partial result.
+	tmp, err := os.CreateTemp(cache.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if err := os.Rename(tmp.Name(), cache.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if elem, ok := cache.entries[key]; ok {
+		cache.lru.MoveToFront(elem)
+	} else {
+		cache.entries[key] = cache.lru.PushFront(key)
+	}
+	cache.evict()
+	return nil
+}
+
+// Returns a version string for the running binary, for use with New. Any
+// rebuild of the sim changes it, so stale results are never served.
+func ExecutableVersion() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(exe)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Clears unknown fields in m and all its sub-messages, since they don't affect results.
+func discardUnknown(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				discardUnknown(list.Get(i).Message())
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					discardUnknown(mv.Message())
+					return true
+				})
+			}
+		default:
+			discardUnknown(v.Message())
+		}
+		return true
+	})
+	if m.GetUnknown() != nil {
+		m.SetUnknown(nil)
+	}
+}
+
+func (cache *Cache) path(key string) string {
+	return filepath.Join(cache.dir, key+fileExt)
+}
+
+// Must be called with the lock held.
+func (cache *Cache) evict() {
+	for cache.maxEntries > 0 && cache.lru.Len() > cache.maxEntries {
+		cache.remove(cache.lru.Back())
+	}
+}
+
+// Must be called with the lock held.
+func (cache *Cache) remove(elem *list.Element) {
+	key := cache.lru.Remove(elem).(string)
+	delete(cache.entries, key)
+	os.Remove(cache.path(key))
+}
@@ -0,0 +1,64 @@
+package resultcache
+
+import (
+	"testing"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+)
+
+func TestKey(t *testing.T) {
+	cache, err := New(t.TempDir(), 10, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := &proto.RaidSimRequest{SimOptions: &proto.SimOptions{Iterations: 100, RandomSeed: 5}}
+	key1, _ := cache.Key(request)
+	key2, _ := cache.Key(&proto.RaidSimRequest{SimOptions: &proto.SimOptions{RandomSeed: 5, Iterations: 100}})
+	if key1 != key2 {
+		t.Fatalf("Expected equal requests to have equal keys")
+	}
+
+	key3, _ := cache.Key(&proto.RaidSimRequest{SimOptions: &proto.SimOptions{Iterations: 100, RandomSeed: 6}})
+	if key1 == key3 {
+		t.Fatalf("Expected different requests to have different keys")
+	}
+
+	otherVersion, _ := New(t.TempDir(), 10, "v2")
+	key4, _ := otherVersion.Key(request)
+	if key1 == key4 {
+		t.Fatalf("Expected different versions to have different keys")
+	}
+}
+
+func TestEviction(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := New(dir, 2, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Put("a", &proto.RaidSimResult{AvgIterationDuration: 1})
+	cache.Put("b", &proto.RaidSimResult{AvgIterationDuration: 2})
+	if !cache.Get("a", &proto.RaidSimResult{}) {
+		t.Fatalf("Expected a to be cached")
+	}
+	cache.Put("c", &proto.RaidSimResult{AvgIterationDuration: 3})
+
+	if cache.Get("b", &proto.RaidSimResult{}) {
+		t.Fatalf("Expected least recently used entry b to be evicted")
+	}
+
+	// Entries should survive reopening the cache.
+	reopened, err := New(dir, 2, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &proto.RaidSimResult{}
+	if !reopened.Get("c", result) || result.AvgIterationDuration != 3 {
+		t.Fatalf("Expected c to be loaded from disk, got %v", result)
+	}
+	if !reopened.Get("a", &proto.RaidSimResult{}) {
+		t.Fatalf("Expected a to be loaded from disk")
+	}
+}
@@ -0,0 +1,27 @@
+package rogue
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func (rogue *Rogue) registerKickSpell() {
+	rogue.Kick = rogue.RegisterInterruptSpell(core.SpellConfig{
+		ActionID:     core.ActionID{SpellID: 1766},
+		ResourceType: stats.Energy,
+		BaseCost:     25,
+
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				Cost: 25,
+			},
+			IgnoreHaste: true,
+			CD: core.Cooldown{
+				Timer:    rogue.NewTimer(),
+				Duration: time.Second * 10,
+			},
+		},
+	}, nil)
+}
@@ -60,11 +60,11 @@ func (rogue *Rogue) registerKillingSpreeSpell() {
 				NumTicks:        4,
 				TickImmediately: false,
 				OnAction: func(s *core.Simulation) {
-					targetCount := sim.GetNumTargets()
+					targets := sim.Encounter.ActiveTargets
 					target := rogue.CurrentTarget
-					if targetCount > 1 {
-						newUnitIndex := int32(math.Ceil(float64(targetCount)*sim.RandomFloat("Killing Spree"))) - 1
-						target = sim.GetTargetUnit(newUnitIndex)
+					if len(targets) > 1 {
+						newTargetIndex := int(math.Ceil(float64(len(targets))*sim.RandomFloat("Killing Spree"))) - 1
+						target = &targets[newTargetIndex].Unit
 					}
 					mhWeaponSwing.Cast(sim, target)
 					ohWeaponSwing.Cast(sim, target)
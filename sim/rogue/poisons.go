@@ -16,6 +16,8 @@ func (rogue *Rogue) applyPoisons() {
 
 var DeadlyPoisonActionID = core.ActionID{SpellID: 57973}
 
+// Poison auras are indexed by target, so every target needs one, including
+// targets which only spawn later in the encounter.
 func (rogue *Rogue) registerPoisonAuras() {
 	numTargets := rogue.Env.GetNumTargets()
 	for i := int32(0); i < numTargets; i++ {
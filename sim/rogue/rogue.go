@@ -39,6 +39,7 @@ type Rogue struct {
 
 	priorityItems []roguePriorityItem
 	rotationItems []rogueRotationItem
+	isMultiTarget bool
 
 	sliceAndDiceDurations [6]time.Duration
 	exposeArmorDurations  [6]time.Duration
@@ -53,6 +54,7 @@ type Rogue struct {
 	DeadlyPoison     *core.Spell
 	FanOfKnives      *core.Spell
 	Hemorrhage       *core.Spell
+	Kick             *core.Spell
 	HungerForBlood   *core.Spell
 	InstantPoison    [3]*core.Spell
 	WoundPoison      [3]*core.Spell
@@ -146,6 +148,7 @@ func (rogue *Rogue) Initialize() {
 	rogue.registerFanOfKnives()
 	rogue.registerHemorrhageSpell()
 	rogue.registerInstantPoisonSpell()
+	rogue.registerKickSpell()
 	rogue.registerWoundPoisonSpell()
 	rogue.registerMutilateSpell()
 	rogue.registerRupture()
@@ -16,6 +16,9 @@ func (rogue *Rogue) OnEnergyGain(sim *core.Simulation) {
 	}
 	rogue.TryUseCooldowns(sim)
 	if rogue.GCD.IsReady(sim) {
+		if rogue.isMultiTarget != rogue.hasMultiTargets(sim) {
+			rogue.updatePriorityItems(sim)
+		}
 		rogue.rotation(sim)
 	}
 }
@@ -237,6 +240,25 @@ func (rogue *Rogue) planRotation(sim *core.Simulation) []rogueRotationItem {
 	return prioStack
 }
 
+func (rogue *Rogue) hasMultiTargets(sim *core.Simulation) bool {
+	return sim.GetNumActiveTargets() > 3
+}
+
+// Rebuilds the priority items when targets spawn or despawn, keeping the cast
+// counts of items which are still in the rotation.
+func (rogue *Rogue) updatePriorityItems(sim *core.Simulation) {
+	oldItems := rogue.priorityItems
+	rogue.setPriorityItems(sim)
+	for i := range rogue.priorityItems {
+		item := &rogue.priorityItems[i]
+		for _, oldItem := range oldItems {
+			if item.Aura != nil && item.Aura == oldItem.Aura {
+				item.CastCount = oldItem.CastCount
+			}
+		}
+	}
+}
+
 func (rogue *Rogue) setPriorityItems(sim *core.Simulation) {
 	rogue.Builder = rogue.SinisterStrike
 	rogue.BuilderPoints = 1
@@ -244,7 +266,8 @@ func (rogue *Rogue) setPriorityItems(sim *core.Simulation) {
 		rogue.Builder = rogue.Mutilate
 		rogue.BuilderPoints = 2
 	}
-	isMultiTarget := sim.GetNumTargets() > 3
+	isMultiTarget := rogue.hasMultiTargets(sim)
+	rogue.isMultiTarget = isMultiTarget
 	// Slice and Dice
 	rogue.priorityItems = make([]roguePriorityItem, 0)
 
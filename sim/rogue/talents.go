@@ -389,7 +389,7 @@ func (rogue *Rogue) registerBladeFlurryCD() {
 			rogue.MultiplyMeleeSpeed(sim, inverseHasteBonus)
 		},
 		OnSpellHitDealt: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, spellEffect *core.SpellEffect) {
-			if sim.GetNumTargets() < 2 {
+			if sim.GetNumActiveTargets() < 2 {
 				return
 			}
 			if spellEffect.Damage == 0 || !spell.ProcMask.Matches(core.ProcMaskMelee) {
@@ -50,6 +50,7 @@ func (shaman *Shaman) newChainLightningSpell(isLightningOverload bool) *core.Spe
 	spellConfig.ApplyEffects = func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 		bounceCoeff := 1.0
 		curTarget := target
+		numHits := core.MinInt32(numHits, sim.GetNumActiveTargets())
 		for hitIndex := int32(0); hitIndex < numHits; hitIndex++ {
 			baseDamage := dmgBonus + sim.Roll(973, 1111) + spellCoeff*spell.SpellPower()
 			baseDamage *= bounceCoeff
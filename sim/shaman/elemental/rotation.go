@@ -20,9 +20,32 @@ func (eleShaman *ElementalShaman) tryUseGCD(sim *core.Simulation) {
 		return
 	}
 
+	// Only shocks can be cast while moving, so use those until Lightning Bolt
+	// can be cast again.
+	if !eleShaman.LightningBolt.FinishesBeforeMovement(sim) {
+		if spell := eleShaman.instantWhileMoving(sim); spell != nil {
+			if !spell.Cast(sim, eleShaman.CurrentTarget) {
+				eleShaman.WaitForMana(sim, spell.CurCast.Cost)
+			}
+			return
+		} else if eleShaman.IsMoving(sim) {
+			eleShaman.WaitUntil(sim, core.MinDuration(eleShaman.MovementEndsAt(sim), eleShaman.EarthShock.ReadyAt()))
+			return
+		}
+	}
+
 	eleShaman.rotation.DoAction(eleShaman, sim)
 }
 
+func (eleShaman *ElementalShaman) instantWhileMoving(sim *core.Simulation) *core.Spell {
+	if !eleShaman.FlameShockDot.IsActive() && eleShaman.FlameShock.IsReady(sim) {
+		return eleShaman.FlameShock
+	} else if eleShaman.EarthShock.IsReady(sim) {
+		return eleShaman.EarthShock
+	}
+	return nil
+}
+
 // Picks which attacks / abilities the Shaman does.
 type Rotation interface {
 	// GetPresimOptions() *core.PresimOptions
@@ -50,7 +73,7 @@ func (rotation *AdaptiveRotation) DoAction(eleShaman *ElementalShaman, sim *core
 	shouldTS := false
 	cmp := eleShaman.CurrentManaPercent()
 	percent := 0.55
-	if len(eleShaman.Env.Encounter.Targets) > 1 {
+	if len(eleShaman.Env.Encounter.ActiveTargets) > 1 {
 		percent = 0.9 // single target we need less mana.
 	}
 	if cmp < percent {
@@ -76,7 +99,7 @@ func (rotation *AdaptiveRotation) DoAction(eleShaman *ElementalShaman, sim *core
 	if cmp > rotation.clmm && eleShaman.ChainLightning.IsReady(sim) {
 		lbTime := eleShaman.ApplyCastSpeed(eleShaman.LightningBolt.DefaultCast.CastTime)
 		// Only CL if LB is slower than CL or there is more than 1 target.
-		if lbTime > time.Second || len(eleShaman.Env.Encounter.Targets) > 1 {
+		if lbTime > time.Second || len(eleShaman.Env.Encounter.ActiveTargets) > 1 {
 			if !eleShaman.ChainLightning.Cast(sim, target) {
 				eleShaman.WaitForMana(sim, eleShaman.ChainLightning.CurCast.Cost)
 			}
@@ -100,22 +123,22 @@ func (rotation *AdaptiveRotation) DoAction(eleShaman *ElementalShaman, sim *core
 func (rotation *AdaptiveRotation) Reset(eleShaman *ElementalShaman, sim *core.Simulation) {
 	rotation.fnmm = 1.0
 	rotation.clmm = 1.0
-	if len(sim.Encounter.Targets) > 4 {
+	if len(sim.Encounter.ActiveTargets) > 4 {
 		// 5+ targets FN is better
 		rotation.fnmm = 0.33
 		// Allow CL as long as you have decent mana (leaving most mana for FN)
 		rotation.clmm = 0.5
-	} else if len(sim.Encounter.Targets) == 4 {
+	} else if len(sim.Encounter.ActiveTargets) == 4 {
 		// 4 targets, enable both similar prio, prob looking at real AoE now (short fight)
 		rotation.clmm = 0.33
 		rotation.fnmm = 0.33
-	} else if len(sim.Encounter.Targets) == 3 {
+	} else if len(sim.Encounter.ActiveTargets) == 3 {
 		// 3 targets, enable both, but prio CL (more efficient)
 		//  Still trying to be very mana efficient as 3 targets
 		//  is still often a "boss fight" and could be long.
 		rotation.clmm = 0.33
 		rotation.fnmm = 0.66
-	} else if len(sim.Encounter.Targets) == 2 {
+	} else if len(sim.Encounter.ActiveTargets) == 2 {
 		// enable CL with 2
 		rotation.clmm = 0.33
 	}
@@ -154,7 +177,7 @@ func (rotation *ManualRotation) DoAction(eleShaman *ElementalShaman, sim *core.S
 
 	// TODO: expose these percents to let user tweak
 	percent := 0.55
-	if len(eleShaman.Env.Encounter.Targets) > 1 {
+	if len(eleShaman.Env.Encounter.ActiveTargets) > 1 {
 		percent = 0.9
 	}
 	if cmp < percent {
@@ -187,7 +210,7 @@ func (rotation *ManualRotation) DoAction(eleShaman *ElementalShaman, sim *core.S
 	lbTime := eleShaman.ApplyCastSpeed(eleShaman.LightningBolt.DefaultCast.CastTime)
 
 	// Never cast CL if single target and LB cast time == CL cast time.
-	if lbTime <= time.Second && len(eleShaman.Env.Encounter.Targets) == 1 {
+	if lbTime <= time.Second && len(eleShaman.Env.Encounter.ActiveTargets) == 1 {
 		shouldCL = false // never CL if your LB is just as fast.
 	}
 	if shouldCL && rotation.options.UseClOnlyGap {
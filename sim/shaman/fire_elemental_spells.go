@@ -80,7 +80,7 @@ func (fireElemental *FireElemental) registerFireNova() {
 			// TODO is this the right affect should it be Capped?
 			// TODO these are approximation, from base SP
 			dmgFromSP := 1.0071 * spell.SpellPower()
-			for _, aoeTarget := range sim.Encounter.Targets {
+			for _, aoeTarget := range sim.Encounter.ActiveTargets {
 				baseDamage := sim.Roll(1, 150) + dmgFromSP
 				baseDamage *= sim.Encounter.AOECapMultiplier()
 				spell.CalcAndDealDamageMagicHitAndCrit(sim, &aoeTarget.Unit, baseDamage)
@@ -125,7 +125,7 @@ func (fireElemental *FireElemental) registerFireShieldAura() {
 			// TODO is this the right affect should it be Capped?
 			// TODO these are approximation, from base SP
 			dmgFromSP := 0.032 * spell.SpellPower()
-			for _, aoeTarget := range sim.Encounter.Targets {
+			for _, aoeTarget := range sim.Encounter.ActiveTargets {
 				baseDamage := sim.Roll(68, 70) + dmgFromSP
 				//baseDamage *= sim.Encounter.AOECapMultiplier()
 				spell.CalcAndDealDamageMagicCrit(sim, &aoeTarget.Unit, baseDamage)
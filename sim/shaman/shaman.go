@@ -99,6 +99,8 @@ type Shaman struct {
 
 	Thunderstorm *core.Spell
 
+	WindShear *core.Spell
+
 	EarthShock *core.Spell
 	FlameShock *core.Spell
 	FrostShock *core.Spell
@@ -214,6 +216,7 @@ func (shaman *Shaman) Initialize() {
 	shaman.registerStormstrikeSpell()
 	shaman.registerStrengthOfEarthTotemSpell()
 	shaman.registerThunderstormSpell()
+	shaman.registerWindShearSpell()
 	shaman.registerTotemOfWrathSpell()
 	shaman.registerTranquilAirTotemSpell()
 	shaman.registerTremorTotemSpell()
@@ -46,7 +46,7 @@ func (shaman *Shaman) registerThunderstormSpell() {
 
 			if shaman.thunderstormInRange {
 				dmgFromSP := 0.172 * spell.SpellPower()
-				for _, aoeTarget := range sim.Encounter.Targets {
+				for _, aoeTarget := range sim.Encounter.ActiveTargets {
 					baseDamage := sim.Roll(1450, 1656) + dmgFromSP
 					baseDamage *= sim.Encounter.AOECapMultiplier()
 					spell.CalcAndDealDamageMagicHitAndCrit(sim, &aoeTarget.Unit, baseDamage)
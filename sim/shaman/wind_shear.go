@@ -0,0 +1,28 @@
+package shaman
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func (shaman *Shaman) registerWindShearSpell() {
+	baseCost := 0.09 * shaman.BaseMana
+
+	shaman.WindShear = shaman.RegisterInterruptSpell(core.SpellConfig{
+		ActionID:     core.ActionID{SpellID: 57994},
+		ResourceType: stats.Mana,
+		BaseCost:     baseCost,
+
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				Cost: baseCost,
+			},
+			CD: core.Cooldown{
+				Timer:    shaman.NewTimer(),
+				Duration: time.Second * 6,
+			},
+		},
+	}, nil)
+}
@@ -525,6 +525,22 @@ func (warlock *Warlock) tryUseGCD(sim *core.Simulation) {
 		spell = filler
 	}
 
+	// ------------------------------------------
+	// Movement
+	// ------------------------------------------
+	// Hard casts can't be finished while moving, so only Corruption and Life Tap are left.
+	if !spell.FinishesBeforeMovement(sim) {
+		if !warlock.CorruptionDot.IsActive() {
+			spell = warlock.Corruption
+		} else if warlock.CurrentManaPercent() < 1 {
+			warlock.LifeTapOrDarkPact(sim)
+			return
+		} else if warlock.IsMoving(sim) {
+			warlock.WaitUntil(sim, warlock.MovementEndsAt(sim))
+			return
+		}
+	}
+
 	// This part tracks all the damage multiplier that roll over with corruption
 	PotentialCorruptionRolloverPower := warlock.corruptionTracker()
 	if sim.Log != nil {
@@ -40,7 +40,7 @@ func (warlock *Warlock) makeSeed(targetIdx int, numTargets int) {
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			dmgFromSP := 0.2129 * spell.SpellPower()
-			for _, aoeTarget := range sim.Encounter.Targets {
+			for _, aoeTarget := range sim.Encounter.ActiveTargets {
 				// Seeded target is not affected by explosion.
 				if &aoeTarget.Unit == target {
 					continue
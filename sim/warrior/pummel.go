@@ -0,0 +1,29 @@
+package warrior
+
+import (
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core"
+	"github.com/wowsims/wotlk/sim/core/stats"
+)
+
+func (warrior *Warrior) registerPummelSpell() {
+	warrior.Pummel = warrior.RegisterInterruptSpell(core.SpellConfig{
+		ActionID:     core.ActionID{SpellID: 6552},
+		ResourceType: stats.Rage,
+		BaseCost:     10,
+
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				Cost: 10,
+			},
+			IgnoreHaste: true,
+			CD: core.Cooldown{
+				Timer:    warrior.NewTimer(),
+				Duration: time.Second * 10,
+			},
+		},
+	}, func(sim *core.Simulation) bool {
+		return warrior.StanceMatches(BerserkerStance)
+	})
+}
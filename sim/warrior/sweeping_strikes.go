@@ -91,7 +91,7 @@ func (warrior *Warrior) registerSweepingStrikesCD() {
 		Spell: ssCD,
 		Type:  core.CooldownTypeDPS,
 		CanActivate: func(sim *core.Simulation, character *core.Character) bool {
-			return sim.GetNumTargets() > 1 && warrior.CurrentRage() >= ssCD.DefaultCast.Cost
+			return sim.GetNumActiveTargets() > 1 && warrior.CurrentRage() >= ssCD.DefaultCast.Cost
 		},
 		ShouldActivate: func(sim *core.Simulation, character *core.Character) bool {
 			return true
@@ -50,7 +50,7 @@ func (warrior *Warrior) registerThunderClapSpell() {
 			baseDamage := 300 + 0.12*spell.MeleeAttackPower()
 			baseDamage *= sim.Encounter.AOECapMultiplier()
 
-			for _, aoeTarget := range sim.Encounter.Targets {
+			for _, aoeTarget := range sim.Encounter.ActiveTargets {
 				result := spell.CalcDamage(sim, &aoeTarget.Unit, baseDamage, spell.OutcomeRangedHitAndCrit)
 				spell.DealDamage(sim, &result)
 				if result.Landed() {
@@ -55,6 +55,7 @@ type Warrior struct {
 	Execute              *core.Spell
 	MortalStrike         *core.Spell
 	Overpower            *core.Spell
+	Pummel               *core.Spell
 	Rend                 *core.Spell
 	Revenge              *core.Spell
 	ShieldBlock          *core.Spell
@@ -142,6 +143,7 @@ func (warrior *Warrior) Initialize() {
 	warrior.registerExecuteSpell()
 	warrior.registerMortalStrikeSpell(primaryTimer)
 	warrior.registerOverpowerSpell(overpowerRevengeTimer)
+	warrior.registerPummelSpell()
 	warrior.registerRevengeSpell(overpowerRevengeTimer)
 	warrior.registerShieldSlamSpell()
 	warrior.registerSlamSpell()
@@ -31,6 +31,13 @@ func main() {
 	js.Global().Set("raidSimAsync", js.FuncOf(raidSimAsync))
 	js.Global().Set("statWeights", js.FuncOf(statWeights))
 	js.Global().Set("statWeightsAsync", js.FuncOf(statWeightsAsync))
+	js.Global().Set("statCurve", js.FuncOf(statCurve))
+	js.Global().Set("statCurveAsync", js.FuncOf(statCurveAsync))
+	js.Global().Set("gearOptimizer", js.FuncOf(gearOptimizer))
+	js.Global().Set("gearOptimizerAsync", js.FuncOf(gearOptimizerAsync))
+	js.Global().Set("gemFill", js.FuncOf(gemFill))
+	js.Global().Set("talentComparison", js.FuncOf(talentComparison))
+	js.Global().Set("talentComparisonAsync", js.FuncOf(talentComparisonAsync))
 	js.Global().Call("wasmready")
 	<-c
 }
@@ -239,6 +246,122 @@ func statWeightsAsync(this js.Value, args []js.Value) interface{} {
 	return result
 }
 
+func statCurve(this js.Value, args []js.Value) interface{} {
+	scr := &proto.StatCurveRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), scr); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.StatCurve(scr)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
+func statCurveAsync(this js.Value, args []js.Value) interface{} {
+	scr := &proto.StatCurveRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), scr); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.StatCurveAsync(scr, reporter)
+
+	return processAsyncProgress(args[1], reporter)
+}
+
+func gearOptimizer(this js.Value, args []js.Value) interface{} {
+	gor := &proto.GearOptimizerRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), gor); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.GearOptimizer(gor)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
+func gemFill(this js.Value, args []js.Value) interface{} {
+	gfr := &proto.GemFillRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), gfr); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.GemFill(gfr)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
+func gearOptimizerAsync(this js.Value, args []js.Value) interface{} {
+	gor := &proto.GearOptimizerRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), gor); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.GearOptimizerAsync(gor, reporter)
+
+	return processAsyncProgress(args[1], reporter)
+}
+
+func talentComparison(this js.Value, args []js.Value) interface{} {
+	tcr := &proto.TalentComparisonRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), tcr); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := core.TalentComparison(tcr)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
+func talentComparisonAsync(this js.Value, args []js.Value) interface{} {
+	tcr := &proto.TalentComparisonRequest{}
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), tcr); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.TalentComparisonAsync(tcr, reporter)
+
+	return processAsyncProgress(args[1], reporter)
+}
+
 // Assumes args[0] is a Uint8Array
 func getArgsBinary(value js.Value) []byte {
 	data := make([]byte, value.Get("length").Int())
@@ -270,7 +393,8 @@ reader:
 			js.CopyBytesToJS(outArray, outbytes)
 			progFunc.Invoke(outArray)
 
-			if progMetric.FinalWeightResult != nil || progMetric.FinalRaidResult != nil {
+			if progMetric.FinalWeightResult != nil || progMetric.FinalRaidResult != nil || progMetric.FinalStatCurveResult != nil || progMetric.FinalGearOptimizerResult != nil ||
+				progMetric.FinalTalentComparisonResult != nil {
 				return outArray
 			}
 		}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/resultcache"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Cache of seeded sim results, or nil if caching is disabled.
+var resultCache *resultcache.Cache
+
+func setupResultCache(dir string, maxEntries int) {
+	if dir == "" {
+		return
+	}
+	version, err := resultcache.ExecutableVersion()
+	if err != nil {
+		log.Printf("Result cache disabled, could not determine sim version: %s", err)
+		return
+	}
+	resultCache, err = resultcache.New(dir, maxEntries, version)
+	if err != nil {
+		log.Printf("Result cache disabled: %s", err)
+		return
+	}
+	log.Printf("Caching seeded sim results in %s", dir)
+}
+
+// Returns the cache key for the request, or "" if its result shouldn't be cached.
+func resultCacheKey(msg googleProto.Message) string {
+	if resultCache == nil {
+		return ""
+	}
+	request, ok := msg.(interface{ GetSimOptions() *proto.SimOptions })
+	if !ok || !resultcache.Cacheable(request) || newResultFor(msg) == nil {
+		return ""
+	}
+	key, err := resultCache.Key(msg)
+	if err != nil {
+		log.Printf("[ERROR] Failed to compute cache key: %s", err.Error())
+		return ""
+	}
+	return key
+}
+
+// Clients can force a fresh sim by sending 'Cache-Control: no-cache'. The new
+// result still replaces the cached one.
+func skipCachedResult(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Cache-Control"), "no-cache")
+}
+
+// Returns an empty result message for the request type, or nil if it has no
+// cacheable result.
+func newResultFor(msg googleProto.Message) googleProto.Message {
+	switch msg.(type) {
+	case *proto.RaidSimRequest:
+		return &proto.RaidSimResult{}
+	case *proto.StatWeightsRequest:
+		return &proto.StatWeightsResult{}
+	case *proto.StatCurveRequest:
+		return &proto.StatCurveResult{}
+	case *proto.GearOptimizerRequest:
+		return &proto.GearOptimizerResult{}
+	case *proto.GemFillRequest:
+		return &proto.GemFillResult{}
+	case *proto.TalentComparisonRequest:
+		return &proto.TalentComparisonResult{}
+	}
+	return nil
+}
+
+func storeCachedResult(key string, result googleProto.Message) {
+	if key == "" {
+		return
+	}
+	if errResult, ok := result.(interface{ GetErrorResult() string }); ok && errResult.GetErrorResult() != "" {
+		return
+	}
+	if err := resultCache.Put(key, result); err != nil {
+		log.Printf("[ERROR] Failed to cache result: %s", err.Error())
+	}
+}
+
+// Wraps a cached result in a final progress report, as if the sim just finished.
+func finalProgressFor(result googleProto.Message) *proto.ProgressMetrics {
+	switch result := result.(type) {
+	case *proto.RaidSimResult:
+		return &proto.ProgressMetrics{FinalRaidResult: result}
+	case *proto.StatWeightsResult:
+		return &proto.ProgressMetrics{FinalWeightResult: result}
+	case *proto.StatCurveResult:
+		return &proto.ProgressMetrics{FinalStatCurveResult: result}
+	case *proto.GearOptimizerResult:
+		return &proto.ProgressMetrics{FinalGearOptimizerResult: result}
+	case *proto.TalentComparisonResult:
+		return &proto.ProgressMetrics{FinalTalentComparisonResult: result}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -11,7 +12,10 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
 	"runtime/pprof"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -24,6 +28,7 @@ import (
 	"github.com/wowsims/wotlk/sim"
 	"github.com/wowsims/wotlk/sim/core"
 	proto "github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/encounters"
 
 	googleProto "google.golang.org/protobuf/proto"
 )
@@ -35,8 +40,60 @@ func init() {
 var (
 	Version  string
 	outdated int
+
+	maxIterations iterationLimit
 )
 
+type iterationLimit int32
+
+func (limit *iterationLimit) String() string {
+	return strconv.Itoa(int(*limit))
+}
+
+func (limit *iterationLimit) Set(value string) error {
+	v, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return err
+	}
+	*limit = iterationLimit(v)
+	return nil
+}
+
+// Returns an error message if the request asks for more iterations than allowed.
+// Stat weights which stop on convergence are capped at the limit instead.
+func (limit iterationLimit) check(msg googleProto.Message) string {
+	var iterations int64
+	switch request := msg.(type) {
+	case *proto.RaidSimRequest:
+		iterations = int64(request.GetSimOptions().GetIterations())
+	case *proto.StatWeightsRequest:
+		iterations = int64(request.GetSimOptions().GetIterations())
+		if limit > 0 && request.MaxWeightError > 0 {
+			if request.MaxIterations > int32(limit) {
+				return fmt.Sprintf("Too many max iterations: %d, this server allows at most %d", request.MaxIterations, limit)
+			}
+			// Without a limit of its own, the sim would run until the default of
+			// 100000 iterations per stat if the weights don't converge.
+			if request.MaxIterations == 0 {
+				request.MaxIterations = int32(limit)
+			}
+		}
+	case *proto.StatCurveRequest:
+		// Every point of the curve is a separate sim.
+		iterations = int64(request.GetSimOptions().GetIterations()) * int64(core.StatCurvePoints(request))
+	case *proto.GearOptimizerRequest:
+		iterations = int64(request.GetSimOptions().GetIterations()) * int64(core.GearOptimizerSims(request))
+	case *proto.GemFillRequest:
+		iterations = int64(request.GetSimOptions().GetIterations()) * int64(core.GemFillSims(request))
+	case *proto.TalentComparisonRequest:
+		iterations = int64(request.GetSimOptions().GetIterations()) * int64(core.TalentComparisonSims(request))
+	}
+	if limit > 0 && iterations > int64(limit) {
+		return fmt.Sprintf("Too many iterations: %d, this server allows at most %d", iterations, limit)
+	}
+	return ""
+}
+
 func main() {
 	if Version == "" {
 		Version = "development"
@@ -47,9 +104,21 @@ func main() {
 	var host = flag.String("host", ":3333", "URL to host the interface on.")
 	var launch = flag.Bool("launch", true, "auto launch browser")
 	var skipVersionCheck = flag.Bool("nvc", false, "set true to skip version check")
+	var workers = flag.Int("workers", runtime.NumCPU(), "Max number of sims to run at once. Others wait in a queue.")
+	var maxClientQueue = flag.Int("maxclientqueue", 0, "Max number of queued sims per client, or 0 for no limit.")
+	flag.Var(&maxIterations, "maxiterations", "Max number of iterations allowed per request, summed over every sim it runs, or 0 for no limit.")
+	var cacheDir = flag.String("cachedir", defaultCacheDir(), "Directory for caching results of sims with a fixed random seed. Set to empty to disable.")
+	var cacheSize = flag.Int("cachesize", 1000, "Max number of results to keep in the result cache.")
+	var encountersDir = flag.String("encountersdir", "", "Directory of extra encounter definitions (.json) to load as presets.")
 
 	flag.Parse()
 
+	if *encountersDir != "" {
+		if err := encounters.LoadDefinitionsFromDir(*encountersDir); err != nil {
+			log.Fatalf("Failed to load encounter definitions: %s", err)
+		}
+	}
+
 	fmt.Printf("Version: %s\n", Version)
 	if !*skipVersionCheck && Version != "development" {
 		go func() {
@@ -76,27 +145,49 @@ func main() {
 		}()
 	}
 
-	setupAsyncServer()
-	runServer(*useFS, *host, *launch, *simName, *wasm, bufio.NewReader(os.Stdin))
+	setupResultCache(*cacheDir, *cacheSize)
+	queue := newSimQueue(*workers, *maxClientQueue)
+	setupAsyncServer(queue)
+	runServer(queue, *useFS, *host, *launch, *simName, *wasm, bufio.NewReader(os.Stdin))
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "wowsimwotlk", "results")
 }
 
-type simProgReportCreator func() (string, progReport)
+type simProgReportCreator func() (string, context.Context, progReport)
 type progReport func(progMetric *proto.ProgressMetrics)
 type asyncAPIHandler struct {
 	msg    func() googleProto.Message
-	handle func(googleProto.Message, chan *proto.ProgressMetrics)
+	handle func(context.Context, googleProto.Message, chan *proto.ProgressMetrics)
 }
 
 var asyncAPIHandlers = map[string]asyncAPIHandler{
-	"/raidSimAsync": {msg: func() googleProto.Message { return &proto.RaidSimRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
-		core.RunRaidSimAsync(msg.(*proto.RaidSimRequest), reporter)
+	"/raidSimAsync": {msg: func() googleProto.Message { return &proto.RaidSimRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.RunRaidSimAsyncWithContext(ctx, msg.(*proto.RaidSimRequest), reporter)
+	}},
+	"/statWeightsAsync": {msg: func() googleProto.Message { return &proto.StatWeightsRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.StatWeightsAsyncWithContext(ctx, msg.(*proto.StatWeightsRequest), reporter)
+	}},
+	"/statCurveAsync": {msg: func() googleProto.Message { return &proto.StatCurveRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.StatCurveAsyncWithContext(ctx, msg.(*proto.StatCurveRequest), reporter)
 	}},
-	"/statWeightsAsync": {msg: func() googleProto.Message { return &proto.StatWeightsRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
-		core.StatWeightsAsync(msg.(*proto.StatWeightsRequest), reporter)
+	"/gearOptimizerAsync": {msg: func() googleProto.Message { return &proto.GearOptimizerRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.GearOptimizerAsyncWithContext(ctx, msg.(*proto.GearOptimizerRequest), reporter)
+	}},
+	"/talentComparisonAsync": {msg: func() googleProto.Message { return &proto.TalentComparisonRequest{} }, handle: func(ctx context.Context, msg googleProto.Message, reporter chan *proto.ProgressMetrics) {
+		core.TalentComparisonAsyncWithContext(ctx, msg.(*proto.TalentComparisonRequest), reporter)
 	}},
 }
 
-func handleAsyncAPI(w http.ResponseWriter, r *http.Request, addNewSim simProgReportCreator) {
+// How long results of finished async sims are kept if nobody fetches them.
+const finishedProgressTTL = time.Minute * 10
+
+func handleAsyncAPI(w http.ResponseWriter, r *http.Request, queue *simQueue, addNewSim simProgReportCreator) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return
@@ -116,34 +207,104 @@ func handleAsyncAPI(w http.ResponseWriter, r *http.Request, addNewSim simProgRep
 		return
 	}
 
-	// reporter channel is handed into the core simulation.
-	//  as the simulation advances it will push changes to the channel
-	//  these changes will be consumed by the goroutine below so the asyncProgress endpoint can fetch the results.
-	reporter := make(chan *proto.ProgressMetrics, 100)
-	handler.handle(msg, reporter)
+	if errMsg := maxIterations.check(msg); errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
 
-	// Generate a new async simulation, and get back the ID and reporting function.
-	id, cacheProgressFunc := addNewSim()
+	// Generate a new async simulation, and get back the ID, its cancellation context and reporting function.
+	id, ctx, cacheProgressFunc := addNewSim()
 
-	// Now launch a background process that pulls progress reports off the reporter channel
-	// and pushes it into the async progress cache.
-	go func() {
-		for {
-			select {
-			case <-time.After(time.Hour):
-				return // if we get no progress after an hour, exit
-			case progMetric, ok := <-reporter:
-				if !ok {
-					return
-				}
-				cacheProgressFunc(progMetric)
-				if progMetric.FinalRaidResult != nil || progMetric.FinalWeightResult != nil {
+	cacheKey := resultCacheKey(msg)
+	if cacheKey != "" && !skipCachedResult(r) {
+		if result := newResultFor(msg); resultCache.Get(cacheKey, result) {
+			cacheProgressFunc(finalProgressFor(result))
+			writeAsyncAPIResult(w, id)
+			return
+		}
+	}
+
+	finalResult := func(errMsg string) *proto.ProgressMetrics {
+		switch msg.(type) {
+		case *proto.StatWeightsRequest:
+			return &proto.ProgressMetrics{FinalWeightResult: &proto.StatWeightsResult{ErrorResult: errMsg}}
+		case *proto.StatCurveRequest:
+			return &proto.ProgressMetrics{FinalStatCurveResult: &proto.StatCurveResult{ErrorResult: errMsg}}
+		case *proto.GearOptimizerRequest:
+			return &proto.ProgressMetrics{FinalGearOptimizerResult: &proto.GearOptimizerResult{ErrorResult: errMsg}}
+		case *proto.TalentComparisonRequest:
+			return &proto.ProgressMetrics{FinalTalentComparisonResult: &proto.TalentComparisonResult{ErrorResult: errMsg}}
+		}
+		return &proto.ProgressMetrics{FinalRaidResult: &proto.RaidSimResult{ErrorResult: errMsg}}
+	}
+
+	// The sim waits in the queue until a worker is free, then runs on that worker.
+	accepted := queue.enqueue(&simJob{
+		client: clientID(r),
+		ctx:    ctx,
+		run: func() {
+			// reporter channel is handed into the core simulation.
+			//  as the simulation advances it will push changes to the channel
+			//  these changes are consumed below and pushed into the async progress cache,
+			//  so the asyncProgress endpoint can fetch the results.
+			reporter := make(chan *proto.ProgressMetrics, 100)
+			handler.handle(ctx, msg, reporter)
+
+			for {
+				select {
+				case <-time.After(time.Hour):
+					// if we get no progress after an hour, stop the sim and exit
+					cacheProgressFunc(finalResult("Sim timed out"))
+
+					// Keep reading so the cancelled sim doesn't block on a full channel.
+					for progMetric := range reporter {
+						if isFinalProgress(progMetric) {
+							return
+						}
+					}
 					return
+				case progMetric, ok := <-reporter:
+					if !ok {
+						return
+					}
+					cacheProgressFunc(progMetric)
+					if progMetric.FinalRaidResult != nil {
+						storeCachedResult(cacheKey, progMetric.FinalRaidResult)
+						return
+					} else if progMetric.FinalWeightResult != nil {
+						storeCachedResult(cacheKey, progMetric.FinalWeightResult)
+						return
+					} else if progMetric.FinalStatCurveResult != nil {
+						storeCachedResult(cacheKey, progMetric.FinalStatCurveResult)
+						return
+					} else if progMetric.FinalGearOptimizerResult != nil {
+						storeCachedResult(cacheKey, progMetric.FinalGearOptimizerResult)
+						return
+					} else if progMetric.FinalTalentComparisonResult != nil {
+						storeCachedResult(cacheKey, progMetric.FinalTalentComparisonResult)
+						return
+					}
 				}
 			}
-		}
-	}()
+		},
+		onPosition: func(position int32) {
+			cacheProgressFunc(&proto.ProgressMetrics{QueuePosition: position})
+		},
+		onCancel: func() {
+			cacheProgressFunc(finalResult("Sim cancelled"))
+		},
+	})
+	if !accepted {
+		// Store a final result so the progress entry gets cleaned up.
+		cacheProgressFunc(finalResult("Too many queued sims"))
+		http.Error(w, "Too many queued sims, try again once your earlier sims finish", http.StatusTooManyRequests)
+		return
+	}
 
+	writeAsyncAPIResult(w, id)
+}
+
+func writeAsyncAPIResult(w http.ResponseWriter, id string) {
 	protoResult := &proto.AsyncAPIResult{
 		ProgressId: id,
 	}
@@ -159,37 +320,91 @@ func handleAsyncAPI(w http.ResponseWriter, r *http.Request, addNewSim simProgRep
 	w.Write(outbytes)
 }
 
-func setupAsyncServer() {
+func setupAsyncServer(queue *simQueue) {
 
 	// Hold all state for in-flight async processes here.
 	type asyncProgress struct {
 		latestProgress atomic.Value
+		cancel         context.CancelFunc
+
+		// Unix nanos of when the final result was stored, or 0 if still running.
+		finishedAt int64
+
+		// Streams of the asyncProgressStream endpoint, which get every update.
+		subMut      sync.Mutex
+		subscribers map[chan *proto.ProgressMetrics]struct{}
+		done        chan struct{} // closed once the final result is stored
+		doneOnce    sync.Once
 	}
 	progMut := &sync.RWMutex{} // mutex for progresses map
 	progresses := map[string]*asyncProgress{}
 
 	// addNewSim just stores progress data for a new running simulation into the state above.
-	addNewSim := func() (string, progReport) {
+	addNewSim := func() (string, context.Context, progReport) {
 		newID := uuid.NewV4().String()
-		simProgress := &asyncProgress{}
+		ctx, cancel := context.WithCancel(context.Background())
+		simProgress := &asyncProgress{
+			cancel:      cancel,
+			subscribers: map[chan *proto.ProgressMetrics]struct{}{},
+			done:        make(chan struct{}),
+		}
 		simProgress.latestProgress.Store(&proto.ProgressMetrics{})
 		progMut.Lock()
 		progresses[newID] = simProgress
 		progMut.Unlock()
 
-		return newID, func(newProg *proto.ProgressMetrics) {
+		return newID, ctx, func(newProg *proto.ProgressMetrics) {
 			// caches progress into the progress map indexed by the ID.
 			// This can later be fetched by the async progress endpoint.
+			simProgress.subMut.Lock()
 			simProgress.latestProgress.Store(newProg)
+			for sub := range simProgress.subscribers {
+				select {
+				case sub <- newProg:
+				default:
+					// Subscriber is too slow, skip this update. It always gets the final result from latestProgress.
+				}
+			}
+			simProgress.subMut.Unlock()
+
+			if isFinalProgress(newProg) {
+				// Release the context, this also stops the sim if it is still running.
+				cancel()
+				atomic.StoreInt64(&simProgress.finishedAt, time.Now().UnixNano())
+				simProgress.doneOnce.Do(func() { close(simProgress.done) })
+			}
 		}
 	}
 
+	// Periodically evict finished sims whose results were never fetched.
+	go func() {
+		for range time.Tick(time.Minute) {
+			cutoff := time.Now().Add(-finishedProgressTTL).UnixNano()
+			progMut.Lock()
+			for id, progress := range progresses {
+				if finishedAt := atomic.LoadInt64(&progress.finishedAt); finishedAt != 0 && finishedAt < cutoff {
+					delete(progresses, id)
+				}
+			}
+			progMut.Unlock()
+		}
+	}()
+
 	// All async handlers here will call the addNewSim, generating a new UUID and cached progress state.
 	http.HandleFunc("/statWeightsAsync", func(w http.ResponseWriter, r *http.Request) {
-		handleAsyncAPI(w, r, addNewSim)
+		handleAsyncAPI(w, r, queue, addNewSim)
 	})
 	http.HandleFunc("/raidSimAsync", func(w http.ResponseWriter, r *http.Request) {
-		handleAsyncAPI(w, r, addNewSim)
+		handleAsyncAPI(w, r, queue, addNewSim)
+	})
+	http.HandleFunc("/statCurveAsync", func(w http.ResponseWriter, r *http.Request) {
+		handleAsyncAPI(w, r, queue, addNewSim)
+	})
+	http.HandleFunc("/gearOptimizerAsync", func(w http.ResponseWriter, r *http.Request) {
+		handleAsyncAPI(w, r, queue, addNewSim)
+	})
+	http.HandleFunc("/talentComparisonAsync", func(w http.ResponseWriter, r *http.Request) {
+		handleAsyncAPI(w, r, queue, addNewSim)
 	})
 
 	// asyncProgress will fetch the current progress of a simulation by its UUID.
@@ -222,7 +437,7 @@ func setupAsyncServer() {
 		}
 
 		// If this was the last result, delete the cache for this simulation.
-		if latest.FinalRaidResult != nil || latest.FinalWeightResult != nil {
+		if isFinalProgress(latest) {
 			progMut.Lock()
 			delete(progresses, msg.ProgressId)
 			progMut.Unlock()
@@ -230,9 +445,137 @@ func setupAsyncServer() {
 		w.Header().Add("Content-Type", "application/x-protobuf")
 		w.Write(outbytes)
 	})
+
+	// asyncProgressStream pushes every progress update of a simulation as Server-Sent Events,
+	// ending with the final result. The UUID is either in the 'id' query parameter (for EventSource)
+	// or a protobuf AsyncAPIResult body, like asyncProgress.
+	http.HandleFunc("/asyncProgressStream", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				return
+			}
+			msg := &proto.AsyncAPIResult{}
+			if err := googleProto.Unmarshal(body, msg); err != nil {
+				log.Printf("Failed to parse request: %s", err.Error())
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			id = msg.ProgressId
+		}
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "json" && format != "proto" {
+			http.Error(w, "format must be 'json' or 'proto'", http.StatusBadRequest)
+			return
+		}
+
+		progMut.RLock()
+		progress, ok := progresses[id]
+		progMut.RUnlock()
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		stream, err := newProgressStream(w, format == "proto")
+		if err != nil {
+			log.Printf("[ERROR] Failed to start progress stream: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		updates := make(chan *proto.ProgressMetrics, 100)
+		progress.subMut.Lock()
+		progress.subscribers[updates] = struct{}{}
+		latest := progress.latestProgress.Load().(*proto.ProgressMetrics)
+		progress.subMut.Unlock()
+		defer func() {
+			progress.subMut.Lock()
+			delete(progress.subscribers, updates)
+			progress.subMut.Unlock()
+		}()
+
+		// Once the final result is sent, delete the cache for this simulation, same as asyncProgress.
+		finish := func(final *proto.ProgressMetrics) {
+			stream.send(final)
+			progMut.Lock()
+			delete(progresses, id)
+			progMut.Unlock()
+		}
+
+		if isFinalProgress(latest) {
+			finish(latest)
+			return
+		}
+		if stream.send(latest) != nil {
+			return
+		}
+
+		keepAlive := time.NewTicker(progressStreamKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case update := <-updates:
+				if isFinalProgress(update) {
+					finish(update)
+					return
+				}
+				if stream.send(update) != nil {
+					return
+				}
+			case <-progress.done:
+				// Send whatever is still buffered, then the final result.
+			drain:
+				for {
+					select {
+					case update := <-updates:
+						if !isFinalProgress(update) {
+							stream.send(update)
+						}
+					default:
+						break drain
+					}
+				}
+				finish(progress.latestProgress.Load().(*proto.ProgressMetrics))
+				return
+			case <-keepAlive.C:
+				if stream.keepAlive() != nil {
+					return
+				}
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+
+	// asyncCancel stops a running simulation by its UUID. Its final (error) result
+	// can still be fetched from asyncProgress.
+	http.HandleFunc("/asyncCancel", func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		msg := &proto.AsyncAPIResult{}
+		if err := googleProto.Unmarshal(body, msg); err != nil {
+			log.Printf("Failed to parse request: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		progMut.RLock()
+		progress, ok := progresses[msg.ProgressId]
+		progMut.RUnlock()
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		progress.cancel()
+		w.WriteHeader(http.StatusOK)
+	})
 }
 
-func runServer(useFS bool, host string, launchBrowser bool, simName string, wasm bool, inputReader *bufio.Reader) {
+func runServer(queue *simQueue, useFS bool, host string, launchBrowser bool, simName string, wasm bool, inputReader *bufio.Reader) {
 	var fs http.Handler
 	if useFS {
 		log.Printf("Using local file system for development.")
@@ -246,11 +589,11 @@ func runServer(useFS bool, host string, launchBrowser bool, simName string, wasm
 		msg := fmt.Sprintf(`{"version": "%s", "outdated": %d}`, Version, outdated)
 		resp.Write([]byte(msg))
 	})
-	http.HandleFunc("/statWeights", handleAPI)
-	http.HandleFunc("/computeStats", handleAPI)
-	http.HandleFunc("/individualSim", handleAPI)
-	http.HandleFunc("/raidSim", handleAPI)
-	http.HandleFunc("/gearList", handleAPI)
+	for _, endpoint := range []string{"/statWeights", "/computeStats", "/individualSim", "/raidSim", "/statCurve", "/gearOptimizer", "/gemFill", "/talentComparison", "/gearList"} {
+		http.HandleFunc(endpoint, func(w http.ResponseWriter, r *http.Request) {
+			handleAPI(w, r, queue)
+		})
+	}
 	http.HandleFunc("/", func(resp http.ResponseWriter, req *http.Request) {
 		if req.URL.Path == "/" {
 			http.Redirect(resp, req, "/wotlk/", http.StatusPermanentRedirect)
@@ -347,16 +690,31 @@ func runServer(useFS bool, host string, launchBrowser bool, simName string, wasm
 type apiHandler struct {
 	msg    func() googleProto.Message
 	handle func(googleProto.Message) googleProto.Message
+
+	// Whether this runs sims, and so has to wait in the sim queue.
+	runsSims bool
 }
 
 // Handlers to decode and handle each proto function
 var handlers = map[string]apiHandler{
 	"/raidSim": {msg: func() googleProto.Message { return &proto.RaidSimRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
 		return core.RunRaidSim(msg.(*proto.RaidSimRequest))
-	}},
+	}, runsSims: true},
 	"/statWeights": {msg: func() googleProto.Message { return &proto.StatWeightsRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
 		return core.StatWeights(msg.(*proto.StatWeightsRequest))
-	}},
+	}, runsSims: true},
+	"/statCurve": {msg: func() googleProto.Message { return &proto.StatCurveRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.StatCurve(msg.(*proto.StatCurveRequest))
+	}, runsSims: true},
+	"/gearOptimizer": {msg: func() googleProto.Message { return &proto.GearOptimizerRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.GearOptimizer(msg.(*proto.GearOptimizerRequest))
+	}, runsSims: true},
+	"/gemFill": {msg: func() googleProto.Message { return &proto.GemFillRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.GemFill(msg.(*proto.GemFillRequest))
+	}, runsSims: true},
+	"/talentComparison": {msg: func() googleProto.Message { return &proto.TalentComparisonRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.TalentComparison(msg.(*proto.TalentComparisonRequest))
+	}, runsSims: true},
 	"/computeStats": {msg: func() googleProto.Message { return &proto.ComputeStatsRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
 		return core.ComputeStats(msg.(*proto.ComputeStatsRequest))
 	}},
@@ -366,7 +724,7 @@ var handlers = map[string]apiHandler{
 }
 
 // handleAPI is generic handler for any api function using protos.
-func handleAPI(w http.ResponseWriter, r *http.Request) {
+func handleAPI(w http.ResponseWriter, r *http.Request, queue *simQueue) {
 	endpoint := r.URL.Path
 
 	body, err := ioutil.ReadAll(r.Body)
@@ -387,7 +745,29 @@ func handleAPI(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	result := handler.handle(msg)
+	if errMsg := maxIterations.check(msg); errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	var result googleProto.Message
+	cacheKey := resultCacheKey(msg)
+	if cacheKey != "" && !skipCachedResult(r) {
+		if cached := newResultFor(msg); resultCache.Get(cacheKey, cached) {
+			result = cached
+		}
+	}
+	if result == nil {
+		run := func() {
+			result = handler.handle(msg)
+			storeCachedResult(cacheKey, result)
+		}
+		if !handler.runsSims {
+			run()
+		} else if !runQueued(w, r, queue, run) {
+			return
+		}
+	}
 
 	outbytes, err := googleProto.Marshal(result)
 	if err != nil {
@@ -399,3 +779,32 @@ func handleAPI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/x-protobuf")
 	w.Write(outbytes)
 }
+
+// Runs a sim for a sync request in the sim queue, so sync and async sims share
+// the same workers and per-client limits. Blocks until the sim is finished.
+// Returns false if the sim didn't finish, in which case there is nothing to send.
+func runQueued(w http.ResponseWriter, r *http.Request, queue *simQueue, run func()) bool {
+	done := make(chan struct{})
+	accepted := queue.enqueue(&simJob{
+		client: clientID(r),
+		ctx:    r.Context(),
+		run: func() {
+			run()
+			close(done)
+		},
+		onPosition: func(int32) {},
+		onCancel:   func() {},
+	})
+	if !accepted {
+		http.Error(w, "Too many queued sims, try again once your earlier sims finish", http.StatusTooManyRequests)
+		return false
+	}
+
+	select {
+	case <-done:
+		return true
+	case <-r.Context().Done():
+		// The client went away. If the sim was still queued it has been removed.
+		return false
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/wowsims/wotlk/sim/core"
 	"github.com/wowsims/wotlk/sim/core/proto"
+	"github.com/wowsims/wotlk/sim/core/stats"
+	"github.com/wowsims/wotlk/sim/resultcache"
 	googleProto "google.golang.org/protobuf/proto"
 )
 
@@ -62,7 +65,7 @@ var p1Equip = &proto.EquipmentSpec{
 
 func init() {
 	go func() {
-		runServer(true, ":3339", false, "", false, bufio.NewReader(bytes.NewBuffer([]byte{})))
+		runServer(newSimQueue(2, 0), true, ":3339", false, "", false, bufio.NewReader(bytes.NewBuffer([]byte{})))
 	}()
 
 	time.Sleep(time.Second) // hack so we have time for server to startup. Probably could repeatedly curl the endpoint until it responds.
@@ -119,3 +122,139 @@ func TestIndividualSim(t *testing.T) {
 
 	log.Printf("RESULT: %#v", rsr)
 }
+
+func TestEndpointsRegistered(t *testing.T) {
+	for endpoint := range handlers {
+		_, pattern := http.DefaultServeMux.Handler(&http.Request{Method: http.MethodPost, URL: &url.URL{Path: endpoint}})
+		if pattern != endpoint {
+			t.Errorf("Endpoint %s is not registered, requests go to %s", endpoint, pattern)
+		}
+	}
+}
+
+func TestIterationLimit(t *testing.T) {
+	limit := iterationLimit(1000)
+	statCurve := func(iterations int32, max float64) *proto.StatCurveRequest {
+		return &proto.StatCurveRequest{
+			SimOptions: &proto.SimOptions{Iterations: iterations},
+			Ranges:     []*proto.StatRange{{Stat: proto.Stat_StatSpellPower, Min: 0, Max: max, Step: 100}},
+		}
+	}
+
+	talentComparison := &proto.TalentComparisonRequest{
+		Player: &proto.Player{
+			Class: proto.Class_ClassShaman,
+			Spec:  basicSpec,
+		},
+		SimOptions:     &proto.SimOptions{Iterations: 100},
+		TalentsStrings: []string{"0", "1", "2"},
+	}
+	talentPointValues := googleProto.Clone(talentComparison).(*proto.TalentComparisonRequest)
+	talentPointValues.TalentPointValues = true
+
+	testCases := []struct {
+		name    string
+		msg     googleProto.Message
+		allowed bool
+	}{
+		{"raid sim", &proto.RaidSimRequest{SimOptions: &proto.SimOptions{Iterations: 1000}}, true},
+		{"raid sim over limit", &proto.RaidSimRequest{SimOptions: &proto.SimOptions{Iterations: 1001}}, false},
+		{"stat curve", statCurve(100, 900), true},
+		{"stat curve over limit", statCurve(100, 1000), false},
+		{"gear optimizer", &proto.GearOptimizerRequest{SimOptions: &proto.SimOptions{Iterations: 100}, StatWeights: []float64{1}}, true},
+		{"gear optimizer over limit", &proto.GearOptimizerRequest{SimOptions: &proto.SimOptions{Iterations: 100}, StatWeights: []float64{1}, NumSims: 11}, false},
+		{"gem fill", &proto.GemFillRequest{SimOptions: &proto.SimOptions{Iterations: 100000}, StatWeights: []float64{1}}, true},
+		{"gem fill with presim", &proto.GemFillRequest{SimOptions: &proto.SimOptions{Iterations: 1000}, StatsToWeigh: []proto.Stat{proto.Stat_StatSpellPower}}, false},
+		{"talent comparison", talentComparison, true},
+		{"talent comparison with point values", talentPointValues, false},
+		{"gear optimizer with presim", &proto.GearOptimizerRequest{SimOptions: &proto.SimOptions{Iterations: 100}, StatsToWeigh: []proto.Stat{proto.Stat_StatSpellPower}}, false},
+	}
+	for _, tc := range testCases {
+		if allowed := limit.check(tc.msg) == ""; allowed != tc.allowed {
+			t.Errorf("%s: expected allowed = %v", tc.name, tc.allowed)
+		}
+	}
+}
+
+func TestStatCurveEndpoint(t *testing.T) {
+	req := &proto.StatCurveRequest{
+		Player: &proto.Player{
+			Race:      proto.Race_RaceTroll,
+			Class:     proto.Class_ClassShaman,
+			Equipment: &proto.EquipmentSpec{},
+			Spec:      basicSpec,
+		},
+		RaidBuffs:  &proto.RaidBuffs{},
+		PartyBuffs: &proto.PartyBuffs{},
+		Debuffs:    &proto.Debuffs{},
+		Encounter:  &proto.Encounter{Duration: 30, Targets: []*proto.Target{{}}},
+		SimOptions: &proto.SimOptions{Iterations: 5, RandomSeed: 1},
+		Ranges:     []*proto.StatRange{{Stat: proto.Stat_StatSpellPower, Min: 0, Max: 100, Step: 100}},
+	}
+
+	result := &proto.StatCurveResult{}
+	postProto(t, "/statCurve", req, result)
+	if result.ErrorResult != "" || len(result.Points) != 2 {
+		t.Fatalf("Expected 2 points, got %d (error: %s)", len(result.Points), result.ErrorResult)
+	}
+}
+
+func TestCachedGemFill(t *testing.T) {
+	cache, err := resultcache.New(t.TempDir(), 10, "test")
+	if err != nil {
+		t.Fatalf("Failed to create result cache: %s", err)
+	}
+	resultCache = cache
+	defer func() { resultCache = nil }()
+
+	req := &proto.GemFillRequest{
+		Player: &proto.Player{
+			Race:  proto.Race_RaceTroll,
+			Class: proto.Class_ClassShaman,
+			Equipment: &proto.EquipmentSpec{
+				Items: []*proto.ItemSpec{{Id: 40516}},
+			},
+			Spec: basicSpec,
+		},
+		SimOptions:  &proto.SimOptions{Iterations: 1, RandomSeed: 1},
+		StatWeights: stats.Stats{stats.SpellPower: 1}.ToFloatArray(),
+	}
+
+	// The second request is served from the cache.
+	var results [2]*proto.GemFillResult
+	for i := range results {
+		results[i] = &proto.GemFillResult{}
+		postProto(t, "/gemFill", req, results[i])
+		if results[i].ErrorResult != "" {
+			t.Fatalf("Gem fill failed with error: %s", results[i].ErrorResult)
+		}
+	}
+	if !googleProto.Equal(results[0], results[1]) {
+		t.Fatalf("Expected the cached result to match the first one")
+	}
+}
+
+// Posts the request to the test server and parses its response into result.
+func postProto(t *testing.T, endpoint string, req googleProto.Message, result googleProto.Message) {
+	msgBytes, err := googleProto.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to encode request: %s", err.Error())
+	}
+
+	r, err := http.Post("http://localhost:3339"+endpoint, "application/x-protobuf", bytes.NewReader(msgBytes))
+	if err != nil {
+		t.Fatalf("Failed to POST request: %s", err.Error())
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		t.Fatalf("Request to %s failed with status %d", endpoint, r.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("Failed to read result body: %s", err.Error())
+	}
+	if err := googleProto.Unmarshal(body, result); err != nil {
+		t.Fatalf("Failed to parse result: %s", err.Error())
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// A sim waiting for a free worker.
+type simJob struct {
+	client string
+	ctx    context.Context
+
+	// Runs the sim, blocking until it is finished.
+	run func()
+
+	// Called whenever the job's 1-based position in the queue changes, and with
+	// 0 once it starts running.
+	onPosition func(int32)
+
+	// Called if ctx is cancelled while the job is still queued.
+	onCancel func()
+}
+
+// simQueue runs sims on a fixed number of workers. Each client has its own FIFO
+// queue, and workers take jobs from clients in round-robin order, so a single
+// client queueing many sims can't starve everyone else.
+type simQueue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// Clients with queued jobs, in the order they'll next be served.
+	clients []string
+	queued  map[string][]*simJob
+
+	// Max number of queued (not yet running) jobs per client, or 0 for no limit.
+	maxPerClient int
+}
+
+func newSimQueue(numWorkers int, maxPerClient int) *simQueue {
+	queue := &simQueue{
+		queued:       make(map[string][]*simJob),
+		maxPerClient: maxPerClient,
+	}
+	queue.cond = sync.NewCond(&queue.mu)
+
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	for i := 0; i < numWorkers; i++ {
+		go queue.work()
+	}
+	return queue
+}
+
+// Adds a job to the queue. Returns false if the client already has too many
+// jobs waiting.
+func (queue *simQueue) enqueue(job *simJob) bool {
+	queue.mu.Lock()
+	jobs := queue.queued[job.client]
+	if queue.maxPerClient > 0 && len(jobs) >= queue.maxPerClient {
+		queue.mu.Unlock()
+		return false
+	}
+	if len(jobs) == 0 {
+		queue.clients = append(queue.clients, job.client)
+	}
+	queue.queued[job.client] = append(jobs, job)
+	queue.updatePositions()
+	queue.mu.Unlock()
+	queue.cond.Signal()
+
+	go func() {
+		<-job.ctx.Done()
+		if queue.remove(job) {
+			job.onCancel()
+		}
+	}()
+	return true
+}
+
+// Removes a job which hasn't started yet. Returns false if it was not queued.
+func (queue *simQueue) remove(job *simJob) bool {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	jobs := queue.queued[job.client]
+	for i, queuedJob := range jobs {
+		if queuedJob != job {
+			continue
+		}
+		jobs = append(jobs[:i:i], jobs[i+1:]...)
+		queue.queued[job.client] = jobs
+		if len(jobs) == 0 {
+			queue.removeClient(job.client)
+		}
+		queue.updatePositions()
+		return true
+	}
+	return false
+}
+
+func (queue *simQueue) removeClient(client string) {
+	delete(queue.queued, client)
+	for i, c := range queue.clients {
+		if c == client {
+			queue.clients = append(queue.clients[:i:i], queue.clients[i+1:]...)
+			return
+		}
+	}
+}
+
+// Blocks until a job is available, then removes and returns it.
+func (queue *simQueue) next() *simJob {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	for len(queue.clients) == 0 {
+		queue.cond.Wait()
+	}
+
+	client := queue.clients[0]
+	queue.clients = queue.clients[1:]
+	jobs := queue.queued[client]
+	job := jobs[0]
+	if len(jobs) == 1 {
+		delete(queue.queued, client)
+	} else {
+		queue.queued[client] = jobs[1:]
+		queue.clients = append(queue.clients, client)
+	}
+	queue.updatePositions()
+	return job
+}
+
+func (queue *simQueue) work() {
+	for {
+		job := queue.next()
+		job.onPosition(0)
+		job.run()
+	}
+}
+
+// Reports the position of every queued job, in the order workers will pick them
+// up. Must be called with the lock held.
+func (queue *simQueue) updatePositions() {
+	position := int32(1)
+	for round := 0; ; round++ {
+		found := false
+		for _, client := range queue.clients {
+			jobs := queue.queued[client]
+			if round < len(jobs) {
+				jobs[round].onPosition(position)
+				position++
+				found = true
+			}
+		}
+		if !found {
+			return
+		}
+	}
+}
+
+// Identifies the client making a request, for fairness between clients.
+func clientID(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
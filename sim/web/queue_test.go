@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+func TestSimQueueFairness(t *testing.T) {
+	queue := newSimQueue(1, 0)
+
+	// Block the only worker so everything else stays queued.
+	block := make(chan struct{})
+	started := make(chan struct{})
+	queue.enqueue(&simJob{
+		client:     "a",
+		ctx:        context.Background(),
+		run:        func() { close(started); <-block },
+		onPosition: func(int32) {},
+	})
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	positions := map[string]int32{}
+	var waitGroup sync.WaitGroup
+	for _, name := range []string{"a1", "a2", "a3", "b1", "b2"} {
+		name := name
+		waitGroup.Add(1)
+		queue.enqueue(&simJob{
+			client: name[:1],
+			ctx:    context.Background(),
+			run: func() {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				waitGroup.Done()
+			},
+			onPosition: func(position int32) {
+				mu.Lock()
+				positions[name] = position
+				mu.Unlock()
+			},
+		})
+	}
+
+	mu.Lock()
+	expectedPositions := map[string]int32{"a1": 1, "b1": 2, "a2": 3, "b2": 4, "a3": 5}
+	if !reflect.DeepEqual(positions, expectedPositions) {
+		t.Fatalf("Expected queue positions %v, got %v", expectedPositions, positions)
+	}
+	mu.Unlock()
+
+	close(block)
+	waitGroup.Wait()
+
+	expectedOrder := []string{"a1", "b1", "a2", "b2", "a3"}
+	if !reflect.DeepEqual(order, expectedOrder) {
+		t.Fatalf("Expected run order %v, got %v", expectedOrder, order)
+	}
+}
+
+func TestSimQueueCancel(t *testing.T) {
+	queue := newSimQueue(1, 1)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	queue.enqueue(&simJob{
+		client:     "a",
+		ctx:        context.Background(),
+		run:        func() { close(started); <-block },
+		onPosition: func(int32) {},
+	})
+	<-started
+	defer close(block)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan struct{})
+	job := &simJob{
+		client:     "a",
+		ctx:        ctx,
+		run:        func() { t.Errorf("Cancelled job should not run") },
+		onPosition: func(int32) {},
+		onCancel:   func() { close(cancelled) },
+	}
+	if !queue.enqueue(job) {
+		t.Fatalf("Expected job to be queued")
+	}
+	if queue.enqueue(&simJob{client: "a", ctx: context.Background(), onPosition: func(int32) {}}) {
+		t.Fatalf("Expected job over the per-client limit to be rejected")
+	}
+
+	cancel()
+	<-cancelled
+	if queue.remove(job) {
+		t.Fatalf("Expected cancelled job to already be removed")
+	}
+}
+
+func TestSyncSimsQueued(t *testing.T) {
+	queue := newSimQueue(1, 1)
+
+	// Block the only worker with a sim from the same client as the requests below.
+	client := clientID(httptest.NewRequest(http.MethodPost, "/raidSim", nil))
+	block := make(chan struct{})
+	started := make(chan struct{})
+	queue.enqueue(&simJob{
+		client:     client,
+		ctx:        context.Background(),
+		run:        func() { close(started); <-block },
+		onPosition: func(int32) {},
+	})
+	<-started
+
+	post := func(endpoint string, msg googleProto.Message) *httptest.ResponseRecorder {
+		body, err := googleProto.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Failed to encode request: %s", err)
+		}
+		w := httptest.NewRecorder()
+		handleAPI(w, httptest.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body)), queue)
+		return w
+	}
+
+	// Requests which don't run sims skip the queue.
+	if w := post("/gearList", &proto.GearListRequest{}); w.Code != http.StatusOK {
+		t.Fatalf("Expected gear list to be served while the queue is full, got status %d", w.Code)
+	}
+
+	queued := make(chan *httptest.ResponseRecorder)
+	go func() {
+		queued <- post("/raidSim", &proto.RaidSimRequest{})
+	}()
+	select {
+	case <-queued:
+		t.Fatalf("Expected the sync sim to wait for a free worker")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// The client already has a sim waiting, so another is rejected.
+	if w := post("/raidSim", &proto.RaidSimRequest{}); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected a sync sim over the per-client limit to be rejected, got status %d", w.Code)
+	}
+
+	close(block)
+	if w := <-queued; w.Code != http.StatusOK {
+		t.Fatalf("Expected the queued sync sim to run once the worker is free, got status %d", w.Code)
+	}
+}
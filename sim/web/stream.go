@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wowsims/wotlk/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// How often to send a comment on idle progress streams, so proxies don't close them.
+const progressStreamKeepAlive = time.Second * 15
+
+// Writes ProgressMetrics as Server-Sent Events. Intermediate updates are sent as
+// 'progress' events and the final result as a 'final' event. The data of each
+// event is either protojson, or a base64 encoded protobuf.
+type progressStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	binary  bool
+}
+
+func newProgressStream(w http.ResponseWriter, binary bool) (*progressStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("streaming is not supported by this connection")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &progressStream{
+		w:       w,
+		flusher: flusher,
+		binary:  binary,
+	}, nil
+}
+
+func (stream *progressStream) send(progress *proto.ProgressMetrics) error {
+	var data string
+	if stream.binary {
+		outbytes, err := googleProto.Marshal(progress)
+		if err != nil {
+			return err
+		}
+		data = base64.StdEncoding.EncodeToString(outbytes)
+	} else {
+		outbytes, err := protojson.Marshal(progress)
+		if err != nil {
+			return err
+		}
+		data = string(outbytes)
+	}
+
+	event := "progress"
+	if isFinalProgress(progress) {
+		event = "final"
+	}
+	if _, err := fmt.Fprintf(stream.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	stream.flusher.Flush()
+	return nil
+}
+
+func (stream *progressStream) keepAlive() error {
+	if _, err := fmt.Fprint(stream.w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	stream.flusher.Flush()
+	return nil
+}
+
+func isFinalProgress(progress *proto.ProgressMetrics) bool {
+	return progress.FinalRaidResult != nil || progress.FinalWeightResult != nil || progress.FinalStatCurveResult != nil ||
+		progress.FinalGearOptimizerResult != nil || progress.FinalTalentComparisonResult != nil
+}
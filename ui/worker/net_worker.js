@@ -22,29 +22,13 @@ addEventListener('message', async (e) => {
 	var content = await response.arrayBuffer();
 	var outputData;
 	if (msg == "raidSimAsync" || msg == "statWeightsAsync") {
-		while (true) {
-			let progressResponse = await fetch("/asyncProgress", {
-				method: 'POST',
-				headers: {
-					'Content-Type': 'application/x-protobuf'
-				},
-				body: content,
-			});
-
-			// If no new data available, stop querying.
-			if (progressResponse.status == 204) {
-				break
-			}
-
-			outputData = await progressResponse.arrayBuffer();
-			var uint8View = new Uint8Array(outputData);
+		outputData = await streamProgress(content, (progressData) => {
 			postMessage({
 				msg: msg,
-				outputData: uint8View,
+				outputData: progressData,
 				id: id + "progress",
 			});
-			await new Promise(resolve => setTimeout(resolve, 500));
-		}
+		});
 	} else {
 		outputData = content;
 	}
@@ -58,6 +42,51 @@ addEventListener('message', async (e) => {
 
 }, false);
 
+// Reads progress updates from the server as they happen, calling onProgress with
+// each one. Returns the final result.
+async function streamProgress(asyncResult, onProgress) {
+	let response = await fetch("/asyncProgressStream?format=proto", {
+		method: 'POST',
+		headers: {
+			'Content-Type': 'application/x-protobuf'
+		},
+		body: asyncResult,
+	});
+	if (response.status != 200) {
+		return new ArrayBuffer(0);
+	}
+
+	const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+	let buffer = "";
+	let lastData = new Uint8Array(0);
+	while (true) {
+		const { value, done } = await reader.read();
+		if (done) {
+			return lastData.buffer;
+		}
+		buffer += value;
+
+		// Events are separated by a blank line.
+		let end;
+		while ((end = buffer.indexOf("\n\n")) != -1) {
+			const event = buffer.slice(0, end);
+			buffer = buffer.slice(end + 2);
+
+			const dataLine = event.split("\n").find(line => line.startsWith("data: "));
+			if (!dataLine) {
+				continue; // keep-alive
+			}
+			const decoded = atob(dataLine.slice("data: ".length));
+			lastData = Uint8Array.from(decoded, c => c.charCodeAt(0));
+			onProgress(lastData);
+			if (event.startsWith("event: final")) {
+				reader.cancel();
+				return lastData.buffer;
+			}
+		}
+	}
+}
+
 // Let UI know worker is ready.
 postMessage({
 	msg: "ready"